@@ -0,0 +1,78 @@
+package jq
+
+// eachNumber calls fn for every numeric leaf selected by path: the values that path selects,
+// and the leaves of any arrays, slices, maps and structs among them.
+// Numbers of any Go numeric type and json.Number are included, all other values are skipped.
+func eachNumber(root interface{}, path string, fn func(float64)) {
+	var leaves func(v interface{})
+	leaves = func(v interface{}) {
+		if f, ok := number(v); ok {
+			fn(f)
+			return
+		}
+		if o := order(v); o == orderArray || o == orderObject {
+			for _, e := range elements(v) {
+				leaves(e)
+			}
+		}
+	}
+	index, err := parsePath(path)
+	if err != nil {
+		return
+	}
+	stream(root, index, nil, func(_ []interface{}, v interface{}) bool {
+		leaves(v)
+		return true
+	})
+}
+
+// SumFloat returns the sum of the numeric leaves selected by path, or 0 if there are none.
+func SumFloat(root interface{}, path string) float64 {
+	var s float64
+	eachNumber(root, path, func(f float64) { s += f })
+	return s
+}
+
+// MinFloat returns the smallest of the numeric leaves selected by path.
+// It returns false if there are none.
+func MinFloat(root interface{}, path string) (float64, bool) {
+	var (
+		m  float64
+		ok bool
+	)
+	eachNumber(root, path, func(f float64) {
+		if !ok || f < m {
+			m, ok = f, true
+		}
+	})
+	return m, ok
+}
+
+// MaxFloat returns the largest of the numeric leaves selected by path.
+// It returns false if there are none.
+func MaxFloat(root interface{}, path string) (float64, bool) {
+	var (
+		m  float64
+		ok bool
+	)
+	eachNumber(root, path, func(f float64) {
+		if !ok || f > m {
+			m, ok = f, true
+		}
+	})
+	return m, ok
+}
+
+// Avg returns the mean of the numeric leaves selected by path.
+// It returns false if there are none.
+func Avg(root interface{}, path string) (float64, bool) {
+	var (
+		s float64
+		n int
+	)
+	eachNumber(root, path, func(f float64) { s, n = s+f, n+1 })
+	if n == 0 {
+		return 0, false
+	}
+	return s / float64(n), true
+}
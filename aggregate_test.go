@@ -0,0 +1,46 @@
+package jq
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAggregates(t *testing.T) {
+	root := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"price": 2.5},
+			map[string]interface{}{"price": json.Number("4")},
+			map[string]interface{}{"price": uint8(1)},
+			map[string]interface{}{"price": "n/a"},
+			map[string]interface{}{},
+		},
+		"matrix": []interface{}{[]int{1, 2}, []int{3}},
+	}
+
+	for _, tc := range []struct {
+		path          string
+		sum, min, max float64
+		avg           float64
+		ok            bool
+	}{
+		{"items/*/price", 7.5, 1, 4, 2.5, true},
+		{"matrix", 6, 1, 3, 2, true},
+		{"matrix/*/0", 4, 1, 3, 2, true},
+		{"items/0/price", 2.5, 2.5, 2.5, 2.5, true},
+		{"items/*/nosuchkey", 0, 0, 0, 0, false},
+		{"nosuchkey", 0, 0, 0, 0, false},
+	} {
+		if v := SumFloat(root, tc.path); v != tc.sum {
+			t.Errorf("SumFloat [%q]: expected %v, got %v", tc.path, tc.sum, v)
+		}
+		if v, ok := MinFloat(root, tc.path); v != tc.min || ok != tc.ok {
+			t.Errorf("MinFloat [%q]: expected %v, %v, got %v, %v", tc.path, tc.min, tc.ok, v, ok)
+		}
+		if v, ok := MaxFloat(root, tc.path); v != tc.max || ok != tc.ok {
+			t.Errorf("MaxFloat [%q]: expected %v, %v, got %v, %v", tc.path, tc.max, tc.ok, v, ok)
+		}
+		if v, ok := Avg(root, tc.path); v != tc.avg || ok != tc.ok {
+			t.Errorf("Avg [%q]: expected %v, %v, got %v, %v", tc.path, tc.avg, tc.ok, v, ok)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package jq
+
+import "fmt"
+
+// Coalesce returns the result of the first of paths for which QQ returns a value that
+// is neither nil nor an error, or nil if there is none.
+// It is useful for documents that moved fields between schema versions:
+//
+//	replicas := Coalesce(root, "spec/replicas", "replicas")
+func Coalesce(root interface{}, paths ...string) interface{} {
+	for _, p := range paths {
+		v := QQ(root, p)
+		if _, ok := v.(error); ok || v == nil {
+			continue
+		}
+		return v
+	}
+	return nil
+}
+
+// GetMany returns QQ(root, p) for each of paths, in the same order.
+// Paths that share a prefix share the traversal of that prefix, up to the first ALL.
+func GetMany(root interface{}, paths ...string) []interface{} {
+	t := &pathTrie{}
+	r := make([]interface{}, len(paths))
+	for i, p := range paths {
+		index, err := parsePath(p)
+		if err != nil {
+			r[i] = err
+			continue
+		}
+		t.insert(i, index)
+	}
+	t.eval(root, r)
+	return r
+}
+
+// GetManyMap is like GetMany, but returns the results keyed by path.
+func GetManyMap(root interface{}, paths ...string) map[string]interface{} {
+	m := make(map[string]interface{}, len(paths))
+	for i, v := range GetMany(root, paths...) {
+		m[paths[i]] = v
+	}
+	return m
+}
+
+// pathTrie holds a set of paths with their common prefixes merged.
+type pathTrie struct {
+	ends     []int // paths that end at this node
+	rest     []pathRest
+	keys     []string
+	children map[string]*pathTrie
+}
+
+// pathRest is the remainder of a path from its first quantifier on, which is not shared.
+type pathRest struct {
+	i     int
+	index []interface{}
+}
+
+func (t *pathTrie) insert(i int, index []interface{}) {
+	for len(index) > 0 {
+		k, ok := index[0].(string)
+		if !ok {
+			t.rest = append(t.rest, pathRest{i, index})
+			return
+		}
+		c := t.children[k]
+		if c == nil {
+			if t.children == nil {
+				t.children = make(map[string]*pathTrie)
+			}
+			c = &pathTrie{}
+			t.children[k] = c
+			t.keys = append(t.keys, k)
+		}
+		t, index = c, index[1:]
+	}
+	t.ends = append(t.ends, i)
+}
+
+// eval stores the result of each path under t, applied to v, in r.
+func (t *pathTrie) eval(v interface{}, r []interface{}) {
+	for _, i := range t.ends {
+		r[i] = v
+	}
+	for _, p := range t.rest {
+		r[p.i] = Q(v, p.index...)
+	}
+	for _, k := range t.keys {
+		c := t.children[k]
+		cv := Q(v, k)
+		if _, ok := cv.(error); ok || cv == nil {
+			c.fill(cv, r)
+			continue
+		}
+		c.eval(cv, r)
+	}
+}
+
+// fill stores v as the result of every path under t.
+func (t *pathTrie) fill(v interface{}, r []interface{}) {
+	for _, i := range t.ends {
+		r[i] = v
+	}
+	for _, p := range t.rest {
+		r[p.i] = v
+	}
+	for _, c := range t.children {
+		c.fill(v, r)
+	}
+}
+
+// Table selects the array or other container at rowsPath and returns one row per element,
+// in the order of Values, holding the result of each of columns applied to the element.
+// Missing fields and errors in a cell yield nil.
+// It returns an error if rowsPath does not select a container.
+func Table(root interface{}, rowsPath string, columns ...string) ([][]interface{}, error) {
+	c := QQ(root, rowsPath)
+	if err, ok := c.(error); ok {
+		return nil, err
+	}
+	if o := order(c); o != orderArray && o != orderObject {
+		return nil, fmt.Errorf("cannot use %v (type %T) as table rows", c, c)
+	}
+	rows := [][]interface{}{}
+	for _, e := range elements(c) {
+		row := GetMany(e, columns...)
+		for i, v := range row {
+			if _, ok := v.(error); ok {
+				row[i] = nil
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
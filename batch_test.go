@@ -0,0 +1,75 @@
+package jq
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCoalesce(t *testing.T) {
+	for _, tc := range []struct {
+		paths  []string
+		expect interface{}
+	}{
+		{[]string{"nosuchkey", "foo/x", "bar", "foo"}, 2.},
+		{[]string{"test"}, "Hello, world!"},
+		{[]string{"nosuchkey", "foo/x"}, nil},
+		{nil, nil},
+	} {
+		if v := Coalesce(testObj, tc.paths...); v != tc.expect {
+			t.Errorf("%q:  expected %v, got %v", tc.paths, tc.expect, v)
+		}
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	paths := []string{
+		"subobj/subsubobj/bar",
+		"subobj/subsubobj/array/1",
+		"subobj/foo",
+		"array/*/foo",
+		"subobj/nosuchkey/x",
+		"foo/x/y",
+		"",
+		"subobj/subarray/*",
+	}
+	got := GetMany(testObj, paths...)
+	m := GetManyMap(testObj, paths...)
+	for i, p := range paths {
+		expect := QQ(testObj, p)
+		if _, ok := expect.(error); ok {
+			if _, ok := got[i].(error); !ok {
+				t.Errorf("[%q]: expected error, got %v (%T) ", p, got[i], got[i])
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got[i], expect) {
+			t.Errorf("[%q]:  expected %v, got %v", p, expect, got[i])
+		}
+		if !reflect.DeepEqual(m[p], expect) {
+			t.Errorf("GetManyMap [%q]:  expected %v, got %v", p, expect, m[p])
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	rows, err := Table(people, "", "name", "age", "name/x")
+	expect := [][]interface{}{
+		{"carol", 35, nil},
+		{"alice", json.Number("30"), nil},
+		{"bob", 4.5, nil},
+		{"dave", nil, nil},
+	}
+	if err != nil || !reflect.DeepEqual(rows, expect) {
+		t.Errorf("Table: expected %v, got %v, %v", expect, rows, err)
+	}
+
+	if rows, err := Table(testObj, "array/*/foo", ""); err != nil || len(rows) != 3 {
+		t.Errorf("Table of ALL: got %v, %v", rows, err)
+	}
+	for _, p := range []string{"foo", "foo/x", "nosuchkey"} {
+		if _, err := Table(testObj, p, "a"); err == nil {
+			t.Errorf("[%q]: expected error", p)
+		}
+	}
+}
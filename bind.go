@@ -0,0 +1,109 @@
+package jq
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Bind fills the fields of the struct pointed to by dst from root. Each field tagged with
+// `jqpath:"path"` is set to the value at that slash separated path, converted as by DecodePath.
+// Untagged struct fields are bound from root in turn, so tagged fields may be grouped into
+// nested structs. A field whose tag ends in ",optional", as in `jqpath:"a/b,optional"`,
+// is left unmodified if the value is not present; otherwise a missing value is an error wrapping ErrNotFound.
+// Bind sets as many fields as it can and returns the errors for all others joined together.
+func Bind(root interface{}, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot bind into %T, need a non-nil pointer to a struct", dst)
+	}
+	var errs []error
+	bindStruct(root, rv.Elem(), &errs)
+	return errors.Join(errs...)
+}
+
+// bindStruct fills the tagged fields of the struct dst, appending failures to errs.
+func bindStruct(root interface{}, dst reflect.Value, errs *[]error) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag, ok := f.Tag.Lookup("jqpath")
+		if !ok {
+			if f.Type.Kind() == reflect.Struct && f.Type != timeType {
+				bindStruct(root, dst.Field(i), errs)
+			}
+			continue
+		}
+		path, opt, _ := strings.Cut(tag, ",")
+		if path == "-" {
+			continue
+		}
+		v, err := resolve(root, path)
+		if err != nil {
+			if opt == "optional" && errors.Is(err, ErrNotFound) {
+				continue
+			}
+			*errs = append(*errs, fmt.Errorf("%s: %w", f.Name, err))
+			continue
+		}
+		if err := weakDecode(path, v, dst.Field(i)); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", f.Name, err))
+		}
+	}
+}
+
+// BindMap fills each pointer in dsts from the value at the slash separated path it is keyed by,
+// converted as by DecodePath. Every path is tried; the errors for those that fail, including
+// errors wrapping ErrNotFound for missing values, are returned joined together in path order.
+func BindMap(root interface{}, dsts map[string]interface{}) error {
+	paths := make([]string, 0, len(dsts))
+	for p := range dsts {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var errs []error
+	for _, p := range paths {
+		v, err := resolve(root, p)
+		if err == nil {
+			err = decodeTo(p, v, dsts[p])
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Scan fills destination pointers from paths in root, in the manner of sql.Rows.Scan.
+// Its arguments alternate between slash separated paths and pointers:
+//
+//	var name string
+//	var age int
+//	err := jq.Scan(root, "user/name", &name, "user/age", &age)
+//
+// Values are converted as by DecodePath. Scan stops at the first path that fails and returns its error,
+// which wraps ErrNotFound if the value is not present.
+func Scan(root interface{}, pathsAndDsts ...interface{}) error {
+	if len(pathsAndDsts)%2 != 0 {
+		return fmt.Errorf("Scan: expected pairs of path and destination, got %d arguments", len(pathsAndDsts))
+	}
+	for i := 0; i < len(pathsAndDsts); i += 2 {
+		p, ok := pathsAndDsts[i].(string)
+		if !ok {
+			return fmt.Errorf("Scan: argument %d: expected path string, got %T", i, pathsAndDsts[i])
+		}
+		v, err := resolve(root, p)
+		if err != nil {
+			return err
+		}
+		if err := decodeTo(p, v, pathsAndDsts[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package jq
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBind(t *testing.T) {
+	var dst struct {
+		Foo    int      `jqpath:"subobj/foo"`
+		First  float64  `jqpath:"subobj/subarray/0"`
+		Words  []string `jqpath:"subobj/subsubobj/array"`
+		Test   string   `jqpath:"test"`
+		Bar    string   `jqpath:"array/1/bar"`
+		Absent string   `jqpath:"nosuchkey,optional"`
+		Nested struct {
+			Hello string `jqpath:"subobj/subsubobj/array/0"`
+		}
+		Ignored string `jqpath:"-"`
+		private string
+	}
+	dst.Absent = "default"
+	if err := Bind(testObj, &dst); err != nil {
+		t.Fatal(err)
+	}
+	if dst.Foo != 1 || dst.First != 1 || len(dst.Words) != 2 || dst.Test != "Hello, world!" || dst.Bar != "2" ||
+		dst.Absent != "default" || dst.Nested.Hello != "hello" {
+		t.Errorf("Bind: got %+v", dst)
+	}
+
+	var bad struct {
+		Missing int  `jqpath:"nosuchkey"`
+		Wrong   bool `jqpath:"subobj/subsubobj/array"`
+		Foo     int  `jqpath:"subobj/foo"`
+	}
+	err := Bind(testObj, &bad)
+	if err == nil || !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "Wrong") {
+		t.Errorf("Bind errors: got %v", err)
+	}
+	if bad.Foo != 1 {
+		t.Errorf("Bind: expected remaining fields to be set, got %+v", bad)
+	}
+
+	if err := Bind(testObj, dst); err == nil {
+		t.Errorf("Bind non-pointer: expected error")
+	}
+}
+
+func TestBindMap(t *testing.T) {
+	var (
+		foo   int
+		words []string
+		bar   string
+		n     int
+	)
+	if err := BindMap(testObj, map[string]interface{}{
+		"subobj/foo":             &foo,
+		"subobj/subsubobj/array": &words,
+		"array/1/bar":            &bar,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if foo != 1 || len(words) != 2 || bar != "2" {
+		t.Errorf("BindMap: got %v %v %q", foo, words, bar)
+	}
+
+	err := BindMap(testObj, map[string]interface{}{
+		"nosuchkey":  &n,
+		"test":       &n,
+		"subobj/foo": n,
+	})
+	if err == nil || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("BindMap errors: got %v", err)
+	}
+	if lines := strings.Split(err.Error(), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[0], "nosuchkey") {
+		t.Errorf("BindMap: expected three errors in path order, got %q", lines)
+	}
+}
+
+func TestScan(t *testing.T) {
+	var (
+		foo   int
+		hello string
+		bar   float64
+	)
+	if err := Scan(testObj, "subobj/foo", &foo, "subobj/subsubobj/array/0", &hello, "array/1/bar", &bar); err != nil {
+		t.Fatal(err)
+	}
+	if foo != 1 || hello != "hello" || bar != 2 {
+		t.Errorf("Scan: got %v %q %v", foo, hello, bar)
+	}
+
+	foo = 0
+	for _, args := range [][]interface{}{
+		{"subobj/foo"},
+		{&foo, "subobj/foo"},
+		{"nosuchkey", &foo},
+		{"test", &foo},
+		{"subobj/foo", foo},
+	} {
+		if err := Scan(testObj, args...); err == nil {
+			t.Errorf("Scan%v: expected error", args)
+		}
+	}
+	if err := Scan(testObj, "nosuchkey", &foo); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Scan missing: expected ErrNotFound, got %v", err)
+	}
+	if err := Scan(testObj); err != nil {
+		t.Errorf("Scan without arguments: got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package jq
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Canonicalize returns a copy of root in a canonical form, so that documents that are
+// equal produce the same JSON encoding, for hashing and diffing:
+//
+//   - objects, including maps of any type and OrderedMaps, become *Ordered values with
+//     their keys sorted;
+//   - arrays and slices become []interface{}, except byte slices, which become
+//     base64 strings like encoding/json encodes them;
+//   - numbers become json.Number values in their shortest form, integers without
+//     a fraction or exponent, so 1, 1.0 and json.Number("1e0") all become json.Number("1");
+//   - structs and other values implementing json.Marshaler are encoded with encoding/json,
+//     honoring their struct tags, and the result is canonicalized, and values implementing
+//     encoding.TextMarshaler become strings;
+//   - json.RawMessage values are decoded.
+//
+// It returns an error if a value cannot be encoded as JSON, such as a channel or a NaN.
+func Canonicalize(root interface{}) (interface{}, error) {
+	return canonicalize(root)
+}
+
+// MarshalCanonical returns the JSON encoding of the canonical form of v, as returned by
+// Canonicalize, without insignificant white space.
+func MarshalCanonical(v interface{}) ([]byte, error) {
+	c, err := canonicalize(v)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(c)
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+func canonicalize(v interface{}) (interface{}, error) {
+	switch vv := v.(type) {
+	case nil, bool, string:
+		return v, nil
+	case json.RawMessage:
+		return canonicalJSON(vv)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(vv), nil
+	case OrderedMap:
+		return canonicalObject(v)
+	}
+	if n, ok := canonicalNumber(v); ok {
+		if n == "" {
+			return nil, fmt.Errorf("%v is not a valid JSON number", v)
+		}
+		return json.Number(n), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.Type().Implements(jsonMarshalerType):
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return canonicalJSON(b)
+	case rv.Type().Implements(textMarshalerType):
+		b, err := v.(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return canonicalize(rv.Elem().Interface())
+	case reflect.Struct:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		return canonicalJSON(b)
+	case reflect.Map:
+		return canonicalObject(v)
+	case reflect.Array, reflect.Slice:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		a := make([]interface{}, rv.Len())
+		for i := range a {
+			e, err := canonicalize(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			a[i] = e
+		}
+		return a, nil
+	}
+	return nil, fmt.Errorf("cannot canonicalize type %T", v)
+}
+
+// canonicalJSON decodes b and canonicalizes the result.
+func canonicalJSON(b []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var v interface{}
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+	return canonicalize(v)
+}
+
+// canonicalObject returns the entries of the map or OrderedMap v as an *Ordered with sorted keys.
+func canonicalObject(v interface{}) (interface{}, error) {
+	kk, _ := keys(v).([]interface{})
+	o := &Ordered{keys: make([]string, 0, len(kk)), vals: make(map[string]interface{}, len(kk))}
+	for _, k := range kk {
+		e, err := canonicalize(Q(v, k))
+		if err != nil {
+			return nil, err
+		}
+		ks := fmt.Sprint(k)
+		o.keys = append(o.keys, ks)
+		o.vals[ks] = e
+	}
+	sort.Strings(o.keys)
+	return o, nil
+}
+
+// canonicalNumber returns the shortest form of the number v, or "" if v is a number
+// that JSON cannot represent. It returns false if v is not a number.
+func canonicalNumber(v interface{}) (string, bool) {
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), true
+	}
+	_, isNumber := v.(json.Number)
+	if isNumber {
+		if i, err := strconv.ParseInt(string(v.(json.Number)), 10, 64); err == nil {
+			return strconv.FormatInt(i, 10), true
+		}
+	}
+	f, ok := number(v)
+	if !ok {
+		return "", isNumber
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", true
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), true
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64), true
+}
@@ -0,0 +1,56 @@
+package jq
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMarshalCanonical(t *testing.T) {
+	type point struct {
+		Y int `json:"y"`
+		X int `json:"x"`
+	}
+	for _, tc := range []struct {
+		v      interface{}
+		expect string
+	}{
+		{map[string]interface{}{"b": 1, "a": []interface{}{true, nil, "s"}}, `{"a":[true,null,"s"],"b":1}`},
+		{map[string]interface{}{"n": 1.0, "m": json.Number("1e0"), "l": int8(1), "k": 2.5, "j": json.Number("-0"), "i": uint64(math.MaxUint64)},
+			`{"i":18446744073709551615,"j":0,"k":2.5,"l":1,"m":1,"n":1}`},
+		{map[int]string{10: "x", 9: "y"}, `{"10":"x","9":"y"}`},
+		{point{Y: 1, X: 2}, `{"x":2,"y":1}`},
+		{&point{Y: 1}, `{"x":0,"y":1}`},
+		{json.RawMessage(`{"z": 1.50, "a": [1e2]}`), `{"a":[100],"z":1.5}`},
+		{&Ordered{keys: []string{"b", "a"}, vals: map[string]interface{}{"a": 1, "b": 2}}, `{"a":1,"b":2}`},
+		{[]byte("hi"), `"aGk="`},
+		{[2]int{1, 2}, `[1,2]`},
+		{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), `"2024-01-02T03:04:05Z"`},
+		{1e21, `1e+21`},
+		{[]interface{}(nil), `null`},
+	} {
+		b, err := MarshalCanonical(tc.v)
+		if err != nil || string(b) != tc.expect {
+			t.Errorf("%v: expected %s, got %s, %v", tc.v, tc.expect, b, err)
+		}
+	}
+
+	for _, v := range []interface{}{math.NaN(), json.Number("abc"), map[string]interface{}{"c": make(chan int)}} {
+		if _, err := MarshalCanonical(v); err == nil {
+			t.Errorf("%v: expected error", v)
+		}
+	}
+
+	a, _ := MarshalCanonical(map[string]interface{}{"x": 1, "y": []interface{}{1.0, "a"}})
+	b, _ := MarshalCanonical(map[string]int{"y": 0, "x": 1})
+	c, _ := MarshalCanonical(map[string]interface{}{"y": []interface{}{json.Number("1"), "a"}, "x": 1.0})
+	if string(a) == string(b) || string(a) != string(c) {
+		t.Errorf("canonical forms: %s, %s, %s", a, b, c)
+	}
+
+	v, err := Canonicalize(map[string]interface{}{"b": 1, "a": 2})
+	if o, ok := v.(*Ordered); err != nil || !ok || o.Keys()[0] != "a" {
+		t.Errorf("Canonicalize: got %v, %v", v, err)
+	}
+}
@@ -0,0 +1,11 @@
+module github.com/gtrevg/go-jq/cmd/jq
+
+go 1.25.0
+
+require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/gtrevg/go-jq v0.0.0-00010101000000-000000000000
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/gtrevg/go-jq => ../..
@@ -0,0 +1,227 @@
+/*
+Command jq applies jq.QQ paths to JSON documents.
+
+Usage:
+
+	jq [-i json|yaml|toml] [-o json|raw|tsv|csv] [-H] -p path [-p path ...] [file ...]
+
+Each file, or standard input if no files are given, must hold one JSON, YAML or TOML document.
+Unless the input format is given with -i, it is chosen by the file extension
+(.yaml, .yml or .toml), and JSON is assumed for other files and standard input.
+Every path is applied to every document, and one result line or row is written per document:
+
+	json	a JSON array of the results, or the result itself for a single path
+	raw	the results separated by spaces, strings without quotes
+	tsv	the results separated by tabs, strings without quotes, with tabs, newlines,
+		carriage returns and backslashes in them escaped as \t, \n, \r and \\
+	csv	the results as a CSV record
+
+With -H, tsv and csv output starts with a header row holding the paths.
+Missing values are written as null in json output and as empty fields otherwise.
+Objects and arrays are always written as compact JSON.
+*/
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+type pathList []string
+
+func (p *pathList) String() string     { return strings.Join(*p, ",") }
+func (p *pathList) Set(s string) error { *p = append(*p, s); return nil }
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("jq", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var paths pathList
+	fs.Var(&paths, "p", "`path` to extract, may be repeated")
+	format := fs.String("o", "json", "output `format`: json, raw, tsv or csv")
+	header := fs.Bool("H", false, "write a header row for tsv and csv output")
+	input := fs.String("i", "", "input `format`: json, yaml or toml (default from the file extension)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if len(paths) == 0 {
+		fmt.Fprintln(stderr, "jq: at least one -p path is required")
+		return 2
+	}
+
+	w := bufio.NewWriter(stdout)
+	defer w.Flush()
+
+	var write func(vals []interface{}) error
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(w)
+		write = func(vals []interface{}) error {
+			if len(vals) == 1 {
+				return enc.Encode(vals[0])
+			}
+			return enc.Encode(vals)
+		}
+	case "raw":
+		write = func(vals []interface{}) error {
+			s := make([]string, len(vals))
+			for i, v := range vals {
+				s[i] = text(v)
+			}
+			_, err := fmt.Fprintln(w, strings.Join(s, " "))
+			return err
+		}
+	case "tsv":
+		writeRow := func(s []string) error {
+			for i := range s {
+				s[i] = tsvEscaper.Replace(s[i])
+			}
+			_, err := fmt.Fprintln(w, strings.Join(s, "\t"))
+			return err
+		}
+		write = func(vals []interface{}) error {
+			s := make([]string, len(vals))
+			for i, v := range vals {
+				s[i] = text(v)
+			}
+			return writeRow(s)
+		}
+		if *header {
+			if err := writeRow(append([]string(nil), paths...)); err != nil {
+				fmt.Fprintf(stderr, "jq: %v\n", err)
+				return 1
+			}
+		}
+	case "csv":
+		cw := csv.NewWriter(w)
+		if *header {
+			if err := cw.Write(paths); err != nil {
+				fmt.Fprintf(stderr, "jq: %v\n", err)
+				return 1
+			}
+		}
+		write = func(vals []interface{}) error {
+			s := make([]string, len(vals))
+			for i, v := range vals {
+				s[i] = text(v)
+			}
+			if err := cw.Write(s); err != nil {
+				return err
+			}
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		fmt.Fprintf(stderr, "jq: unknown output format %q\n", *format)
+		return 2
+	}
+	switch *input {
+	case "", "json", "yaml", "toml":
+	default:
+		fmt.Fprintf(stderr, "jq: unknown input format %q\n", *input)
+		return 2
+	}
+
+	inputs := fs.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	status := 0
+	for _, name := range inputs {
+		root, err := decode(name, *input, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "jq: %s: %v\n", name, err)
+			status = 1
+			continue
+		}
+		vals := make([]interface{}, len(paths))
+		for i, p := range paths {
+			vals[i] = jq.QQ(root, p)
+			if err, ok := vals[i].(error); ok {
+				fmt.Fprintf(stderr, "jq: %s: %s: %v\n", name, p, err)
+				vals[i], status = nil, 1
+			}
+		}
+		if err := write(vals); err != nil {
+			fmt.Fprintf(stderr, "jq: %v\n", err)
+			return 1
+		}
+	}
+	return status
+}
+
+func decode(name, format string, stdin io.Reader) (interface{}, error) {
+	r := stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(name)) {
+		case ".yaml", ".yml":
+			format = "yaml"
+		case ".toml":
+			format = "toml"
+		default:
+			format = "json"
+		}
+	}
+
+	var v interface{}
+	switch format {
+	case "yaml":
+		if err := yaml.NewDecoder(r).Decode(&v); err != nil {
+			return nil, err
+		}
+	case "toml":
+		var m map[string]interface{}
+		if _, err := toml.NewDecoder(r).Decode(&m); err != nil {
+			return nil, err
+		}
+		v = m
+	default:
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// tsvEscaper escapes the characters that would break a tsv field, like jq's @tsv.
+var tsvEscaper = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+
+// text formats v for the raw, tsv and csv output formats.
+func text(v interface{}) string {
+	switch vv := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return vv
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.json")
+	os.WriteFile(a, []byte(`{"name": "alice", "age": 30, "tags": ["x"]}`), 0o644)
+	os.WriteFile(b, []byte(`{"name": "bob, jr", "age": 4.5}`), 0o644)
+	y := filepath.Join(dir, "c.yml")
+	os.WriteFile(y, []byte("name: carol\nage: 41\ntags: [y, z]\n"), 0o644)
+	tm := filepath.Join(dir, "d.toml")
+	os.WriteFile(tm, []byte("name = \"dave\"\n[server]\nport = 8080\n"), 0o644)
+
+	for _, tc := range []struct {
+		args   []string
+		stdin  string
+		expect string
+	}{
+		{[]string{"-p", "name"}, `{"name": "carol"}`, "\"carol\"\n"},
+		{[]string{"-p", "name", "-p", "age", a, b}, "", "[\"alice\",30]\n[\"bob, jr\",4.5]\n"},
+		{[]string{"-o", "raw", "-p", "name", "-p", "tags", a}, "", "alice [\"x\"]\n"},
+		{[]string{"-o", "tsv", "-H", "-p", "name", "-p", "tags/0", a, b}, "", "name\ttags/0\nalice\tx\nbob, jr\t\n"},
+		{[]string{"-o", "csv", "-H", "-p", "name", "-p", "age", a, b}, "", "name,age\nalice,30\n\"bob, jr\",4.5\n"},
+		{[]string{"-o", "tsv", "-p", "name", "-p", "tags/1", y}, "", "carol\tz\n"},
+		{[]string{"-p", "server/port", tm}, "", "8080\n"},
+		{[]string{"-i", "yaml", "-p", "a/b"}, "a: {b: 1}", "1\n"},
+		{[]string{"-i", "toml", "-p", "a"}, "a = true", "true\n"},
+		{[]string{"-o", "tsv", "-p", "a", "-p", "b"}, `{"a": "x\ty\nz", "b": "c:\\d"}`, "x\\ty\\nz\tc:\\\\d\n"},
+	} {
+		var out, errs bytes.Buffer
+		if st := run(tc.args, strings.NewReader(tc.stdin), &out, &errs); st != 0 {
+			t.Errorf("%v: exit status %d: %s", tc.args, st, errs.String())
+		}
+		if out.String() != tc.expect {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expect, out.String())
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"-o", "xml", "-p", "a"},
+		{"-i", "xml", "-p", "a"},
+	} {
+		var out, errs bytes.Buffer
+		if st := run(args, strings.NewReader("{}"), &out, &errs); st != 2 {
+			t.Errorf("%v: expected exit status 2, got %d", args, st)
+		}
+	}
+
+	var out, errs bytes.Buffer
+	if st := run([]string{"-p", "a", "nosuchfile"}, nil, &out, &errs); st != 1 || errs.Len() == 0 {
+		t.Errorf("missing file: got status %d, %q", st, errs.String())
+	}
+}
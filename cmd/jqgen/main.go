@@ -0,0 +1,374 @@
+/*
+Command jqgen generates typed accessors for documents that have the shape of Go struct types.
+
+Usage:
+
+	jqgen -type T[,T...] [-tag json] [-output file] [dir]
+
+For every exported field of each named struct type declared in the package in dir,
+or the current directory, jqgen writes a function that reads the value at the path of
+that field from a document and converts it to the type of the field with jq.Get:
+
+	//go:generate jqgen -type User
+
+	type User struct {
+		Email   string `json:"email"`
+		Address struct {
+			City string `json:"city"`
+		} `json:"address"`
+	}
+
+yields, among others,
+
+	func UserEmail(root interface{}) (string, error)
+	func UserAddressCity(root interface{}) (string, error)
+
+which read "email" and "address/city" from a decoded JSON document. Path elements are
+the names given by the struct tag, json by default, or the field names; fields tagged "-"
+are skipped. Fields of struct types declared in the same package, and pointers to them,
+get accessors for their own fields as well, and embedded structs contribute their fields
+to the enclosing type. The paths are package-level slices of index elements, so no path
+is parsed when an accessor is called. Two fields whose names concatenate to the same
+accessor name, such as AddressCity and Address.City, are an error.
+
+The output is written to <type>_jq.go, after the first type, unless -output is given.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+func run(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("jqgen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	typeNames := fs.String("type", "", "comma-separated list of struct type `names`")
+	tag := fs.String("tag", "json", "struct tag `key` naming the path elements")
+	output := fs.String("output", "", "output `file` (default <type>_jq.go)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *typeNames == "" {
+		fmt.Fprintln(stderr, "jqgen: -type is required")
+		return 2
+	}
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+
+	src, err := generate(dir, types, *tag)
+	if err != nil {
+		fmt.Fprintf(stderr, "jqgen: %v\n", err)
+		return 1
+	}
+	name := *output
+	if name == "" {
+		name = filepath.Join(dir, strings.ToLower(types[0])+"_jq.go")
+	}
+	if err := os.WriteFile(name, src, 0o644); err != nil {
+		fmt.Fprintf(stderr, "jqgen: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// accessor describes a generated function.
+type accessor struct {
+	name string   // of the function
+	path []string // to the value
+	typ  string   // of the result
+}
+
+// generator collects the accessors of the requested types.
+type generator struct {
+	fset    *token.FileSet
+	tag     string
+	structs map[string]*ast.StructType
+	files   map[string]*ast.File // declaring each struct
+	imports map[string]string    // used by the field types, path by name
+	out     []accessor
+}
+
+// generate returns the formatted source of the accessors for types in the package in dir.
+func generate(dir string, types []string, tag string) ([]byte, error) {
+	g := &generator{
+		fset:    token.NewFileSet(),
+		tag:     tag,
+		structs: make(map[string]*ast.StructType),
+		files:   make(map[string]*ast.File),
+		imports: make(map[string]string),
+	}
+	names, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	pkg := ""
+	for _, name := range names {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(g.fset, name, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if ast.IsGenerated(f) {
+			continue
+		}
+		pkg = f.Name.Name
+		for _, d := range f.Decls {
+			gd, ok := d.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				ts := s.(*ast.TypeSpec)
+				if st, ok := ts.Type.(*ast.StructType); ok && ts.TypeParams == nil {
+					g.structs[ts.Name.Name] = st
+					g.files[ts.Name.Name] = f
+				}
+			}
+		}
+	}
+	if pkg == "" {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	for _, t := range types {
+		st, ok := g.structs[t]
+		if !ok {
+			return nil, fmt.Errorf("struct type %s not found in %s", t, dir)
+		}
+		if err := g.fields(t, nil, st, g.files[t], map[string]bool{t: true}); err != nil {
+			return nil, err
+		}
+	}
+	paths := make(map[string][]string, len(g.out))
+	for _, a := range g.out {
+		if p, ok := paths[a.name]; ok {
+			return nil, fmt.Errorf("accessor %s would be generated for both %q and %q", a.name, strings.Join(p, "/"), strings.Join(a.path, "/"))
+		}
+		paths[a.name] = a.path
+	}
+	return g.source(pkg, types)
+}
+
+// fields adds the accessors for the fields of st, named after prefix and found under path.
+// The struct types in seen are not descended into again, to end recursive types.
+func (g *generator) fields(prefix string, path []string, st *ast.StructType, file *ast.File, seen map[string]bool) error {
+	for _, f := range st.Fields.List {
+		key, omit := "", false
+		if f.Tag != nil {
+			tag, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return err
+			}
+			key, _, _ = strings.Cut(reflect.StructTag(tag).Get(g.tag), ",")
+			omit = key == "-"
+		}
+		if omit {
+			continue
+		}
+
+		if len(f.Names) == 0 { // embedded
+			if local, _ := g.local(f.Type); local != "" && key == "" {
+				if !seen[local] {
+					seen[local] = true
+					if err := g.fields(prefix, path, g.structs[local], g.files[local], seen); err != nil {
+						return err
+					}
+					delete(seen, local)
+				}
+				continue
+			}
+			name := embeddedName(f.Type)
+			if !ast.IsExported(name) {
+				continue
+			}
+			if key == "" {
+				key = name
+			}
+			if err := g.field(prefix+name, append(path[:len(path):len(path)], key), f.Type, nil, file, seen); err != nil {
+				return err
+			}
+			continue
+		}
+
+		for _, n := range f.Names {
+			if !n.IsExported() {
+				continue
+			}
+			k := key
+			if k == "" {
+				k = n.Name
+			}
+			var inline *ast.StructType
+			if s, ok := f.Type.(*ast.StructType); ok {
+				inline = s
+			}
+			if err := g.field(prefix+n.Name, append(path[:len(path):len(path)], k), f.Type, inline, file, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// field adds the accessor for a field of type typ, and those for its own fields if it is
+// a struct declared inline or in the package.
+func (g *generator) field(name string, path []string, typ ast.Expr, inline *ast.StructType, file *ast.File, seen map[string]bool) error {
+	if inline != nil {
+		// anonymous struct types cannot be named in the signature of an accessor
+		return g.fields(name, path, inline, file, seen)
+	}
+	t, err := g.typeString(typ, file)
+	if err != nil {
+		return err
+	}
+	g.out = append(g.out, accessor{name: name, path: path, typ: t})
+	if local, _ := g.local(typ); local != "" && !seen[local] {
+		seen[local] = true
+		defer delete(seen, local)
+		return g.fields(name, path, g.structs[local], g.files[local], seen)
+	}
+	return nil
+}
+
+// local returns the name of the struct type declared in the package that typ,
+// or the type it points to, refers to.
+func (g *generator) local(typ ast.Expr) (string, bool) {
+	ptr := false
+	if s, ok := typ.(*ast.StarExpr); ok {
+		typ, ptr = s.X, true
+	}
+	if id, ok := typ.(*ast.Ident); ok {
+		if _, ok := g.structs[id.Name]; ok {
+			return id.Name, ptr
+		}
+	}
+	return "", false
+}
+
+// embeddedName returns the field name of an embedded field of type typ.
+func embeddedName(typ ast.Expr) string {
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.SelectorExpr:
+			return t.Sel.Name
+		case *ast.Ident:
+			return t.Name
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		default:
+			return ""
+		}
+	}
+}
+
+// typeString formats typ as written in file, recording the imports it refers to.
+func (g *generator) typeString(typ ast.Expr, file *ast.File) (string, error) {
+	var err error
+	ast.Inspect(typ, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		id, ok := sel.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if p, ok := importPath(file, id.Name); ok {
+			g.imports[id.Name] = p
+		} else if err == nil {
+			err = fmt.Errorf("%s: unknown package %s", g.fset.Position(sel.Pos()), id.Name)
+		}
+		return false
+	})
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, g.fset, typ); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// importPath returns the path of the package imported by file under name.
+func importPath(file *ast.File, name string) (string, bool) {
+	for _, is := range file.Imports {
+		p, err := strconv.Unquote(is.Path.Value)
+		if err != nil {
+			continue
+		}
+		n := p[strings.LastIndex(p, "/")+1:]
+		if is.Name != nil {
+			n = is.Name.Name
+		}
+		if n == name {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// source returns the formatted file holding the accessors.
+func (g *generator) source(pkg string, types []string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by \"jqgen -type %s\"; DO NOT EDIT.\n\n", strings.Join(types, ","))
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", pkg)
+	names := make([]string, 0, len(g.imports))
+	for n := range g.imports {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		p := g.imports[n]
+		if n == p[strings.LastIndex(p, "/")+1:] {
+			fmt.Fprintf(&b, "\t%q\n", p)
+		} else {
+			fmt.Fprintf(&b, "\t%s %q\n", n, p)
+		}
+	}
+	fmt.Fprintf(&b, "\n\tjq %q\n)\n", "github.com/gtrevg/go-jq")
+
+	b.WriteString("\nvar (\n")
+	for _, a := range g.out {
+		ee := make([]string, len(a.path))
+		for i, e := range a.path {
+			ee[i] = strconv.Quote(e)
+		}
+		fmt.Fprintf(&b, "\tjqPath%s = []interface{}{%s}\n", a.name, strings.Join(ee, ", "))
+	}
+	b.WriteString(")\n")
+
+	for _, a := range g.out {
+		fmt.Fprintf(&b, "\n// %s returns the value at %q in root as %s.\n", a.name, strings.Join(a.path, "/"), a.typ)
+		fmt.Fprintf(&b, "func %s(root interface{}) (%s, error) {\n", a.name, a.typ)
+		fmt.Fprintf(&b, "\treturn jq.Get[%s](nil, root, jqPath%s...)\n}\n", a.typ, a.name)
+	}
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const src = `package users
+
+import (
+	"time"
+
+	u "example.com/uuid"
+)
+
+type Base struct {
+	ID u.UUID ` + "`json:\"id\"`" + `
+}
+
+type User struct {
+	Base
+	Email   string ` + "`json:\"email,omitempty\"`" + `
+	Age     int
+	Secret  string ` + "`json:\"-\"`" + `
+	private string
+	Address *Address ` + "`json:\"address\"`" + `
+	Tags    []string ` + "`json:\"tags\"`" + `
+	Login   struct {
+		At time.Time ` + "`json:\"at\"`" + `
+	} ` + "`json:\"login\"`" + `
+	Manager *User ` + "`json:\"manager\"`" + `
+}
+
+type Address struct {
+	City string ` + "`json:\"city\"`" + `
+}
+`
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "users.go"), []byte(src), 0o644)
+
+	var errs bytes.Buffer
+	if st := run([]string{"-type", "User", dir}, &errs); st != 0 {
+		t.Fatalf("exit status %d: %s", st, errs.String())
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "user_jq.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	for _, s := range []string{
+		"// Code generated by \"jqgen -type User\"; DO NOT EDIT.",
+		"package users",
+		"\"time\"",
+		"u \"example.com/uuid\"",
+		"jq \"github.com/gtrevg/go-jq\"",
+		"func UserID(root interface{}) (u.UUID, error) {",
+		"func UserEmail(root interface{}) (string, error) {\n\treturn jq.Get[string](nil, root, jqPathUserEmail...)\n}",
+		"jqPathUserAge         = []interface{}{\"Age\"}",
+		"func UserAddress(root interface{}) (*Address, error) {",
+		"jqPathUserAddressCity = []interface{}{\"address\", \"city\"}",
+		"func UserTags(root interface{}) ([]string, error) {",
+		"func UserLoginAt(root interface{}) (time.Time, error) {",
+		"func UserManager(root interface{}) (*User, error) {",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output lacks %q", s)
+		}
+	}
+	for _, s := range []string{"Secret", "private", "UserManagerEmail", "func UserLogin("} {
+		if strings.Contains(out, s) {
+			t.Errorf("output contains %q", s)
+		}
+	}
+
+	// generated files are ignored on the next run
+	if st := run([]string{"-type", "User", "-output", filepath.Join(dir, "x.go"), dir}, &errs); st != 0 {
+		t.Fatalf("second run: exit status %d: %s", st, errs.String())
+	}
+
+	for _, args := range [][]string{{dir}, {"-type", "Nope", dir}, {"-type", "User", t.TempDir()}} {
+		errs.Reset()
+		if st := run(args, &errs); st == 0 {
+			t.Errorf("%v: expected failure", args)
+		}
+	}
+}
+
+func TestCollision(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "users.go"), []byte(`package users
+
+type User struct {
+	AddressCity string
+	Address     struct {
+		City string
+	}
+}
+`), 0o644)
+	var errs bytes.Buffer
+	if st := run([]string{"-type", "User", dir}, &errs); st == 0 {
+		t.Fatal("expected failure")
+	}
+	if msg := errs.String(); !strings.Contains(msg, "UserAddressCity") || !strings.Contains(msg, `"AddressCity"`) || !strings.Contains(msg, `"Address/City"`) {
+		t.Errorf("message does not name the accessor and both paths: %s", msg)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "user_jq.go")); err == nil {
+		t.Errorf("output written despite the collision")
+	}
+}
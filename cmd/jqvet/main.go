@@ -0,0 +1,23 @@
+/*
+Command jqvet checks the constant paths passed to the functions of package jq
+against the static types of their roots, as described in package jqvet.
+
+Usage:
+
+	jqvet [flags] [packages]
+
+It can also be run by go vet:
+
+	go vet -vettool=$(which jqvet) ./...
+*/
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/gtrevg/go-jq/jqvet"
+)
+
+func main() {
+	singlechecker.Main(jqvet.Analyzer)
+}
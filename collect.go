@@ -0,0 +1,362 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Values returns the values of the container found at path in a deterministic order:
+// the values of maps in the order of their sorted keys, the exported fields of structs
+// in declaration order, the values of an OrderedMap in its order and the elements of
+// arrays and slices by index.
+// It returns nil if the value at path is not present or not a container.
+func Values(root interface{}, index ...interface{}) []interface{} {
+	return elements(Q(root, index...))
+}
+
+// elements returns the values of the container v in the order of keys(v).
+func elements(v interface{}) []interface{} {
+	kk, ok := keys(v).([]interface{})
+	if !ok {
+		return nil
+	}
+	a := make([]interface{}, 0, len(kk))
+	for _, k := range kk {
+		a = append(a, Q(v, k))
+	}
+	return a
+}
+
+// Filter returns the elements of the container selected by QQ(root, path) for which pred returns true,
+// in the order of Values. Since a path ending in ALL selects a container of results,
+// "items" and "items/*/name" both filter over all items.
+// Elements that are errors are dropped without calling pred.
+func Filter(root interface{}, path string, pred func(interface{}) bool) []interface{} {
+	var a []interface{}
+	for _, e := range elements(QQ(root, path)) {
+		if _, ok := e.(error); ok {
+			continue
+		}
+		if pred(e) {
+			a = append(a, e)
+		}
+	}
+	return a
+}
+
+// MapValues applies fn to every element of the container selected by QQ(root, path)
+// and returns a container of the results of the same shape: a slice for arrays and slices,
+// a map with the same keys for maps, a map of field names for structs and an *Ordered for
+// an OrderedMap. Errors from the query are returned as the result, as with Q.
+func MapValues(root interface{}, path string, fn func(interface{}) interface{}) interface{} {
+	c := QQ(root, path)
+	if _, ok := c.(error); ok {
+		return c
+	}
+	if om, ok := c.(OrderedMap); ok {
+		r := &Ordered{vals: make(map[string]interface{})}
+		for _, k := range om.Keys() {
+			v, _ := om.Get(k)
+			r.keys = append(r.keys, k)
+			r.vals[k] = fn(v)
+		}
+		return r
+	}
+
+	switch v := reflect.ValueOf(c); v.Kind() {
+	case reflect.Array, reflect.Slice:
+		a := make([]interface{}, v.Len())
+		for i := range a {
+			a[i] = fn(v.Index(i).Interface())
+		}
+		return a
+	case reflect.Map:
+		m := reflect.MakeMap(reflect.MapOf(v.Type().Key(), reflect.TypeOf((*interface{})(nil)).Elem()))
+		for _, k := range v.MapKeys() {
+			r := reflect.ValueOf(fn(v.MapIndex(k).Interface()))
+			if !r.IsValid() {
+				r = reflect.Zero(m.Type().Elem())
+			}
+			m.SetMapIndex(k, r)
+		}
+		return m.Interface()
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.PkgPath == "" {
+				m[f.Name] = fn(v.Field(i).Interface())
+			}
+		}
+		return m
+	}
+	return fmt.Errorf("type %T is not a container", c)
+}
+
+// MapValuesInPlace is like MapValues, but stores the results of fn back into the slice or map
+// selected by QQ(root, path). It returns an error if the selection is not a slice or map,
+// or if a result cannot be assigned to its element type. Since ALL, KEYS, operators and decoding
+// JSON held in json.RawMessage or, with DecodeStrings, strings build new values that are not part
+// of root, it returns an error for paths that go through them.
+func MapValuesInPlace(root interface{}, path string, fn func(interface{}) interface{}) error {
+	index, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	c := plain.inPlace(root, index)
+	if err, ok := c.(error); ok {
+		return err
+	}
+
+	switch v := reflect.ValueOf(c); v.Kind() {
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			r, err := assign(fn(v.Index(i).Interface()), v.Type().Elem())
+			if err != nil {
+				return err
+			}
+			v.Index(i).Set(r)
+		}
+		return nil
+	case reflect.Map:
+		for _, k := range v.MapKeys() {
+			r, err := assign(fn(v.MapIndex(k).Interface()), v.Type().Elem())
+			if err != nil {
+				return err
+			}
+			v.SetMapIndex(k, r)
+		}
+		return nil
+	}
+	return fmt.Errorf("type %T cannot be updated in place", c)
+}
+
+// assign returns r as a value to store in an element of type t, nil as the zero value.
+func assign(r interface{}, t reflect.Type) (reflect.Value, error) {
+	rv := reflect.ValueOf(r)
+	switch {
+	case !rv.IsValid():
+		return reflect.Zero(t), nil
+	case rv.Type().AssignableTo(t):
+		return rv, nil
+	}
+	return reflect.Value{}, fmt.Errorf("cannot assign %v (type %T) to element of type %s", r, r, t)
+}
+
+// inPlace returns the value at index in root like c.query, or an error if reaching it builds
+// a new value, so that modifying the value in place would not modify root. Hooks are not applied.
+func (c *config) inPlace(root interface{}, index []interface{}) interface{} {
+	cc := *c
+	cc.hooks = nil
+	cur := root
+	for i, e := range index {
+		switch e.(type) {
+		case quantifier, *Operator:
+			return fmt.Errorf("cannot update the result of %v in place", e)
+		}
+		switch v := cur.(type) {
+		case json.RawMessage:
+			return fmt.Errorf("cannot update the JSON in a json.RawMessage in place")
+		case string:
+			if _, ok := decodeJSONString(v); ok && cc.decodeStrings {
+				return fmt.Errorf("cannot update the JSON in a string in place")
+			}
+		}
+		cur = cc.step(cur, index[i:i+1])
+		if _, ok := cur.(error); ok || cur == nil {
+			return cur
+		}
+	}
+	return cur
+}
+
+// Reduce folds the values selected by path into an accumulator, starting with init.
+// Paths containing ALL are traversed without building the intermediate result containers.
+// Values that are nil or errors, such as missing fields, are skipped.
+func Reduce(root interface{}, path string, init interface{}, fn func(acc, v interface{}) interface{}) interface{} {
+	acc := init
+	index, err := parsePath(path)
+	if err != nil {
+		return acc
+	}
+	stream(root, index, nil, func(_ []interface{}, v interface{}) bool {
+		if _, ok := v.(error); ok || v == nil {
+			return true
+		}
+		acc = fn(acc, v)
+		return true
+	})
+	return acc
+}
+
+// Pluck returns QQ(element, fieldPath) for every element of the container selected by
+// QQ(root, collectionPath), in the order of Values. Missing fields and errors yield nil,
+// so the result has one entry per element.
+func Pluck(root interface{}, collectionPath, fieldPath string) []interface{} {
+	a := pluck(root, collectionPath, fieldPath)
+	for i, v := range a {
+		if _, ok := v.(error); ok {
+			a[i] = nil
+		}
+	}
+	return a
+}
+
+// PluckStrings is like Pluck, but converts each field with String.
+func PluckStrings(root interface{}, collectionPath, fieldPath string) []string {
+	var a []string
+	for _, v := range pluck(root, collectionPath, fieldPath) {
+		a = append(a, asString(v))
+	}
+	return a
+}
+
+// PluckInts is like Pluck, but converts each field with Int.
+func PluckInts(root interface{}, collectionPath, fieldPath string) []int {
+	var a []int
+	for _, v := range pluck(root, collectionPath, fieldPath) {
+		a = append(a, asInt(v))
+	}
+	return a
+}
+
+// pluck returns QQ(element, fieldPath) for every element of the container selected by
+// QQ(root, collectionPath), parsing fieldPath once.
+func pluck(root interface{}, collectionPath, fieldPath string) []interface{} {
+	index, err := parsePath(fieldPath)
+	var a []interface{}
+	for _, e := range elements(QQ(root, collectionPath)) {
+		if err != nil {
+			a = append(a, err)
+			continue
+		}
+		a = append(a, Q(e, index...))
+	}
+	return a
+}
+
+// Contains reports whether the value selected by QQ(root, path) contains value:
+// for a string, whether value is a substring of it, and for any other container,
+// whether one of its elements equals value. Elements are compared deeply,
+// and numbers of different types are equal if they have the same value.
+func Contains(root interface{}, path string, value interface{}) bool {
+	c := QQ(root, path)
+	if order(c) == orderString {
+		s, ok := value.(string)
+		return ok && strings.Contains(reflect.ValueOf(c).String(), s)
+	}
+	for _, e := range elements(c) {
+		if compare(e, value) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// CountWhere returns the number of values selected by path for which pred returns true,
+// without building the intermediate results of ALL. A path without ALL selects the elements
+// of the container at path, as with Filter, but CountWhere visits each value ALL selects
+// on its own: with several ALLs it counts the innermost values, where Filter considers the
+// containers of the outer ALL, and it passes pred the null and missing values of maps, which
+// Q drops from the result of ALL. Errors are skipped.
+func CountWhere(root interface{}, path string, pred func(interface{}) bool) int {
+	index, err := parsePath(path)
+	if err != nil {
+		return 0
+	}
+	if !hasQuantifier(index, ALL) {
+		index = append(index, ALL)
+	}
+	n := 0
+	stream(root, index, nil, func(_ []interface{}, v interface{}) bool {
+		if _, ok := v.(error); !ok && pred(v) {
+			n++
+		}
+		return true
+	})
+	return n
+}
+
+// Each calls fn for every value that CountWhere(root, path, ...) would pass its predicate, with
+// the key of the value in its container, and stops as soon as fn returns false. Like CountWhere, it does
+// not build the intermediate results of ALL, so finding the first element that satisfies
+// a condition does not cost more than visiting the elements before it.
+// Elements that are errors are skipped, and fn is not called at all if there is no container at path.
+func Each(root interface{}, path string, fn func(key, value interface{}) bool) {
+	index, err := parsePath(path)
+	if err != nil {
+		return
+	}
+	if !hasQuantifier(index, ALL) {
+		index = append(index, ALL)
+	}
+	for i, e := range index {
+		if e == ALL {
+			if c := unwrap(Q(root, index[:i]...)); c == nil || order(c) != orderArray && order(c) != orderObject {
+				return
+			}
+			break
+		}
+	}
+	stream(root, index, nil, func(p []interface{}, v interface{}) bool {
+		if _, ok := v.(error); ok {
+			return true
+		}
+		var k interface{}
+		if len(p) > 0 {
+			k = p[len(p)-1]
+		}
+		return fn(k, v)
+	})
+}
+
+func hasQuantifier(index []interface{}, q quantifier) bool {
+	for _, i := range index {
+		if i == q {
+			return true
+		}
+	}
+	return false
+}
+
+// Compact returns the container selected by QQ(root, path) without its nil entries,
+// such as JSON nulls and the results of ALL for elements that lack a field.
+// Arrays and slices yield a []interface{}, maps a map with the same key type
+// and an OrderedMap an *Ordered. Other values are returned as an error.
+func Compact(root interface{}, path string) interface{} {
+	c := QQ(root, path)
+	if _, ok := c.(error); ok {
+		return c
+	}
+	if om, ok := c.(OrderedMap); ok {
+		r := &Ordered{vals: make(map[string]interface{})}
+		for _, k := range om.Keys() {
+			if v, _ := om.Get(k); order(v) != orderNull {
+				r.keys = append(r.keys, k)
+				r.vals[k] = v
+			}
+		}
+		return r
+	}
+
+	switch v := reflect.ValueOf(c); v.Kind() {
+	case reflect.Array, reflect.Slice:
+		a := []interface{}{}
+		for i := 0; i < v.Len(); i++ {
+			if e := v.Index(i).Interface(); order(e) != orderNull {
+				a = append(a, e)
+			}
+		}
+		return a
+	case reflect.Map:
+		m := reflect.MakeMap(reflect.MapOf(v.Type().Key(), reflect.TypeOf((*interface{})(nil)).Elem()))
+		for _, k := range v.MapKeys() {
+			if e := v.MapIndex(k); order(e.Interface()) != orderNull {
+				m.SetMapIndex(k, e)
+			}
+		}
+		return m.Interface()
+	}
+	return fmt.Errorf("type %T is not an array or map", c)
+}
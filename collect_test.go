@@ -0,0 +1,292 @@
+package jq
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestValues(t *testing.T) {
+	for _, tc := range []struct {
+		root   interface{}
+		path   []interface{}
+		expect []interface{}
+	}{
+		{testObj, []interface{}{"subobj", "subsubobj"}, []interface{}{[]interface{}{"hello", "world"}, 2., 3.}},
+		{testObj, []interface{}{"subobj", "subarray"}, []interface{}{1., 2., 3.}},
+		{testStruct, []interface{}{"subobj", "subsubobj"}, []interface{}{2, 3, []string{"hello", "world"}}},
+		{map[int]string{3: "c", 1: "a", 2: "b"}, nil, []interface{}{"a", "b", "c"}},
+		{testObj, []interface{}{"foo"}, nil},
+		{testObj, []interface{}{"nosuchkey"}, nil},
+	} {
+		if v := Values(tc.root, tc.path...); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%#v [%v]:  expected %v, got %v", tc.root, tc.path, tc.expect, v)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	isFloat := func(v interface{}) bool { _, ok := v.(float64); return ok }
+	for _, tc := range []struct {
+		root   interface{}
+		path   string
+		pred   func(interface{}) bool
+		expect []interface{}
+	}{
+		{testObj, "subobj/subarray", func(v interface{}) bool { return v.(float64) > 1 }, []interface{}{2., 3.}},
+		{testObj, "array/*/foo", isFloat, []interface{}{1.}},
+		{testObj, "array", func(v interface{}) bool { return Q(v, "bar") != nil }, []interface{}{map[string]interface{}{"bar": 2.}}},
+		{testObj, "subobj/subsubobj", isFloat, []interface{}{2., 3.}},
+		{testObj, "array/*/foo/x", isFloat, nil}, // all errors
+		{testObj, "nosuchkey", isFloat, nil},
+	} {
+		if v := Filter(tc.root, tc.path, tc.pred); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("[%q]:  expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+}
+
+func TestMapValues(t *testing.T) {
+	double := func(v interface{}) interface{} { return v.(float64) * 2 }
+	for _, tc := range []struct {
+		root   interface{}
+		path   string
+		expect interface{}
+	}{
+		{testObj, "subobj/subarray", []interface{}{2., 4., 6.}},
+		{testObj, "subobj/subsubobj/*/x", map[string]interface{}{}},
+		{map[string]float64{"a": 1}, "", map[string]interface{}{"a": 2.}},
+		{struct{ A, B float64 }{1, 2}, "", map[string]interface{}{"A": 2., "B": 4.}},
+		{testObj, "foo", ee},
+		{testObj, "foo/x", ee},
+	} {
+		v := MapValues(tc.root, tc.path, double)
+		if _, ok := tc.expect.(error); ok {
+			if _, ok := v.(error); !ok {
+				t.Errorf("[%q]: expected error, got %v (%T) ", tc.path, v, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("[%q]:  expected %v, got %v (%T)", tc.path, tc.expect, v, v)
+		}
+	}
+}
+
+func TestMapValuesInPlace(t *testing.T) {
+	root := map[string]interface{}{
+		"a": []interface{}{1., 2.},
+		"m": map[string]float64{"x": 1},
+		"s": []string{"x"},
+	}
+	double := func(v interface{}) interface{} { return v.(float64) * 2 }
+
+	if err := MapValuesInPlace(root, "a", double); err != nil {
+		t.Errorf("[%q]: unexpected error %v", "a", err)
+	}
+	if err := MapValuesInPlace(root, "m", double); err != nil {
+		t.Errorf("[%q]: unexpected error %v", "m", err)
+	}
+	if !reflect.DeepEqual(root["a"], []interface{}{2., 4.}) || root["m"].(map[string]float64)["x"] != 2 {
+		t.Errorf("not updated in place: %v", root)
+	}
+	if err := MapValuesInPlace(root, "s", func(interface{}) interface{} { return 1 }); err == nil {
+		t.Errorf("[%q]: expected error", "s")
+	}
+	if err := MapValuesInPlace(root, "a/0", double); err == nil {
+		t.Errorf("[%q]: expected error", "a/0")
+	}
+	nested := map[string]interface{}{
+		"l":   []interface{}{[]interface{}{1.}},
+		"raw": json.RawMessage(`{"a": [1]}`),
+		"b64": "WzFd",
+	}
+	for _, path := range []string{"*", "l/*", "raw/a", "b64/@base64d"} {
+		if err := MapValuesInPlace(nested, path, double); err == nil {
+			t.Errorf("[%q]: expected error", path)
+		}
+	}
+	if !reflect.DeepEqual(nested["l"], []interface{}{[]interface{}{1.}}) {
+		t.Errorf("modified through a quantifier: %v", nested)
+	}
+	if v := plain.inPlace(nested, []interface{}{KEYS}); !isError(v) {
+		t.Errorf("KEYS: expected error, got %v", v)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	sum := func(acc, v interface{}) interface{} { return acc.(float64) + v.(float64) }
+	for _, tc := range []struct {
+		path   string
+		expect interface{}
+	}{
+		{"subobj/subarray/*", 6.},
+		{"array/*/*", 6.},
+		{"array/*/foo", 1.},
+		{"subobj/subsubobj/bar", 2.},
+		{"nosuchkey/*", 0.},
+		{"foo/*", 0.},
+	} {
+		if v := Reduce(testObj, tc.path, 0., sum); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("[%q]:  expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+
+	collect := func(acc, v interface{}) interface{} { return append(acc.([]interface{}), v) }
+	expect := []interface{}{[]interface{}{"hello", "world"}, 2., 3.} // sorted by key
+	if v := Reduce(testObj, "subobj/subsubobj/*", []interface{}{}, collect); !reflect.DeepEqual(v, expect) {
+		t.Errorf("[%q]:  expected %v, got %v", "subobj/subsubobj/*", expect, v)
+	}
+}
+
+func TestPluck(t *testing.T) {
+	if v := Pluck(testObj, "array", "foo"); !reflect.DeepEqual(v, []interface{}{1., nil, nil}) {
+		t.Errorf("Pluck: got %v", v)
+	}
+	if v := Pluck(testObj, "array", "foo/x"); !reflect.DeepEqual(v, []interface{}{nil, nil, nil}) {
+		t.Errorf("Pluck errors: got %v", v)
+	}
+	if v := PluckStrings(testStruct, "subobj/subsubobj/array", ""); !reflect.DeepEqual(v, []string{"hello", "world"}) {
+		t.Errorf("PluckStrings: got %v", v)
+	}
+	if v := PluckInts(testStruct, "array", "baz"); !reflect.DeepEqual(v, []int{0, 0, 3}) {
+		t.Errorf("PluckInts: got %v", v)
+	}
+	if v := PluckInts(testObj, "nosuchkey", "baz"); v != nil {
+		t.Errorf("PluckInts missing: got %v", v)
+	}
+}
+
+func TestContains(t *testing.T) {
+	for _, tc := range []struct {
+		path   string
+		value  interface{}
+		expect bool
+	}{
+		{"subobj/subarray", 2, true},
+		{"subobj/subarray", 2.0, true},
+		{"subobj/subarray", 4, false},
+		{"subobj/subarray", "2", false},
+		{"array", map[string]interface{}{"bar": 2}, true},
+		{"array", map[string]interface{}{"bar": 3}, false},
+		{"subobj/subsubobj", []string{"hello", "world"}, true},
+		{"test", "world", true},
+		{"test", "World", false},
+		{"test", 1, false},
+		{"foo", 1, false},
+		{"nosuchkey", nil, false},
+	} {
+		if v := Contains(testObj, tc.path, tc.value); v != tc.expect {
+			t.Errorf("[%q] %v: expected %v, got %v", tc.path, tc.value, tc.expect, v)
+		}
+	}
+}
+
+func TestCountWhere(t *testing.T) {
+	isFloat := func(v interface{}) bool { _, ok := v.(float64); return ok }
+	for _, tc := range []struct {
+		path   string
+		pred   func(interface{}) bool
+		expect int
+	}{
+		{"subobj/subarray", func(v interface{}) bool { return v.(float64) > 1 }, 2},
+		{"subobj/subarray/*", func(v interface{}) bool { return v.(float64) > 1 }, 2},
+		{"array/*/foo", isFloat, 1},
+		{"array", func(v interface{}) bool { return Q(v, "bar") != nil }, 1},
+		{"subobj/subsubobj", isFloat, 2},
+		{"array/*/foo/x", isFloat, 0},
+		{"nosuchkey", isFloat, 0},
+	} {
+		if v := CountWhere(testObj, tc.path, tc.pred); v != tc.expect {
+			t.Errorf("[%q]:  expected %v, got %v", tc.path, tc.expect, v)
+		}
+		if v := len(Filter(testObj, tc.path, tc.pred)); v != tc.expect {
+			t.Errorf("[%q]:  Filter returns %v elements, expected %v", tc.path, v, tc.expect)
+		}
+	}
+
+	// where CountWhere visits values that Filter does not consider
+	root := map[string]interface{}{
+		"m":      map[string]interface{}{"a": 1, "b": nil},
+		"nested": []interface{}{[]interface{}{1, 2}, []interface{}{3}},
+	}
+	isNil := func(v interface{}) bool { return v == nil }
+	if v := CountWhere(root, "m/*", isNil); v != 1 {
+		t.Errorf("CountWhere of a null map value: expected 1, got %v", v)
+	}
+	if v := len(Filter(root, "m/*", isNil)); v != 0 {
+		t.Errorf("Filter of a null map value: expected 0, got %v", v)
+	}
+	any := func(interface{}) bool { return true }
+	if v := CountWhere(root, "nested/*/*", any); v != 3 {
+		t.Errorf("CountWhere of nested ALLs: expected 3, got %v", v)
+	}
+	if v := len(Filter(root, "nested/*/*", any)); v != 2 {
+		t.Errorf("Filter of nested ALLs: expected 2, got %v", v)
+	}
+}
+
+func TestEach(t *testing.T) {
+	var kk, vv []interface{}
+	Each(testObj, "subobj/subarray", func(k, v interface{}) bool {
+		kk, vv = append(kk, k), append(vv, v)
+		return true
+	})
+	if !reflect.DeepEqual(kk, []interface{}{0, 1, 2}) || !reflect.DeepEqual(vv, []interface{}{1.0, 2.0, 3.0}) {
+		t.Errorf("Each: got keys %v, values %v", kk, vv)
+	}
+
+	var first interface{}
+	calls := 0
+	Each(testObj, "subobj/subarray/*", func(k, v interface{}) bool {
+		calls++
+		if v.(float64) > 1 {
+			first = k
+			return false
+		}
+		return true
+	})
+	if first != 1 || calls != 2 {
+		t.Errorf("Each stopping early: got key %v after %d calls", first, calls)
+	}
+
+	kk = nil
+	Each(testObj, "array/*/foo", func(k, v interface{}) bool {
+		kk = append(kk, k)
+		return true
+	})
+	if !reflect.DeepEqual(kk, []interface{}{"foo", "foo", "foo"}) {
+		t.Errorf("Each over a field of all elements: got keys %v", kk)
+	}
+
+	Each(testObj, "nosuchkey/x", func(k, v interface{}) bool {
+		t.Errorf("Each over an error: called with %v %v", k, v)
+		return true
+	})
+}
+
+func TestCompact(t *testing.T) {
+	for _, tc := range []struct {
+		root   interface{}
+		path   string
+		expect interface{}
+	}{
+		{testObj, "array/*/foo", []interface{}{1.}},
+		{[]interface{}{nil, 1, (*int)(nil), "x"}, "", []interface{}{1, "x"}},
+		{map[string]interface{}{"a": nil, "b": 0}, "", map[string]interface{}{"b": 0}},
+		{map[int]*int{1: nil}, "", map[int]interface{}{}},
+		{testObj, "foo", ee},
+		{testObj, "foo/x", ee},
+	} {
+		v := Compact(tc.root, tc.path)
+		if _, ok := tc.expect.(error); ok {
+			if _, ok := v.(error); !ok {
+				t.Errorf("%v [%q]: expected error, got %v (%T) ", tc.root, tc.path, v, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%v [%q]:  expected %v, got %v (%T)", tc.root, tc.path, tc.expect, v, v)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// number returns v as a float64 if it is a number of any Go numeric kind or a json.Number.
+func number(v interface{}) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// exactNumber returns v, which must be a number, as an exact rational, or nil if it has no
+// exact value, as for NaN and infinities.
+func exactNumber(v interface{}) *big.Rat {
+	if n, ok := v.(json.Number); ok {
+		r, ok := new(big.Rat).SetString(string(n))
+		if !ok {
+			return nil
+		}
+		return r
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(rv.Int())
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(rv.Uint()))
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return new(big.Rat).SetFloat64(f)
+		}
+	}
+	return nil
+}
+
+// maxExactFloat is the magnitude from which float64 cannot represent all integers, so that
+// distinct integers such as int64 IDs convert to the same float64.
+const maxExactFloat = 1 << 53
+
+// The ranks returned by order.
+const (
+	orderNull = iota
+	orderFalse
+	orderTrue
+	orderNumber
+	orderString
+	orderArray
+	orderObject
+	orderTime
+	orderOther
+)
+
+// order ranks values like jq does: null, false, true, numbers, strings, arrays, objects,
+// followed by times and then other values jq does not know.
+func order(v interface{}) int {
+	if v == nil {
+		return orderNull
+	}
+	if b, ok := v.(bool); ok {
+		if b {
+			return orderTrue
+		}
+		return orderFalse
+	}
+	if _, ok := number(v); ok {
+		return orderNumber
+	}
+	switch v.(type) {
+	case OrderedMap:
+		return orderObject
+	case time.Time:
+		return orderTime
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String:
+		return orderString
+	case reflect.Array, reflect.Slice:
+		return orderArray
+	case reflect.Map, reflect.Struct:
+		return orderObject
+	case reflect.Ptr, reflect.Interface:
+		if reflect.ValueOf(v).IsNil() {
+			return orderNull
+		}
+	}
+	return orderOther
+}
+
+// compare returns -1, 0 or 1 as a sorts before, equal to or after b.
+// Values of different kinds are ordered like jq orders them, numbers of
+// any type compare by value, exactly even beyond the precision of float64,
+// arrays compare element-wise, objects by
+// their sorted keys and then their values, and times by the instant they stand for.
+func compare(a, b interface{}) int {
+	oa, ob := order(a), order(b)
+	if oa != ob {
+		if oa < ob {
+			return -1
+		}
+		return 1
+	}
+	switch oa {
+	case orderNull, orderFalse, orderTrue:
+		return 0
+	case orderNumber:
+		fa, _ := number(a)
+		fb, _ := number(b)
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		case math.IsNaN(fa) || math.IsNaN(fb):
+			return compareNaN(math.IsNaN(fa), math.IsNaN(fb))
+		case math.Abs(fa) >= maxExactFloat:
+			// equal as float64, but possibly distinct integers
+			if ra, rb := exactNumber(a), exactNumber(b); ra != nil && rb != nil {
+				return ra.Cmp(rb)
+			}
+		}
+		return 0
+	case orderString:
+		return strings.Compare(reflect.ValueOf(a).String(), reflect.ValueOf(b).String())
+	case orderArray:
+		va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+		for i := 0; i < va.Len() && i < vb.Len(); i++ {
+			if c := compare(va.Index(i).Interface(), vb.Index(i).Interface()); c != 0 {
+				return c
+			}
+		}
+		return compareInt(va.Len(), vb.Len())
+	case orderObject:
+		ka, _ := keys(a).([]interface{})
+		kb, _ := keys(b).([]interface{})
+		if c := compare(ka, kb); c != 0 {
+			return c
+		}
+		for _, k := range ka {
+			if c := compare(Q(a, k), Q(b, k)); c != 0 {
+				return c
+			}
+		}
+		return 0
+	case orderTime:
+		ta, tb := a.(time.Time), b.(time.Time)
+		switch {
+		case ta.Before(tb):
+			return -1
+		case ta.After(tb):
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareNaN sorts NaN before all other numbers.
+func compareNaN(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	}
+	return 1
+}
+
+// canonical returns a string that is equal for two values exactly when compare reports them equal,
+// for use as a map key when deduplicating or grouping values.
+func canonical(v interface{}) string {
+	var b strings.Builder
+	writeCanonical(&b, v)
+	return b.String()
+}
+
+// canonicalWriter is implemented by *strings.Builder and *bufio.Writer.
+type canonicalWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+func writeCanonical(b canonicalWriter, v interface{}) {
+	switch order(v) {
+	case orderNull:
+		b.WriteString("null")
+	case orderFalse:
+		b.WriteString("false")
+	case orderTrue:
+		b.WriteString("true")
+	case orderNumber:
+		f, _ := number(v)
+		if r := exactNumber(v); r != nil && math.Abs(f) >= maxExactFloat {
+			b.WriteString(r.RatString()) // compared exactly, see compare
+		} else {
+			b.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case orderString:
+		b.WriteString(strconv.Quote(reflect.ValueOf(v).String()))
+	case orderArray:
+		rv := reflect.ValueOf(v)
+		b.WriteByte('[')
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCanonical(b, rv.Index(i).Interface())
+		}
+		b.WriteByte(']')
+	case orderObject:
+		kk, _ := keys(v).([]interface{})
+		b.WriteByte('{')
+		for i, k := range kk {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			writeCanonical(b, k)
+			b.WriteByte(':')
+			writeCanonical(b, Q(v, k))
+		}
+		b.WriteByte('}')
+	case orderTime:
+		b.WriteString("time(")
+		b.WriteString(v.(time.Time).UTC().Format(time.RFC3339Nano))
+		b.WriteByte(')')
+	default:
+		fmt.Fprintf(b, "%T(%v)", v, v)
+	}
+}
+
+// Equal reports whether a and b are deeply equal, treating numbers of any type
+// as equal if they have the same value, so that 1, 1.0 and json.Number("1") are all equal.
+func Equal(a, b interface{}) bool {
+	return compare(a, b) == 0
+}
+
+// EqualAt reports whether QQ(a, path) and QQ(b, path) are equal according to Equal.
+// Two missing values are equal, but errors are never equal to anything.
+func EqualAt(a, b interface{}, path string) bool {
+	va, vb := QQ(a, path), QQ(b, path)
+	if _, ok := va.(error); ok {
+		return false
+	}
+	if _, ok := vb.(error); ok {
+		return false
+	}
+	return Equal(va, vb)
+}
+
+// EqualValueAt reports whether QQ(root, path) equals value according to Equal.
+func EqualValueAt(root interface{}, path string, value interface{}) bool {
+	v := QQ(root, path)
+	if _, ok := v.(error); ok {
+		return false
+	}
+	return Equal(v, value)
+}
@@ -0,0 +1,90 @@
+package jq
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	t2020 = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2024 = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestEqual(t *testing.T) {
+	for _, tc := range []struct {
+		a, b   interface{}
+		expect bool
+	}{
+		{1, 1.0, true},
+		{json.Number("1"), uint8(1), true},
+		{json.Number("1.5"), 1.5, true},
+		{1, "1", false},
+		{nil, nil, true},
+		{nil, false, false},
+		{[]int{1, 2}, []interface{}{1., json.Number("2")}, true},
+		{[]int{1, 2}, []int{2, 1}, false},
+		{map[string]int{"a": 1}, map[string]interface{}{"a": 1.}, true},
+		{map[string]int{"a": 1}, map[string]interface{}{"a": 1., "b": nil}, false},
+		{struct{ A int }{1}, map[string]interface{}{"A": 1}, true},
+		{int64(9007199254740993), int64(9007199254740992), false},
+		{int64(9007199254740993), json.Number("9007199254740993"), true},
+		{uint64(1 << 63), float64(1 << 63), true},
+		{json.Number("9007199254740993"), float64(9007199254740992), false},
+		{t2020, t2024, false},
+		{t2020, t2020.In(time.FixedZone("X", 3600)), true},
+		{t2020, "2020-01-01T00:00:00Z", false},
+		{map[string]interface{}{"at": t2020}, map[string]interface{}{"at": t2024}, false},
+	} {
+		if v := Equal(tc.a, tc.b); v != tc.expect {
+			t.Errorf("Equal(%#v, %#v): expected %v, got %v", tc.a, tc.b, tc.expect, v)
+		}
+	}
+}
+
+func TestCompareTimesAndIntegers(t *testing.T) {
+	root := map[string]interface{}{
+		"times": []interface{}{t2024, t2020, t2024.Local(), t2020.Add(time.Nanosecond)},
+		"ids":   []interface{}{int64(9007199254740993), int64(9007199254740992), json.Number("9007199254740993")},
+	}
+	if v := Unique(root, "times"); !reflect.DeepEqual(v, []interface{}{t2024, t2020, t2020.Add(time.Nanosecond)}) {
+		t.Errorf("Unique times: got %v", v)
+	}
+	if v := Unique(root, "ids"); len(v) != 2 || v[1] != int64(9007199254740992) {
+		t.Errorf("Unique ids: got %v", v)
+	}
+	events := []interface{}{
+		map[string]interface{}{"id": 1, "at": t2024},
+		map[string]interface{}{"id": 2, "at": t2020},
+	}
+	if v := Pluck(SortBy(events, "", "at"), "", "id"); !reflect.DeepEqual(v, []interface{}{2, 1}) {
+		t.Errorf("SortBy times: got %v", v)
+	}
+}
+
+func TestEqualAt(t *testing.T) {
+	for _, tc := range []struct {
+		path   string
+		expect bool
+	}{
+		{"foo", true},
+		{"array/0/foo", true},
+		{"subobj/subsubobj/array", true},
+		{"test", true},
+		{"array/0/bar", false}, // testStruct has 0, testObj nothing
+		{"nosuchkey", true},
+		{"foo/x", false},
+	} {
+		if v := EqualAt(testObj, testStruct, tc.path); v != tc.expect {
+			t.Errorf("EqualAt [%q]: expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+
+	if !EqualValueAt(testObj, "subobj/subarray", []int{1, 2, 3}) {
+		t.Errorf("EqualValueAt: expected true")
+	}
+	if EqualValueAt(testObj, "foo/x", nil) {
+		t.Errorf("EqualValueAt error: expected false")
+	}
+}
@@ -0,0 +1,72 @@
+package jq
+
+import "context"
+
+// QCtx is like Q, but stops and returns the error of ctx if ctx is cancelled or its deadline
+// passes while the query runs. The context is checked before every step, so long traversals
+// with ALL over large documents end soon after cancellation. It is also passed to context
+// hooks and context providers, which can stop their own work with it.
+func QCtx(ctx context.Context, root interface{}, index ...interface{}) interface{} {
+	return plain.withContext(ctx).query(root, index)
+}
+
+// QCtx is like the package-level QCtx, with the options of e.
+func (e *Engine) QCtx(ctx context.Context, root interface{}, index ...interface{}) interface{} {
+	return e.c.withContext(ctx).query(root, index)
+}
+
+// QQCtx is like the package-level QQ, with the options of e, but stops like QCtx when ctx is done.
+func (e *Engine) QQCtx(ctx context.Context, root interface{}, path string) interface{} {
+	index, err := e.parsePath(path)
+	if err != nil {
+		return err
+	}
+	return e.c.withContext(ctx).query(root, index)
+}
+
+// Context returns a copy of r whose query observes ctx like QCtx. The context is also passed
+// to context hooks, context providers and the converters used by the typed getters.
+func (r Result) Context(ctx context.Context) Result {
+	r.c = r.config().withContext(ctx)
+	return r
+}
+
+// withContext returns a copy of c that observes ctx.
+func (c *config) withContext(ctx context.Context) *config {
+	cc := *c
+	cc.ctx = ctx
+	return &cc
+}
+
+// context returns the context of c, or context.Background if it has none.
+func (c *config) context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// QChan sends the values that QCtx(ctx, root, index...) would return on the returned channel
+// as they are found, one per value selected by ALL, and closes it when the traversal ends.
+// Errors are sent as values. Ctx is checked before every step, as by QCtx, and if it is done,
+// QChan stops traversing and closes the channel without sending the remaining values;
+// the receiver can tell by checking ctx.Err.
+// The receiver must drain the channel or cancel ctx so the traversal can finish.
+func QChan(ctx context.Context, root interface{}, index ...interface{}) <-chan interface{} {
+	ch := make(chan interface{})
+	go func() {
+		defer close(ch)
+		plain.withContext(ctx).stream(root, index, nil, func(_ []interface{}, v interface{}) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			select {
+			case ch <- v:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+	return ch
+}
@@ -0,0 +1,160 @@
+package jq
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestQCtx(t *testing.T) {
+	if v := QCtx(context.Background(), testObj, "subobj", "subsubobj", "array", ALL); !reflect.DeepEqual(v, []interface{}{"hello", "world"}) {
+		t.Errorf("QCtx: got %v", v)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if v := QCtx(ctx, testObj, "subobj", "foo"); v != context.Canceled {
+		t.Errorf("QCtx cancelled: expected %v, got %v", context.Canceled, v)
+	}
+
+	// a document large enough that traversing it takes longer than the deadline
+	big := make([]interface{}, 1000)
+	for i := range big {
+		row := make(map[string]interface{})
+		for j := 0; j < 100; j++ {
+			row[string(rune('a'+j%26))+string(rune('a'+j/26))] = []interface{}{i, j}
+		}
+		big[i] = row
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	var v interface{}
+	for time.Since(start) < time.Second {
+		if v = QCtx(ctx, big, ALL, ALL, ALL); v == context.DeadlineExceeded {
+			break
+		}
+	}
+	if err, _ := v.(error); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("QCtx deadline: expected %v, got %T", context.DeadlineExceeded, v)
+	}
+
+	e := NewEngine(CaseInsensitive())
+	if v := e.QQCtx(context.Background(), testObj, "SUBOBJ/FOO"); v != 1. {
+		t.Errorf("Engine.QQCtx: got %v", v)
+	}
+	if v := e.QCtx(ctx, testObj, "subobj"); v != context.DeadlineExceeded {
+		t.Errorf("Engine.QCtx: expected %v, got %v", context.DeadlineExceeded, v)
+	}
+}
+
+func TestQChan(t *testing.T) {
+	var got []interface{}
+	for v := range QChan(context.Background(), testObj, "subobj", "subsubobj", "array", ALL) {
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(got, []interface{}{"hello", "world"}) {
+		t.Errorf("QChan: got %v", got)
+	}
+
+	big := make([]interface{}, 1000)
+	for i := range big {
+		big[i] = i
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := QChan(ctx, big, ALL)
+	if v := <-ch; v != 0 {
+		t.Errorf("QChan: expected 0 first, got %v", v)
+	}
+	cancel()
+	n := 0
+	for range ch {
+		n++
+	}
+	if n > 1 {
+		t.Errorf("QChan cancelled: expected at most 1 more value, got %d", n)
+	}
+}
+
+type ctxKey struct{}
+
+type ctxProvider struct{ loads, ctxLoads int }
+
+func (p *ctxProvider) Load() (interface{}, error) {
+	p.loads++
+	return map[string]interface{}{"a": 1}, nil
+}
+
+func (p *ctxProvider) LoadContext(ctx context.Context) (interface{}, error) {
+	p.ctxLoads++
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"a": ctx.Value(ctxKey{})}, nil
+}
+
+type ctxHook struct {
+	HookFuncs
+	seen *[]interface{}
+}
+
+func (h ctxHook) WithContext(ctx context.Context) Hook {
+	v := ctx.Value(ctxKey{})
+	return HookFuncs{AfterFunc: func(_ []interface{}, value interface{}) interface{} {
+		*h.seen = append(*h.seen, v)
+		return value
+	}}
+}
+
+func TestContextPropagation(t *testing.T) {
+	ctx := context.WithValue(context.Background(), ctxKey{}, "v")
+
+	p := new(ctxProvider)
+	if v := Q(p, "a"); v != 1 {
+		t.Errorf("Q provider: got %v", v)
+	}
+	if v := QCtx(ctx, p, "a"); v != "v" {
+		t.Errorf("QCtx provider: got %v", v)
+	}
+	if p.loads != 1 || p.ctxLoads != 1 {
+		t.Errorf("provider: expected 1 Load and 1 LoadContext, got %d and %d", p.loads, p.ctxLoads)
+	}
+	for v := range QChan(ctx, map[string]interface{}{"p": p}, "p", "a") {
+		if v != "v" {
+			t.Errorf("QChan provider: got %v", v)
+		}
+	}
+
+	var seen []interface{}
+	e := NewEngine(WithHook(ctxHook{seen: &seen}))
+	e.Q(testObj, "subobj")
+	if len(seen) != 0 {
+		t.Errorf("hook without context: expected it unbound, got %v", seen)
+	}
+	e.QCtx(ctx, testObj, "subobj")
+	if len(seen) != 2 || seen[0] != "v" {
+		t.Errorf("hook with context: got %v", seen)
+	}
+
+	e = NewEngine(WithContextConverter(func(ctx context.Context, v interface{}) (int, error) {
+		if s, ok := ctx.Value(ctxKey{}).(string); ok {
+			return len(s), nil
+		}
+		return 0, errors.New("no value")
+	}))
+	root := map[string]interface{}{"x": "abc"}
+	if n := e.New(root).Path("x").Int(); n != 0 {
+		t.Errorf("converter without context: got %d", n)
+	}
+	if n := e.New(root).Context(ctx).Path("x").Int(); n != 1 {
+		t.Errorf("converter with context: got %d", n)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if v := e.New(root).Context(cancelled).Path("x").Value(); v != context.Canceled {
+		t.Errorf("Result.Context cancelled: expected %v, got %v", context.Canceled, v)
+	}
+}
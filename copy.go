@@ -0,0 +1,82 @@
+package jq
+
+import "reflect"
+
+// DeepCopyAt returns a deep copy of the value selected by QQ(root, path), so that it
+// can be modified without affecting root. Maps, slices, arrays, pointers and the exported
+// fields of structs are copied recursively; unexported struct fields are copied shallowly.
+// Errors from the query are returned as the result, as with Q.
+// The value must not contain cycles.
+func DeepCopyAt(root interface{}, path string) interface{} {
+	v := QQ(root, path)
+	if _, ok := v.(error); ok {
+		return v
+	}
+	return DeepCopy(v)
+}
+
+// DeepCopy returns a deep copy of v, as described for DeepCopyAt.
+func DeepCopy(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(v)).Interface()
+}
+
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			m.SetMapIndex(k, deepCopy(v.MapIndex(k)))
+		}
+		return m
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return s
+
+	case reflect.Array:
+		a := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			a.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return a
+
+	case reflect.Struct:
+		s := reflect.New(v.Type()).Elem()
+		s.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				s.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return s
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(deepCopy(v.Elem()))
+		return p
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	}
+	return v
+}
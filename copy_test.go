@@ -0,0 +1,39 @@
+package jq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopyAt(t *testing.T) {
+	c := DeepCopyAt(testObj, "subobj")
+	if !reflect.DeepEqual(c, QQ(testObj, "subobj")) {
+		t.Fatalf("copy differs: %v", c)
+	}
+	c.(map[string]interface{})["foo"] = 42.
+	c.(map[string]interface{})["subarray"].([]interface{})[0] = 42.
+	c.(map[string]interface{})["subsubobj"].(map[string]interface{})["bar"] = 42.
+	if QQ(testObj, "subobj/foo") != 1. || QQ(testObj, "subobj/subarray/0") != 1. || QQ(testObj, "subobj/subsubobj/bar") != 2. {
+		t.Errorf("original modified: %v", QQ(testObj, "subobj"))
+	}
+
+	type inner struct{ N []int }
+	type outer struct {
+		P *inner
+		A [1][]int
+		I interface{}
+	}
+	o := outer{P: &inner{N: []int{1}}, A: [1][]int{{2}}, I: map[string]int{"x": 3}}
+	oc := DeepCopyAt(o, "").(outer)
+	oc.P.N[0], oc.A[0][0], oc.I.(map[string]int)["x"] = 0, 0, 0
+	if o.P.N[0] != 1 || o.A[0][0] != 2 || o.I.(map[string]int)["x"] != 3 {
+		t.Errorf("original modified: %+v", o)
+	}
+
+	if v := DeepCopyAt(testObj, "nosuchkey"); v != nil {
+		t.Errorf("missing: expected nil, got %v", v)
+	}
+	if _, ok := DeepCopyAt(testObj, "foo/x").(error); !ok {
+		t.Errorf("expected error")
+	}
+}
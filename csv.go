@@ -0,0 +1,68 @@
+package jq
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// WriteCSV writes the table selected by rowsPath and columns, as returned by Table, to w
+// in CSV format, preceded by a header row holding the column paths:
+//
+//	err := jq.WriteCSV(os.Stdout, orders, "items", "sku", "price/amount", "price/currency")
+//
+// Strings are written as they are, numbers in their shortest form without an exponent
+// where possible, times in RFC 3339 format, objects and arrays as JSON, and missing
+// values as empty fields.
+func WriteCSV(w io.Writer, root interface{}, rowsPath string, columns ...string) error {
+	rows, err := Table(root, rowsPath, columns...)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, v := range row {
+			if record[i], err = csvField(v); err != nil {
+				return err
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvField formats v for a field of WriteCSV.
+func csvField(v interface{}) (string, error) {
+	switch vv := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return vv, nil
+	case []byte:
+		return string(vv), nil
+	case json.Number:
+		return vv.String(), nil
+	case bool:
+		return strconv.FormatBool(vv), nil
+	case time.Time:
+		return vv.Format(time.RFC3339Nano), nil
+	}
+	if _, ok := number(v); ok {
+		return numberString(v), nil
+	}
+	if order(v) == orderString {
+		return reflect.ValueOf(v).String(), nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
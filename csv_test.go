@@ -0,0 +1,40 @@
+package jq
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	type level string
+	orders := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"sku": "a-1", "price": map[string]interface{}{"amount": 9.5, "currency": "EUR"}, "tags": []interface{}{"x"}},
+			map[string]interface{}{"sku": "b,2", "price": map[string]interface{}{"amount": json.Number("12"), "currency": "USD"}, "ok": true},
+			map[string]interface{}{"sku": `c"3`, "price": map[string]interface{}{"amount": 100000000.0}, "level": level("high")},
+			map[string]interface{}{"sku": "d", "at": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		},
+	}
+	var b bytes.Buffer
+	if err := WriteCSV(&b, orders, "items", "sku", "price/amount", "price/currency", "tags", "ok", "level", "at"); err != nil {
+		t.Fatal(err)
+	}
+	expect := strings.Join([]string{
+		"sku,price/amount,price/currency,tags,ok,level,at",
+		`a-1,9.5,EUR,"[""x""]",,,`,
+		`"b,2",12,USD,,true,,`,
+		`"c""3",100000000,,,,high,`,
+		"d,,,,,,2024-01-02T03:04:05Z",
+		"",
+	}, "\n")
+	if b.String() != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, b.String())
+	}
+
+	if err := WriteCSV(&b, orders, "items/0/sku", "x"); err == nil {
+		t.Errorf("expected error for rows that are not a container")
+	}
+}
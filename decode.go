@@ -0,0 +1,415 @@
+package jq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned, possibly wrapped, by functions that require a path to resolve
+// to a present value when it does not.
+var ErrNotFound = errors.New("path not found")
+
+// ErrType is returned, wrapped, by functions that convert a value to a Go type when the value
+// cannot be converted to it.
+var ErrType = errors.New("cannot decode")
+
+// resolve returns the value at path, or an error if the query fails or the value is not present.
+// A present nil value is returned without error.
+func resolve(root interface{}, path string) (interface{}, error) {
+	index, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := lookup(root, index)
+	if ok {
+		return v, nil
+	}
+	if err, isErr := QQ(root, path).(error); isErr {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return nil, fmt.Errorf("%s: %w", path, ErrNotFound)
+}
+
+// UnmarshalPath resolves path in root and stores the value in dst, which must be a non-nil pointer,
+// as if the value had been encoded with json.Marshal and decoded with json.Unmarshal, so json struct
+// tags on dst are honored. It returns an error wrapping ErrNotFound if the value is not present.
+// A nil value, such as a JSON null, leaves dst unmodified like json.Unmarshal does.
+func UnmarshalPath(root interface{}, path string, dst interface{}) error {
+	v, err := resolve(root, path)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if err := json.Unmarshal(b, dst); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// DecodePath resolves path in root and stores the value in dst, which must be a non-nil pointer,
+// converting between scalar types where the payload is sloppy about them:
+//
+//   - strings, booleans and numbers of any type convert to each other, so "42", 42.0 and
+//     json.Number("42") all fill an int, and "true", "1" and 1 all fill a bool;
+//   - floats only fill integers if they have no fractional part and fit;
+//   - strings in the formats accepted by Time and numbers of seconds since the Unix epoch fill a time.Time,
+//     and strings like "1m30s" as well as numbers of nanoseconds fill a time.Duration;
+//   - maps fill structs, matching the json tag or, ignoring case, the name of each field;
+//   - a single value fills a slice of length one.
+//
+// It returns an error wrapping ErrNotFound if the value is not present.
+// Nil values, such as JSON nulls, leave the destination unmodified.
+func DecodePath(root interface{}, path string, dst interface{}) error {
+	v, err := resolve(root, path)
+	if err != nil {
+		return err
+	}
+	return decodeTo(path, v, dst)
+}
+
+// decodeTo stores v in the value pointed to by dst with weakDecode.
+func decodeTo(path string, v, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("%s: cannot decode into %T, need a non-nil pointer", path, dst)
+	}
+	return weakDecode(path, v, rv.Elem())
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// weakDecode stores src in dst, converting as described for DecodePath.
+// Path is used in error messages.
+func weakDecode(path string, src interface{}, dst reflect.Value) error {
+	if r, ok := src.(json.RawMessage); ok {
+		src = unwrap(r)
+	}
+	if order(src) == orderNull {
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) && dst.Kind() != reflect.Map && dst.Kind() != reflect.Slice {
+		dst.Set(sv)
+		return nil
+	}
+	fail := func() error {
+		return fmt.Errorf("%s: %w %v (type %T) into %s", path, ErrType, src, src, dst.Type())
+	}
+
+	switch dst.Type() {
+	case timeType:
+		t, ok := toTime(src)
+		if !ok {
+			return fail()
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		if s, ok := src.(string); ok {
+			d, err := time.ParseDuration(strings.TrimSpace(s))
+			if err != nil {
+				return fail()
+			}
+			dst.SetInt(int64(d))
+			return nil
+		}
+	}
+
+	switch dst.Kind() {
+	case reflect.Interface:
+		if !sv.Type().Implements(dst.Type()) {
+			return fail()
+		}
+		dst.Set(sv)
+
+	case reflect.Ptr:
+		p := reflect.New(dst.Type().Elem())
+		if err := weakDecode(path, src, p.Elem()); err != nil {
+			return err
+		}
+		dst.Set(p)
+
+	case reflect.String:
+		s, ok := toString(src)
+		if !ok {
+			return fail()
+		}
+		dst.SetString(s)
+
+	case reflect.Bool:
+		b, ok := toBool(src)
+		if !ok {
+			return fail()
+		}
+		dst.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f, ok := toNumber(src)
+		if !ok {
+			return fail()
+		}
+		if n, err := strconv.ParseInt(numberString(src), 10, 64); err == nil && !isFloat(src) {
+			// exact for integers beyond the precision of float64
+			if dst.OverflowInt(n) {
+				return fail()
+			}
+			dst.SetInt(n)
+			break
+		}
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || dst.OverflowInt(int64(f)) {
+			return fail()
+		}
+		dst.SetInt(int64(f))
+
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, ok := toNumber(src)
+		if !ok {
+			return fail()
+		}
+		if n, err := strconv.ParseUint(numberString(src), 10, 64); err == nil && !isFloat(src) {
+			if dst.OverflowUint(n) {
+				return fail()
+			}
+			dst.SetUint(n)
+			break
+		}
+		if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || dst.OverflowUint(uint64(f)) {
+			return fail()
+		}
+		dst.SetUint(uint64(f))
+
+	case reflect.Float32, reflect.Float64:
+		f, ok := toNumber(src)
+		if !ok {
+			return fail()
+		}
+		dst.SetFloat(f)
+
+	case reflect.Struct:
+		if order(src) != orderObject {
+			return fail()
+		}
+		return decodeStruct(path, src, dst)
+
+	case reflect.Map:
+		if order(src) != orderObject {
+			return fail()
+		}
+		m := reflect.MakeMap(dst.Type())
+		kk, _ := keys(src).([]interface{})
+		for _, k := range kk {
+			kv := reflect.New(dst.Type().Key()).Elem()
+			if err := weakDecode(path, k, kv); err != nil {
+				return err
+			}
+			ev := reflect.New(dst.Type().Elem()).Elem()
+			if err := weakDecode(fmt.Sprintf("%s/%v", path, k), Q(src, k), ev); err != nil {
+				return err
+			}
+			m.SetMapIndex(kv, ev)
+		}
+		dst.Set(m)
+
+	case reflect.Slice:
+		if s, ok := src.(string); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes([]byte(s))
+			return nil
+		}
+		a := []interface{}{src}
+		if order(src) == orderArray {
+			a = elements(src)
+		}
+		s := reflect.MakeSlice(dst.Type(), len(a), len(a))
+		for i, e := range a {
+			if err := weakDecode(fmt.Sprintf("%s/%d", path, i), e, s.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(s)
+
+	case reflect.Array:
+		if order(src) != orderArray {
+			return fail()
+		}
+		a := elements(src)
+		if len(a) > dst.Len() {
+			return fail()
+		}
+		for i, e := range a {
+			if err := weakDecode(fmt.Sprintf("%s/%d", path, i), e, dst.Index(i)); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fail()
+	}
+	return nil
+}
+
+// decodeStruct fills the exported fields of dst from the entries of src.
+func decodeStruct(path string, src interface{}, dst reflect.Value) error {
+	kk, _ := keys(src).([]interface{})
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("json"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if n := strings.Split(tag, ",")[0]; n != "" {
+				name = n
+			}
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get("json") == "" {
+			if err := decodeStruct(path, src, dst.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if k, ok := matchKey(kk, name); ok {
+			if err := weakDecode(path+"/"+k, Q(src, k), dst.Field(i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// matchKey returns the key in kk that is equal to name or, if there is none,
+// the first one equal to it under case folding, as encoding/json matches fields.
+func matchKey(kk []interface{}, name string) (string, bool) {
+	folded := ""
+	found := false
+	for _, k := range kk {
+		ks, ok := k.(string)
+		if !ok {
+			continue
+		}
+		if ks == name {
+			return ks, true
+		}
+		if !found && strings.EqualFold(ks, name) {
+			folded, found = ks, true
+		}
+	}
+	return folded, found
+}
+
+// toString converts scalars to strings.
+func toString(v interface{}) (string, bool) {
+	switch vv := v.(type) {
+	case string:
+		return vv, true
+	case []byte:
+		return string(vv), true
+	case json.Number:
+		return vv.String(), true
+	case bool:
+		return strconv.FormatBool(vv), true
+	}
+	if _, ok := number(v); ok {
+		return numberString(v), true
+	}
+	if reflect.ValueOf(v).Kind() == reflect.String {
+		return reflect.ValueOf(v).String(), true
+	}
+	return "", false
+}
+
+// isFloat reports whether v is a floating-point number, which numberString may round.
+func isFloat(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// numberString formats a number without loss of precision.
+func numberString(v interface{}) string {
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, rv.Type().Bits())
+	case reflect.String:
+		return strings.TrimSpace(rv.String())
+	}
+	return fmt.Sprint(v)
+}
+
+// toNumber converts numbers, numeric strings and booleans to float64.
+func toNumber(v interface{}) (float64, bool) {
+	if f, ok := number(v); ok {
+		return f, true
+	}
+	switch vv := v.(type) {
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(vv), 64)
+		return f, err == nil
+	case bool:
+		if vv {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// toBool converts booleans, strings accepted by strconv.ParseBool and numbers to bool.
+func toBool(v interface{}) (bool, bool) {
+	switch vv := v.(type) {
+	case bool:
+		return vv, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(vv))
+		return b, err == nil
+	}
+	if f, ok := number(v); ok {
+		return f != 0, true
+	}
+	return false, false
+}
+
+// toTime converts time.Time values, RFC 3339 and date strings and Unix seconds to time.Time.
+func toTime(v interface{}) (time.Time, bool) {
+	switch vv := v.(type) {
+	case time.Time:
+		return vv, true
+	case string:
+		s := strings.TrimSpace(vv)
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, true
+			}
+		}
+		if t := asTime(s, time.UTC, nil); !t.IsZero() {
+			return t, true
+		}
+		return time.Time{}, false
+	}
+	if f, ok := number(v); ok {
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), true
+	}
+	return time.Time{}, false
+}
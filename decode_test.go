@@ -0,0 +1,188 @@
+package jq
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalPath(t *testing.T) {
+	var sub struct {
+		Foo      int       `json:"foo"`
+		Values   []float64 `json:"subarray"`
+		Children struct {
+			Words []string `json:"array"`
+		} `json:"subsubobj"`
+	}
+	if err := UnmarshalPath(testObj, "subobj", &sub); err != nil {
+		t.Fatal(err)
+	}
+	if sub.Foo != 1 || !reflect.DeepEqual(sub.Values, []float64{1, 2, 3}) || !reflect.DeepEqual(sub.Children.Words, []string{"hello", "world"}) {
+		t.Errorf("UnmarshalPath: got %+v", sub)
+	}
+
+	var words []string
+	if err := UnmarshalPath(testStruct, "subobj/subsubobj/array", &words); err != nil || !reflect.DeepEqual(words, []string{"hello", "world"}) {
+		t.Errorf("UnmarshalPath slice: got %v, %v", words, err)
+	}
+
+	var m map[string]int
+	if err := UnmarshalPath(testObj, "array/1", &m); err != nil || m["bar"] != 2 {
+		t.Errorf("UnmarshalPath map: got %v, %v", m, err)
+	}
+
+	n := 5
+	if err := UnmarshalPath(map[string]interface{}{"n": nil}, "n", &n); err != nil || n != 5 {
+		t.Errorf("UnmarshalPath null: got %v, %v", n, err)
+	}
+	if err := UnmarshalPath(testObj, "nosuchkey", &n); !errors.Is(err, ErrNotFound) {
+		t.Errorf("UnmarshalPath missing: expected ErrNotFound, got %v", err)
+	}
+	if err := UnmarshalPath(testObj, "foo/x", &n); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("UnmarshalPath bad path: expected query error, got %v", err)
+	}
+	if err := UnmarshalPath(testObj, "test", &n); err == nil {
+		t.Errorf("UnmarshalPath type mismatch: expected error")
+	}
+}
+
+func TestDecodePath(t *testing.T) {
+	root := map[string]interface{}{
+		"port":     "8080",
+		"ratio":    json.Number("0.5"),
+		"count":    3.0,
+		"frac":     3.5,
+		"big":      json.Number("9007199254740993"),
+		"maxint":   json.Number("9223372036854775807"),
+		"maxuint":  json.Number("18446744073709551615"),
+		"over":     json.Number("9223372036854775808"),
+		"float63":  float64(1 << 63),
+		"neg":      -1,
+		"debug":    "true",
+		"on":       1,
+		"created":  "2024-03-01T10:00:00Z",
+		"day":      "2024-03-01",
+		"epoch":    1700000000,
+		"timeout":  "1m30s",
+		"tags":     "single",
+		"list":     []interface{}{"1", 2., json.Number("3")},
+		"headers":  map[string]interface{}{"X-Retry": "2"},
+		"server":   map[string]interface{}{"HOST": "db", "port": "5432", "opts": map[string]interface{}{"tls": "false"}},
+		"nothing":  nil,
+		"nonempty": []interface{}{map[string]interface{}{}},
+	}
+
+	var (
+		port    int
+		ratio   float32
+		count   uint8
+		big     int64
+		debug   bool
+		on      bool
+		name    string
+		created time.Time
+		day     time.Time
+		epoch   time.Time
+		timeout time.Duration
+		tags    []string
+		list    []int
+		headers map[string]int
+		server  struct {
+			Host string
+			Port int `json:"port"`
+			Opts *struct {
+				TLS bool `json:"tls"`
+			} `json:"opts"`
+		}
+		ptr *int
+	)
+	for _, tc := range []struct {
+		path string
+		dst  interface{}
+	}{
+		{"port", &port},
+		{"port", &ptr},
+		{"ratio", &ratio},
+		{"count", &count},
+		{"big", &big},
+		{"debug", &debug},
+		{"on", &on},
+		{"count", &name},
+		{"created", &created},
+		{"day", &day},
+		{"epoch", &epoch},
+		{"timeout", &timeout},
+		{"tags", &tags},
+		{"list", &list},
+		{"headers", &headers},
+		{"server", &server},
+		{"nothing", &port},
+	} {
+		if err := DecodePath(root, tc.path, tc.dst); err != nil {
+			t.Errorf("[%q]: unexpected error %v", tc.path, err)
+		}
+	}
+	if port != 8080 || *ptr != 8080 || ratio != 0.5 || count != 3 || big != 9007199254740993 || !debug || !on || name != "3" {
+		t.Errorf("scalars: %v %v %v %v %v %v %v %q", port, *ptr, ratio, count, big, debug, on, name)
+	}
+	if !created.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) || !day.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || epoch.Unix() != 1700000000 {
+		t.Errorf("times: %v %v %v", created, day, epoch)
+	}
+	if timeout != 90*time.Second {
+		t.Errorf("duration: %v", timeout)
+	}
+	if !reflect.DeepEqual(tags, []string{"single"}) || !reflect.DeepEqual(list, []int{1, 2, 3}) || headers["X-Retry"] != 2 {
+		t.Errorf("containers: %v %v %v", tags, list, headers)
+	}
+	if server.Host != "db" || server.Port != 5432 || server.Opts == nil || server.Opts.TLS {
+		t.Errorf("struct: %+v", server)
+	}
+
+	var ids struct {
+		ID   string `json:"id"`
+		Name string
+	}
+	both := map[string]interface{}{"ID": "upper", "id": "lower", "NAME": "folded"}
+	if err := DecodePath(both, "", &ids); err != nil || ids.ID != "lower" || ids.Name != "folded" {
+		t.Errorf("exact match first: got %+v, %v", ids, err)
+	}
+
+	var maxint int64
+	var maxuint, float63 uint64
+	if err := DecodePath(root, "maxint", &maxint); err != nil || maxint != math.MaxInt64 {
+		t.Errorf("maxint: got %v, %v", maxint, err)
+	}
+	if err := DecodePath(root, "maxuint", &maxuint); err != nil || maxuint != math.MaxUint64 {
+		t.Errorf("maxuint: got %v, %v", maxuint, err)
+	}
+	if err := DecodePath(root, "float63", &float63); err != nil || float63 != 1<<63 {
+		t.Errorf("float63: got %v, %v", float63, err)
+	}
+
+	var n int
+	var u uint
+	var b bool
+	for _, tc := range []struct {
+		path string
+		dst  interface{}
+	}{
+		{"frac", &n},
+		{"neg", &u},
+		{"created", &n},
+		{"created", &b},
+		{"big", &count},
+		{"over", &big},
+		{"float63", &big},
+		{"server", &n},
+		{"nosuchkey", &n},
+		{"port", n},
+		{"list", &server},
+	} {
+		if err := DecodePath(root, tc.path, tc.dst); err == nil {
+			t.Errorf("[%q] into %T: expected error", tc.path, tc.dst)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package jq
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// A Document holds a decoded JSON document that is modified by path, and notifies
+// subscribers of the changes, as needed for a small configuration store:
+//
+//	doc := jq.NewDocument(cfg)
+//	doc.Subscribe("server/port", func(old, new interface{}) { restart(new) })
+//	doc.Set("server/port", 8081)
+//
+// Modifications do not change the containers of the document in place: the maps and slices
+// along the modified path are copied, and the others are shared with the previous version.
+// Values returned by Root and the queries therefore stay valid and unchanged while the
+// document is modified, and must not be modified by the caller either.
+// Set, Delete and Append work on the containers produced by json.Unmarshal into an interface{},
+// map[string]interface{} and []interface{}; other values are replaced, not modified.
+//
+// A Document is safe for concurrent use.
+type Document struct {
+	mu   sync.RWMutex
+	root interface{}
+	subs []*subscription
+}
+
+// subscription is a callback registered with Subscribe.
+type subscription struct {
+	path []interface{}
+	fn   func(old, new interface{})
+}
+
+// NewDocument returns a Document holding root.
+func NewDocument(root interface{}) *Document {
+	return &Document{root: root}
+}
+
+// Root returns the current version of the document.
+func (d *Document) Root() interface{} {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.root
+}
+
+// Q is like the package-level Q, applied to the current version of the document.
+func (d *Document) Q(index ...interface{}) interface{} {
+	return Q(d.Root(), index...)
+}
+
+// QQ is like the package-level QQ, applied to the current version of the document.
+func (d *Document) QQ(path string) interface{} {
+	return QQ(d.Root(), path)
+}
+
+// Set stores v at the slash separated path, creating the objects along the path that do
+// not exist. An array element is selected by its index; the index one past the last element
+// appends to the array. The empty path replaces the whole document.
+func (d *Document) Set(path string, v interface{}) error {
+	return d.Commit(new(Txn).Set(path, v))
+}
+
+// Delete removes the value at the slash separated path: the key of an object, or the element
+// of an array, shifting the following elements. Deleting a value that is not present does nothing.
+func (d *Document) Delete(path string) error {
+	return d.Commit(new(Txn).Delete(path))
+}
+
+// Append appends vv to the array at the slash separated path, creating it if it is not present.
+func (d *Document) Append(path string, vv ...interface{}) error {
+	return d.Commit(new(Txn).Append(path, vv...))
+}
+
+// Subscribe registers fn to be called with the old and the new value at the slash separated path
+// whenever a modification of the document changes the value at or under path, including when
+// the value is created or deleted, in which case the missing value is nil. The values are
+// those returned by QQ, so a path with "*" watches all elements. Callbacks are called in the
+// order of subscription by the goroutine that made the change, after it has been applied.
+// The returned function cancels the subscription. Fn is never called for a malformed path,
+// which QQ rejects.
+func (d *Document) Subscribe(path string, fn func(old, new interface{})) (cancel func()) {
+	index, err := parsePath(path)
+	if err != nil {
+		return func() {}
+	}
+	s := &subscription{path: index, fn: fn}
+	d.mu.Lock()
+	d.subs = append(d.subs, s)
+	d.mu.Unlock()
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		for i, ss := range d.subs {
+			if ss == s {
+				d.subs = append(d.subs[:i:i], d.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// modify replaces the root of d with the result of fn, unless fn fails, and notifies
+// the subscribers of the changes.
+func (d *Document) modify(fn func(root interface{}) (interface{}, error)) error {
+	d.mu.Lock()
+	old := d.root
+	root, err := fn(old)
+	if err != nil {
+		d.mu.Unlock()
+		return err
+	}
+	d.root = root
+	subs := d.subs
+	d.mu.Unlock()
+
+	for _, s := range subs {
+		ov, nv := Q(old, s.path...), Q(root, s.path...)
+		if !reflect.DeepEqual(ov, nv) { // cheap for the shared, unmodified parts
+			s.fn(ov, nv)
+		}
+	}
+	return nil
+}
+
+// setPath returns a copy of root with v stored at index, copying the containers along index.
+func setPath(root interface{}, index []interface{}, v interface{}) (interface{}, error) {
+	return updatePath(root, index, func(interface{}) (interface{}, error) { return v, nil })
+}
+
+// updatePath returns a copy of root with the value at index, or nil if it is not present,
+// replaced by the result of fn, copying the containers along index.
+func updatePath(root interface{}, index []interface{}, fn func(interface{}) (interface{}, error)) (interface{}, error) {
+	if len(index) == 0 {
+		return fn(root)
+	}
+	key, ok := index[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot set %v", index[0])
+	}
+	switch r := root.(type) {
+	case nil:
+		n, err := updatePath(nil, index[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{key: n}, nil
+	case map[string]interface{}:
+		n, err := updatePath(r[key], index[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]interface{}, len(r)+1)
+		for k, e := range r {
+			m[k] = e
+		}
+		m[key] = n
+		return m, nil
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i > len(r) {
+			return nil, fmt.Errorf("invalid index %q for array of length %d", key, len(r))
+		}
+		var e interface{}
+		if i < len(r) {
+			e = r[i]
+		}
+		n, err := updatePath(e, index[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		a := make([]interface{}, len(r), len(r)+1)
+		copy(a, r)
+		if i == len(r) {
+			return append(a, n), nil
+		}
+		a[i] = n
+		return a, nil
+	}
+	return nil, fmt.Errorf("cannot set %q in type %T", key, root)
+}
+
+// appendPath returns a copy of root with vv appended to the array at index, which is
+// created if it is not present, copying the containers along index.
+func appendPath(root interface{}, index []interface{}, vv []interface{}) (interface{}, error) {
+	return updatePath(root, index, func(old interface{}) (interface{}, error) {
+		a, ok := old.([]interface{})
+		if !ok && old != nil {
+			return nil, fmt.Errorf("cannot append to type %T", old)
+		}
+		n := make([]interface{}, 0, len(a)+len(vv))
+		return append(append(n, a...), vv...), nil
+	})
+}
+
+// deletePath returns a copy of root without the value at index, copying the containers along index.
+func deletePath(root interface{}, index []interface{}) (interface{}, error) {
+	key, ok := index[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot delete %v", index[0])
+	}
+	switch r := root.(type) {
+	case map[string]interface{}:
+		e, ok := r[key]
+		if !ok {
+			return root, nil
+		}
+		m := make(map[string]interface{}, len(r))
+		for k, e := range r {
+			m[k] = e
+		}
+		if len(index) == 1 {
+			delete(m, key)
+			return m, nil
+		}
+		n, err := deletePath(e, index[1:])
+		if err != nil {
+			return nil, err
+		}
+		m[key] = n
+		return m, nil
+	case []interface{}:
+		i, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %q for array", key)
+		}
+		if i < 0 || i >= len(r) {
+			return root, nil
+		}
+		if len(index) == 1 {
+			a := make([]interface{}, 0, len(r)-1)
+			return append(append(a, r[:i]...), r[i+1:]...), nil
+		}
+		n, err := deletePath(r[i], index[1:])
+		if err != nil {
+			return nil, err
+		}
+		a := make([]interface{}, len(r))
+		copy(a, r)
+		a[i] = n
+		return a, nil
+	}
+	return root, nil
+}
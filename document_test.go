@@ -0,0 +1,99 @@
+package jq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDocumentSetDelete(t *testing.T) {
+	orig := map[string]interface{}{
+		"server": map[string]interface{}{"host": "localhost", "port": 8080},
+		"tags":   []interface{}{"a", "b", "c"},
+		"name":   "svc",
+	}
+	doc := NewDocument(orig)
+
+	for _, tc := range []struct {
+		op     string
+		path   string
+		value  interface{}
+		at     string
+		expect interface{}
+	}{
+		{"set", "server/port", 8081, "server", map[string]interface{}{"host": "localhost", "port": 8081}},
+		{"set", "limits/cpu/max", 2, "limits", map[string]interface{}{"cpu": map[string]interface{}{"max": 2}}},
+		{"set", "tags/1", "x", "tags", []interface{}{"a", "x", "c"}},
+		{"set", "tags/3", "d", "tags", []interface{}{"a", "x", "c", "d"}},
+		{"delete", "tags/0", nil, "tags", []interface{}{"x", "c", "d"}},
+		{"delete", "server/host", nil, "server", map[string]interface{}{"port": 8081}},
+		{"delete", "nosuchkey/x", nil, "nosuchkey", nil},
+		{"delete", "tags/9", nil, "tags", []interface{}{"x", "c", "d"}},
+	} {
+		var err error
+		if tc.op == "set" {
+			err = doc.Set(tc.path, tc.value)
+		} else {
+			err = doc.Delete(tc.path)
+		}
+		if err != nil {
+			t.Errorf("%s %q: %v", tc.op, tc.path, err)
+			continue
+		}
+		if v := doc.QQ(tc.at); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%s %q: expected %v at %q, got %v", tc.op, tc.path, tc.expect, tc.at, v)
+		}
+	}
+
+	if v := QQ(orig, "server/port"); v != 8080 {
+		t.Errorf("original modified: got %v", v)
+	}
+	if v := Q(orig, "tags"); !reflect.DeepEqual(v, []interface{}{"a", "b", "c"}) {
+		t.Errorf("original modified: got %v", v)
+	}
+
+	for _, path := range []string{"name/x", "tags/x", "tags/7", "tags/*", "a//b"} {
+		if err := doc.Set(path, 1); err == nil {
+			t.Errorf("Set %q: expected error", path)
+		}
+	}
+	if err := doc.Delete("tags/x"); err == nil {
+		t.Errorf("Delete of non-numeric index: expected error")
+	}
+
+	if err := doc.Set("", []interface{}{1}); err != nil || !reflect.DeepEqual(doc.Root(), []interface{}{1}) {
+		t.Errorf("Set of root: got %v, %v", doc.Root(), err)
+	}
+	if err := doc.Delete(""); err != nil || doc.Root() != nil {
+		t.Errorf("Delete of root: got %v, %v", doc.Root(), err)
+	}
+}
+
+func TestDocumentSubscribe(t *testing.T) {
+	doc := NewDocument(map[string]interface{}{
+		"server": map[string]interface{}{"host": "localhost", "port": 8080},
+		"debug":  false,
+	})
+	type change struct{ old, new interface{} }
+	var server, port, debug []change
+	doc.Subscribe("server", func(old, new interface{}) { server = append(server, change{old, new}) })
+	doc.Subscribe("server/port", func(old, new interface{}) { port = append(port, change{old, new}) })
+	cancel := doc.Subscribe("debug", func(old, new interface{}) { debug = append(debug, change{old, new}) })
+
+	doc.Set("server/port", 8081)
+	doc.Set("server/port", 8081) // unchanged
+	doc.Set("server/host", "example.com")
+	doc.Set("debug", true)
+	doc.Delete("server")
+	cancel()
+	doc.Set("debug", false)
+
+	if expect := []change{{8080, 8081}, {8081, nil}}; !reflect.DeepEqual(port, expect) {
+		t.Errorf("port: expected %v, got %v", expect, port)
+	}
+	if len(server) != 3 || server[2].new != nil || QQ(server[1].old, "host") != "localhost" || QQ(server[1].new, "host") != "example.com" {
+		t.Errorf("server: got %v", server)
+	}
+	if expect := []change{{false, true}}; !reflect.DeepEqual(debug, expect) {
+		t.Errorf("debug: expected %v, got %v", expect, debug)
+	}
+}
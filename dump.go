@@ -0,0 +1,177 @@
+package jq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fdump writes a stable, indented rendering of v to w, as returned by Sdump.
+func Fdump(w io.Writer, v interface{}) error {
+	_, err := io.WriteString(w, Sdump(v))
+	return err
+}
+
+// Dump writes a stable, indented rendering of v to standard output.
+func Dump(v interface{}) {
+	Fdump(os.Stdout, v)
+}
+
+// Sdump returns a stable, indented rendering of v suitable for logs and golden files.
+// The output resembles JSON: maps are sorted by key, struct fields are in declaration order,
+// OrderedMaps are in their own order, and errors, which may appear in the results of Q,
+// are rendered as <error: message>. A pointer, map or slice that refers back to a value
+// that contains it is rendered as <cycle>. The output ends in a newline.
+func Sdump(v interface{}) string {
+	var b bytes.Buffer
+	dump(&b, reflect.ValueOf(v), 0, make(map[visit]bool))
+	b.WriteByte('\n')
+	return b.String()
+}
+
+func dumpIndent(b *bytes.Buffer, depth int) {
+	b.WriteByte('\n')
+	b.WriteString(strings.Repeat("  ", depth))
+}
+
+// visit identifies a pointer, map or slice being rendered, to detect cycles.
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// dump renders v at depth; seen holds the references on the way to v.
+func dump(b *bytes.Buffer, v reflect.Value, depth int, seen map[visit]bool) {
+	if !v.IsValid() {
+		b.WriteString("null")
+		return
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		if !v.IsNil() {
+			k := visit{v.Pointer(), v.Type(), 0}
+			if v.Kind() == reflect.Slice {
+				k.len = v.Len()
+			}
+			if seen[k] {
+				b.WriteString("<cycle>")
+				return
+			}
+			seen[k] = true
+			defer delete(seen, k)
+		}
+	}
+
+	switch x := v.Interface().(type) {
+	case error:
+		fmt.Fprintf(b, "<error: %s>", x.Error())
+		return
+	case time.Time:
+		b.WriteString(strconv.Quote(x.Format(time.RFC3339Nano)))
+		return
+	case []byte:
+		b.WriteString(strconv.Quote(string(x)))
+		return
+	case json.Number:
+		b.WriteString(string(x))
+		return
+	case fmt.Stringer:
+		if v.Kind() != reflect.Map && v.Kind() != reflect.Struct && v.Kind() != reflect.Slice {
+			b.WriteString(strconv.Quote(x.String()))
+			return
+		}
+	case OrderedMap:
+		kk := x.Keys()
+		if len(kk) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteByte('{')
+		for _, k := range kk {
+			vv, _ := x.Get(k)
+			dumpIndent(b, depth+1)
+			b.WriteString(strconv.Quote(k))
+			b.WriteString(": ")
+			dump(b, reflect.ValueOf(vv), depth+1, seen)
+		}
+		dumpIndent(b, depth)
+		b.WriteByte('}')
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			b.WriteString("null")
+			return
+		}
+		dump(b, v.Elem(), depth, seen)
+
+	case reflect.String:
+		b.WriteString(strconv.Quote(v.String()))
+
+	case reflect.Float32, reflect.Float64:
+		b.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()))
+
+	case reflect.Map:
+		if v.Len() == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteByte('{')
+		for _, k := range sortKeys(v.MapKeys()) {
+			dumpIndent(b, depth+1)
+			if k.Kind() == reflect.String {
+				b.WriteString(strconv.Quote(k.String()))
+			} else {
+				fmt.Fprint(b, k.Interface())
+			}
+			b.WriteString(": ")
+			dump(b, v.MapIndex(k), depth+1, seen)
+		}
+		dumpIndent(b, depth)
+		b.WriteByte('}')
+
+	case reflect.Struct:
+		b.WriteByte('{')
+		n := 0
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			dumpIndent(b, depth+1)
+			b.WriteString(f.Name)
+			b.WriteString(": ")
+			dump(b, v.Field(i), depth+1, seen)
+			n++
+		}
+		if n > 0 {
+			dumpIndent(b, depth)
+		}
+		b.WriteByte('}')
+
+	case reflect.Array, reflect.Slice:
+		if v.Len() == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			dumpIndent(b, depth+1)
+			dump(b, v.Index(i), depth+1, seen)
+		}
+		dumpIndent(b, depth)
+		b.WriteByte(']')
+
+	default:
+		fmt.Fprint(b, v.Interface())
+	}
+}
@@ -0,0 +1,80 @@
+package jq
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSdump(t *testing.T) {
+	for _, tc := range []struct {
+		v      interface{}
+		expect string
+	}{
+		{nil, "null\n"},
+		{"x", "\"x\"\n"},
+		{1.5, "1.5\n"},
+		{json.Number("42"), "42\n"},
+		{[]interface{}{}, "[]\n"},
+		{errors.New("boom"), "<error: boom>\n"},
+		{map[string]interface{}{"b": []interface{}{1, nil}, "a": "x"}, `{
+  "a": "x"
+  "b": [
+    1
+    null
+  ]
+}
+`},
+		{map[int]string{10: "x", 9: "y"}, `{
+  9: "y"
+  10: "x"
+}
+`},
+		{struct {
+			B      int
+			A      []byte
+			hidden int
+		}{B: 1, A: []byte("z")}, `{
+  B: 1
+  A: "z"
+}
+`},
+		{QQ(testObj, "array/*/foo"), `[
+  1
+  null
+  null
+]
+`},
+	} {
+		if s := Sdump(tc.v); s != tc.expect {
+			t.Errorf("%#v: expected\n%s\ngot\n%s", tc.v, tc.expect, s)
+		}
+	}
+
+	type node struct {
+		Name string
+		Next *node
+	}
+	n := &node{Name: "a"}
+	n.Next = n
+	if s, expect := Sdump(n), "{\n  Name: \"a\"\n  Next: <cycle>\n}\n"; s != expect {
+		t.Errorf("pointer cycle: expected\n%s\ngot\n%s", expect, s)
+	}
+	m := map[string]interface{}{"a": 1}
+	m["self"] = m
+	if s, expect := Sdump(m), "{\n  \"a\": 1\n  \"self\": <cycle>\n}\n"; s != expect {
+		t.Errorf("map cycle: expected\n%s\ngot\n%s", expect, s)
+	}
+	shared := []interface{}{1}
+	if s, expect := Sdump([]interface{}{shared, shared}), "[\n  [\n    1\n  ]\n  [\n    1\n  ]\n]\n"; s != expect {
+		t.Errorf("shared slice: expected\n%s\ngot\n%s", expect, s)
+	}
+
+	// map iteration order must not leak into the output
+	s := Sdump(testObj)
+	for i := 0; i < 10; i++ {
+		if ss := Sdump(testObj); ss != s {
+			t.Fatalf("unstable output:\n%s\n%s", s, ss)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package jq
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	stringType = reflect.TypeOf("")
+	boolType   = reflect.TypeOf(false)
+	intType    = reflect.TypeOf(0)
+	uintType   = reflect.TypeOf(uint(0))
+)
+
+// maxCachedPaths bounds the number of split paths an Engine remembers.
+const maxCachedPaths = 1024
+
+// An Engine resolves paths with a fixed set of options. Applications can construct one
+// at startup and share it, instead of passing options to every call.
+//
+// An Engine is safe for concurrent use, so it can be shared by all goroutines of a server.
+// It caches split paths and, for struct types, the names of their fields under the options,
+// and it never modifies the documents it queries. The converters, hooks, logger and metrics
+// it is configured with are called concurrently and must be safe for that.
+type Engine struct {
+	c config
+
+	mu    sync.RWMutex
+	paths map[string][]interface{} // split paths by string, for QQ
+}
+
+// NewEngine returns an Engine that applies opts to all of its queries.
+func NewEngine(opts ...Option) *Engine {
+	e := &Engine{c: *newConfig(opts), paths: make(map[string][]interface{})}
+	e.c.fields, e.c.guards = new(sync.Map), new(sync.Map)
+	return e
+}
+
+// Q is like the package-level Q, with the options of e.
+func (e *Engine) Q(root interface{}, index ...interface{}) interface{} {
+	return e.c.query(root, index)
+}
+
+// QQ is like the package-level QQ, with the options of e.
+// Paths are split once and remembered, so repeating a query is cheap.
+func (e *Engine) QQ(root interface{}, path string) interface{} {
+	index, err := e.parsePath(path)
+	if err != nil {
+		return err
+	}
+	return e.c.query(root, index)
+}
+
+// parsePath returns the index array for path, from the cache if possible, or the error
+// of a malformed path. The result must not be modified.
+func (e *Engine) parsePath(path string) ([]interface{}, error) {
+	e.mu.RLock()
+	pp, ok := e.paths[path]
+	e.mu.RUnlock()
+	if ok {
+		return pp, nil
+	}
+	pp, err := e.c.parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	if len(e.paths) >= maxCachedPaths {
+		e.paths = make(map[string][]interface{})
+	}
+	e.paths[path] = pp
+	e.mu.Unlock()
+	return pp, nil
+}
+
+// New starts a fluent query on root with the options of e.
+func (e *Engine) New(root interface{}) Result {
+	return Result{c: &e.c, root: root}
+}
+
+// Exists is like the package-level Exists, with the options of e.
+func (e *Engine) Exists(root interface{}, index ...interface{}) bool {
+	return e.New(root).Index(index...).Exists()
+}
+
+// String is like the package-level String, with the options of e.
+func (e *Engine) String(root interface{}, index ...interface{}) string {
+	return e.c.asString(e.Q(root, index...))
+}
+
+// Bool is like the package-level Bool, with the options of e.
+func (e *Engine) Bool(root interface{}, index ...interface{}) bool {
+	return e.c.asBool(e.Q(root, index...))
+}
+
+// Int is like the package-level Int, with the options of e.
+func (e *Engine) Int(root interface{}, index ...interface{}) int {
+	return e.c.asInt(e.Q(root, index...))
+}
+
+// Uint is like the package-level Uint, with the options of e.
+func (e *Engine) Uint(root interface{}, index ...interface{}) uint {
+	return e.c.asUint(e.Q(root, index...))
+}
+
+// Time is like the package-level Time, with the options of e.
+func (e *Engine) Time(root interface{}, index ...interface{}) time.Time {
+	return e.c.asTime(e.Q(root, index...), e.c.location())
+}
+
+// TimeIn is like the package-level TimeIn, with the options of e.
+func (e *Engine) TimeIn(root interface{}, loc *time.Location, index ...interface{}) time.Time {
+	return timeIn(e.c.asTime(e.Q(root, index...), loc), loc)
+}
+
+// asString converts the result of a query like String, applying the options in c,
+// as do the other conversion methods for the typed getters of Engine and Result.
+func (c *config) asString(v interface{}) string {
+	v = c.expanded(v)
+	if r, ok := c.convert(v, stringType); ok {
+		return r.(string)
+	}
+	return asString(v)
+}
+
+func (c *config) asBool(v interface{}) bool {
+	v = c.expanded(v)
+	if r, ok := c.convert(v, boolType); ok {
+		return r.(bool)
+	}
+	return asBool(v)
+}
+
+func (c *config) asInt(v interface{}) int {
+	v = c.expanded(v)
+	if r, ok := c.convert(v, intType); ok {
+		return r.(int)
+	}
+	return asInt(c.number(v))
+}
+
+func (c *config) asUint(v interface{}) uint {
+	v = c.expanded(v)
+	if r, ok := c.convert(v, uintType); ok {
+		return r.(uint)
+	}
+	n, _ := asUint(c.number(v))
+	return n
+}
+
+// asTime interprets times without a zone in loc.
+func (c *config) asTime(v interface{}, loc *time.Location) time.Time {
+	v = c.expanded(v)
+	if r, ok := c.convert(v, timeType); ok {
+		return r.(time.Time)
+	}
+	return asTime(v, loc, c.timeFormats)
+}
+
+// Get resolves index in root with the options of e, or like Q if e is nil, and converts
+// the result to T: with the converter registered for T, if any, and otherwise as by DecodePath.
+// It returns an error wrapping ErrNotFound if the value is not present, and one wrapping ErrType
+// if it cannot be converted to T.
+func Get[T any](e *Engine, root interface{}, index ...interface{}) (T, error) {
+	var (
+		zero T
+		c    = &plain
+	)
+	if e != nil {
+		c = &e.c
+	}
+	sc := *c
+	sc.strict = true // a missing value is an error, a present null is not
+	r := sc.query(root, index)
+	if err, ok := r.(error); ok {
+		return zero, err
+	}
+	r = c.expanded(r)
+	if v, ok := r.(T); ok {
+		return v, nil
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if v, ok := c.convert(r, t); ok {
+		return v.(T), nil
+	}
+	if r == nil {
+		return zero, nil
+	}
+	var v T
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		r = c.number(r)
+	}
+	if err := weakDecode(pathString(index), r, reflect.ValueOf(&v).Elem()); err != nil {
+		return zero, err
+	}
+	return v, nil
+}
+
+// pathString formats index as a slash separated path for error messages.
+func pathString(index []interface{}) string {
+	ss := make([]string, len(index))
+	for i, idx := range index {
+		ss[i] = fmt.Sprint(idx)
+	}
+	return strings.Join(ss, "/")
+}
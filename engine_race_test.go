@@ -0,0 +1,81 @@
+package jq
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestEngineConcurrent shares an Engine with all of its caches and extension points
+// between goroutines. Run it with -race to check for data races.
+func TestEngineConcurrent(t *testing.T) {
+	type item struct {
+		ID    int    `json:"id"`
+		Label string `json:"label"`
+	}
+	root := map[string]interface{}{
+		"Items":   []item{{1, "one"}, {2, "two"}, {3, "three"}},
+		"payload": `{"n": "0x10"}`,
+	}
+	var (
+		mu    sync.Mutex
+		steps int
+	)
+	e := NewEngine(
+		TagAware("json"),
+		CaseInsensitive(),
+		DecodeStrings(),
+		WithConverter(func(v interface{}) (int, error) {
+			var n int
+			_, err := fmt.Sscan(fmt.Sprint(v), &n)
+			return n, err
+		}),
+		WithHook(HookFuncs{BeforeFunc: func([]interface{}, interface{}, interface{}) {
+			mu.Lock()
+			steps++
+			mu.Unlock()
+		}}),
+		WithMetrics(new(recorder)),
+	)
+
+	const goroutines, rounds = 16, 200
+	var wg sync.WaitGroup
+	errs := make(chan string, goroutines)
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				path := fmt.Sprintf("items/%d/id", i%3)
+				if v := e.QQ(root, path); v != i%3+1 {
+					errs <- fmt.Sprintf("%s: got %v", path, v)
+					return
+				}
+				if v := e.Int(root, "PAYLOAD", "N"); v != 16 {
+					errs <- fmt.Sprintf("converter: got %v", v)
+					return
+				}
+				if v := e.QCtx(context.Background(), root, "items", ALL, "label"); !reflect.DeepEqual(v, []interface{}{"one", "two", "three"}) {
+					errs <- fmt.Sprintf("ALL: got %v", v)
+					return
+				}
+				if v := e.New(root).Path("Items").Index(g % 3).Keys().Strings(); !reflect.DeepEqual(v, []string{"id", "label"}) {
+					errs <- fmt.Sprintf("KEYS: got %v", v)
+					return
+				}
+				// many distinct paths churn the path cache
+				e.QQ(root, fmt.Sprintf("items/%d/%d", g, i))
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if steps == 0 {
+		t.Errorf("hook not called")
+	}
+}
@@ -0,0 +1,263 @@
+package jq
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEngine(t *testing.T) {
+	type user struct {
+		Name  string `json:"name"`
+		Admin bool   `json:"is_admin"`
+		Age   int    `json:"age"`
+	}
+	root := map[string]interface{}{
+		"Users":   []user{{"ann", true, 41}, {"bob", false, 29}},
+		"payload": `{"id": 7, "tags": ["a", "b"]}`,
+	}
+
+	e := NewEngine(TagAware("json"), CaseInsensitive(), DecodeStrings())
+	if v := e.String(root, "users", 0, "name"); v != "ann" {
+		t.Errorf("String: got %q", v)
+	}
+	if v := e.Bool(root, "users", 0, "is_admin"); !v {
+		t.Errorf("Bool: got %v", v)
+	}
+	if v := e.Int(root, "users", 1, "age"); v != 29 {
+		t.Errorf("Int: got %v", v)
+	}
+	if v := e.QQ(root, "users/*/name"); !reflect.DeepEqual(v, []interface{}{"ann", "bob"}) {
+		t.Errorf("QQ: got %v", v)
+	}
+	if v := e.QQ(root, "users/*/name"); !reflect.DeepEqual(v, []interface{}{"ann", "bob"}) {
+		t.Errorf("QQ cached: got %v", v)
+	}
+	if v := e.QQ(root, "payload/tags/1"); v != "b" {
+		t.Errorf("QQ decoded string: got %v", v)
+	}
+	// the package-level Q is unaffected by the options of e
+	if v := Q(root, "payload", "tags"); !isError(v) {
+		t.Errorf("package-level Q: expected error for indexing a string, got %v", v)
+	}
+	if v := e.New(root).Path("USERS").All().Path("name").Strings(); !reflect.DeepEqual(v, []string{"ann", "bob"}) {
+		t.Errorf("New: got %v", v)
+	}
+	if !e.Exists(root, "users", 1, "age") || e.Exists(root, "users", 2) || e.Exists(root, "nosuchkey") {
+		t.Errorf("Exists: wrong result")
+	}
+
+	if v := NewEngine(MaxDepth(1)).Q(root, "Users", 0); !isError(v) {
+		t.Errorf("MaxDepth: expected error, got %v", v)
+	}
+}
+
+func isError(v interface{}) bool {
+	_, ok := v.(error)
+	return ok
+}
+
+type cents int64
+
+func parseCents(v interface{}) (cents, error) {
+	s, ok := v.(string)
+	if !ok || !strings.HasPrefix(s, "$") {
+		return 0, fmt.Errorf("not an amount: %v", v)
+	}
+	f, err := strconv.ParseFloat(s[1:], 64)
+	return cents(math.Round(f * 100)), err
+}
+
+func TestConverters(t *testing.T) {
+	root := map[string]interface{}{
+		"price":  "$12.50",
+		"hex":    "0x1f",
+		"count":  3.,
+		"status": 2,
+		"raw":    cents(5),
+	}
+	e := NewEngine(
+		WithConverter(parseCents),
+		WithConverter(func(v interface{}) (int, error) {
+			s, _ := v.(string)
+			n, err := strconv.ParseInt(s, 0, 64)
+			return int(n), err
+		}),
+		WithConverter(func(v interface{}) (string, error) {
+			if n, ok := v.(int); ok {
+				return []string{"new", "active", "closed"}[n], nil
+			}
+			return "", fmt.Errorf("not a status: %v", v)
+		}),
+	)
+
+	if v := e.Int(root, "hex"); v != 31 {
+		t.Errorf("Int with converter: expected 31, got %v", v)
+	}
+	if v := Int(root, "hex"); v != 0 {
+		t.Errorf("package-level Int: expected 0, got %v", v)
+	}
+	if v := e.String(root, "status"); v != "closed" {
+		t.Errorf("String with converter: expected closed, got %q", v)
+	}
+	if v := e.String(root, "price"); v != "$12.50" {
+		t.Errorf("String of string: got %q", v)
+	}
+	if v := e.New(root).Path("hex").Int(); v != 31 {
+		t.Errorf("Result.Int with converter: expected 31, got %v", v)
+	}
+	if v := e.New(root).Path("status").String(); v != "closed" {
+		t.Errorf("Result.String with converter: expected closed, got %q", v)
+	}
+	if v := e.New(map[string]interface{}{"a": []interface{}{"0x10", 2}}).Path("a").Ints(); !reflect.DeepEqual(v, []int{16, 2}) {
+		t.Errorf("Result.Ints with converter: got %v", v)
+	}
+	if v := New(root).Path("hex").Int(); v != 0 {
+		t.Errorf("package-level New: expected 0, got %v", v)
+	}
+	if v, err := Get[cents](e, root, "price"); err != nil || v != 1250 {
+		t.Errorf("Get with converter: got %v, %v", v, err)
+	}
+	if v, err := Get[cents](e, root, "raw"); err != nil || v != 5 {
+		t.Errorf("Get of T: got %v, %v", v, err)
+	}
+	if v, err := Get[int](nil, root, "count"); err != nil || v != 3 {
+		t.Errorf("Get without engine: got %v, %v", v, err)
+	}
+	if v, err := Get[[]string](nil, testObj, "subobj", "subsubobj", "array"); err != nil || !reflect.DeepEqual(v, []string{"hello", "world"}) {
+		t.Errorf("Get slice: got %v, %v", v, err)
+	}
+	if _, err := Get[int](e, root, "nosuchkey"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get missing: expected ErrNotFound, got %v", err)
+	}
+	if v, err := Get[interface{}](nil, map[string]interface{}{"n": nil}, "n"); err != nil || v != nil {
+		t.Errorf("Get null: got %v, %v", v, err)
+	}
+	if _, err := Get[cents](nil, root, "price"); err == nil {
+		t.Errorf("Get without converter: expected error")
+	}
+	if _, err := Get[fmt.Stringer](nil, root, "price"); !errors.Is(err, ErrType) {
+		t.Errorf("Get[fmt.Stringer] of a string: expected ErrType, got %v", err)
+	}
+	if v, err := Get[fmt.Stringer](nil, map[string]interface{}{"d": time.Second}, "d"); err != nil || v != time.Second {
+		t.Errorf("Get[fmt.Stringer] of a time.Duration: got %v, %v", v, err)
+	}
+
+	queries := 0
+	counted := NewEngine(WithHook(HookFuncs{AfterFunc: func(path []interface{}, v interface{}) interface{} {
+		if len(path) == 0 {
+			queries++
+		}
+		return v
+	}}))
+	for _, path := range []string{"nosuchkey", "count"} {
+		queries = 0
+		Get[int](counted, root, path)
+		if queries != 1 {
+			t.Errorf("Get %q: expected 1 query, got %d", path, queries)
+		}
+	}
+	withNull := map[string]interface{}{"n": nil}
+	for _, tc := range []struct {
+		path   string
+		expect Kind
+	}{
+		{"n", KindNull},
+		{"nosuchkey", KindMissing},
+		{"n/x", KindMissing},
+	} {
+		queries = 0
+		if k := counted.New(withNull).Path(tc.path).Kind(); k != tc.expect {
+			t.Errorf("Kind %q: expected %v, got %v", tc.path, tc.expect, k)
+		}
+		if queries != 1 {
+			t.Errorf("Kind %q: expected 1 query, got %d", tc.path, queries)
+		}
+	}
+}
+
+func TestNumberFormat(t *testing.T) {
+	root := map[string]interface{}{
+		"de":    "1.234,56",
+		"count": "1.234",
+		"fr":    "12 345",
+		"plain": "42",
+		"bad":   "1,2,3",
+		"text":  "n/a",
+		"list":  []interface{}{"1.000", "2.000"},
+	}
+	de := NewEngine(NumberFormat(',', '.'))
+	if v, err := Get[float64](de, root, "de"); err != nil || v != 1234.56 {
+		t.Errorf("Get[float64]: got %v, %v", v, err)
+	}
+	if v := de.Int(root, "count"); v != 1234 {
+		t.Errorf("Int: expected 1234, got %v", v)
+	}
+	if v := de.Int(root, "de"); v != 0 {
+		t.Errorf("Int of a fraction: expected 0, got %v", v)
+	}
+	if v := de.Int(root, "plain"); v != 42 {
+		t.Errorf("Int of a plain number: expected 42, got %v", v)
+	}
+	if _, err := Get[float64](de, root, "bad"); err == nil {
+		t.Errorf("Get[float64] of two decimal separators: expected an error")
+	}
+	if _, err := Get[float64](de, root, "text"); err == nil {
+		t.Errorf("Get[float64] of text: expected an error")
+	}
+	if v, err := Get[string](de, root, "de"); err != nil || v != "1.234,56" {
+		t.Errorf("Get[string]: got %q, %v", v, err)
+	}
+	if v := de.New(root).Path("list").Ints(); !reflect.DeepEqual(v, []int{1000, 2000}) {
+		t.Errorf("Ints: got %v", v)
+	}
+	if v, err := Get[int](NewEngine(NumberFormat('.', ' ')), root, "fr"); err != nil || v != 12345 {
+		t.Errorf("Get[int] with spaces: got %v, %v", v, err)
+	}
+	if v := Int(root, "count"); v != 0 {
+		t.Errorf("package-level Int: expected 0, got %v", v)
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	vars := map[string]string{"HOME": "/home/x", "PORT": "8080", "ON": "yes"}
+	root := map[string]interface{}{
+		"dir":   "${HOME}/data",
+		"port":  "$PORT",
+		"flag":  "$ON",
+		"dirs":  []interface{}{"$HOME/a", "b"},
+		"plain": "cost: 5$",
+	}
+	e := NewEngine(ExpandEnv(func(k string) string { return vars[k] }))
+	if v := e.String(root, "dir"); v != "/home/x/data" {
+		t.Errorf("String: got %q", v)
+	}
+	if v, err := Get[int](e, root, "port"); err != nil || v != 8080 {
+		t.Errorf("Get[int]: got %v, %v", v, err)
+	}
+	if v, err := Get[string](e, root, "port"); err != nil || v != "8080" {
+		t.Errorf("Get[string]: got %q, %v", v, err)
+	}
+	if v := e.New(root).Path("dirs").Strings(); !reflect.DeepEqual(v, []string{"/home/x/a", "b"}) {
+		t.Errorf("Strings: got %v", v)
+	}
+	if v := e.New(root).Path("plain").String(); v != "cost: 5$" {
+		t.Errorf("String with a lone $: got %q", v)
+	}
+	if v := e.Q(root, "dir"); v != "${HOME}/data" {
+		t.Errorf("Q: got %v", v)
+	}
+	if v := String(root, "dir"); v != "${HOME}/data" {
+		t.Errorf("package-level String: got %q", v)
+	}
+
+	t.Setenv("JQ_TEST_VAR", "from env")
+	if v := NewEngine(ExpandEnv(nil)).String(map[string]interface{}{"a": "$JQ_TEST_VAR"}, "a"); v != "from env" {
+		t.Errorf("os.Getenv: got %q", v)
+	}
+}
@@ -0,0 +1,53 @@
+package jq
+
+// Exists reports whether the path resolves to a present value,
+// including a value that is present but nil, such as a JSON null.
+// For paths containing ALL or KEYS, Exists reports whether Q returns a non-nil result.
+func Exists(root interface{}, index ...interface{}) bool {
+	_, ok := lookup(root, index)
+	return ok
+}
+
+// Has reports whether the path resolves to a present value that is not nil.
+func Has(root interface{}, index ...interface{}) bool {
+	v, ok := lookup(root, index)
+	return ok && v != nil
+}
+
+// lookup is like Q, but also reports whether the value is present.
+// Errors are reported as not present.
+func lookup(root interface{}, index []interface{}) (interface{}, bool) {
+	return plain.lookup(root, index)
+}
+
+// lookup implements lookup, applying the options in c, which must not have hooks.
+// Presence is decided by resolving each element strictly, so that a missing value is an error
+// and a present null is nil, with the same lookup of keys and fields as queries under c.
+func (c *config) lookup(root interface{}, index []interface{}) (interface{}, bool) {
+	cur, err := c.provided(root)
+	if err != nil {
+		return nil, false
+	}
+	sc := *c
+	sc.strict = true
+	for i, idx := range index {
+		if l, ok := cur.(Layered); ok {
+			if cur, err = c.provided(l.pick(c, index[i:])); err != nil {
+				return nil, false
+			}
+		}
+		if _, ok := idx.(quantifier); ok {
+			r := c.step(cur, index[i:])
+			if _, ok := r.(error); ok || r == nil {
+				return nil, false
+			}
+			return r, true
+		}
+		r := sc.step(cur, index[i:i+1])
+		if _, ok := r.(error); ok {
+			return nil, false
+		}
+		cur = r
+	}
+	return cur, true
+}
@@ -0,0 +1,84 @@
+package jq
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExists(t *testing.T) {
+	var root interface{}
+	if err := json.Unmarshal([]byte(`{"a": null, "b": 0, "c": [null, 1], "d": {"e": null}}`), &root); err != nil {
+		t.Fatal(err)
+	}
+	raw := map[string]json.RawMessage{"r": json.RawMessage(`{"n": null}`)}
+	var iface struct{ X interface{} }
+
+	for _, tc := range []struct {
+		root   interface{}
+		path   []interface{}
+		exists bool
+		has    bool
+	}{
+		{root, nil, true, true},
+		{root, []interface{}{"a"}, true, false},
+		{root, []interface{}{"b"}, true, true},
+		{root, []interface{}{"nosuchkey"}, false, false},
+		{root, []interface{}{"c", 0}, true, false},
+		{root, []interface{}{"c", "1"}, true, true},
+		{root, []interface{}{"c", 2}, false, false},
+		{root, []interface{}{"c", -1}, false, false},
+		{root, []interface{}{"d", "e"}, true, false},
+		{root, []interface{}{"a", "x"}, false, false},
+		{root, []interface{}{"b", "x"}, false, false},
+		{root, []interface{}{"c", ALL}, true, true},
+		{root, []interface{}{"d", ALL}, true, true}, // empty map, but not nil
+		{raw, []interface{}{"r", "n"}, true, false},
+		{raw, []interface{}{"r", "m"}, false, false},
+		{iface, []interface{}{"x"}, true, false},
+		{iface, []interface{}{"y"}, false, false},
+		{map[int]interface{}{1: nil}, []interface{}{"1"}, true, false},
+	} {
+		if v := Exists(tc.root, tc.path...); v != tc.exists {
+			t.Errorf("%#v [%v]: Exists expected %v, got %v", tc.root, tc.path, tc.exists, v)
+		}
+		if v := Has(tc.root, tc.path...); v != tc.has {
+			t.Errorf("%#v [%v]: Has expected %v, got %v", tc.root, tc.path, tc.has, v)
+		}
+	}
+}
+
+func TestExistsOptions(t *testing.T) {
+	type user struct {
+		Name string  `json:"name"`
+		Nick *string `json:"nick"`
+	}
+	root := map[string]interface{}{"User": user{Name: "ann"}, "Key": nil}
+	e := NewEngine(CaseInsensitive(), TagAware("json"))
+	for _, tc := range []struct {
+		index  []interface{}
+		expect bool
+	}{
+		{[]interface{}{"user", "name"}, true},
+		{[]interface{}{"user", "nick"}, true},
+		{[]interface{}{"user", "Nick"}, true},
+		{[]interface{}{"user", "nope"}, false},
+		{[]interface{}{"key"}, true},
+		{[]interface{}{"key", "x"}, false},
+	} {
+		if v := e.Exists(root, tc.index...); v != tc.expect {
+			t.Errorf("Exists%v: expected %v, got %v", tc.index, tc.expect, v)
+		}
+	}
+
+	// a present null in the first layer is found under the options of e
+	layers := Layered{root, map[string]interface{}{"key": 5, "user": map[string]interface{}{"nick": "x"}}}
+	if v := e.Q(layers, "key"); v != nil {
+		t.Errorf("Layered: expected the null of the first layer, got %v", v)
+	}
+	if v, ok := e.Q(layers, "user", "nick").(*string); !ok || v != nil {
+		t.Errorf("Layered: expected the nil field of the first layer, got %v", v)
+	}
+	if !e.Exists(layers, "user", "nick") || !e.Exists(layers, "key") || e.Exists(layers, "user", "nope") {
+		t.Errorf("Exists of Layered: wrong result")
+	}
+}
@@ -0,0 +1,852 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Expr is a compiled expression in a subset of the language of the jq command line tool.
+//
+// It supports the identity ., field access .foo, ."foo" and .["foo"], array indices .[0] and .[-1],
+// slices .[1:3], iteration .[], recursion .., the optional suffix ?, pipes |, commas ,,
+// array and object construction [...] and {a: .b, "c": .d, (.e): .f, g}, parentheses, literals,
+// the arithmetic operators + - * / % with the meanings jq gives them for strings, arrays and objects,
+// the comparisons == != < <= > >=, and, or, the alternative a // b, conditionals
+// if c then a elif c then a else b end, variables bound with term as $name | body or passed
+// to CompileWithArgs, and the functions select(f), map(f), length, keys,
+// not, type, empty, recurse, add, tostring, tonumber, split(s), join(s), ascii_downcase
+// and ascii_upcase.
+//
+// Values are compared and ordered like jq does, so 1 == 1.0 and null < false < true < numbers
+// < strings < arrays < objects. Objects are iterated in the order of their sorted keys, or in
+// the order of their fields for structs and of their keys for an OrderedMap.
+type Expr struct {
+	src  string
+	root node
+	args *env
+}
+
+// Compile parses expr.
+func Compile(expr string) (*Expr, error) {
+	return CompileWithArgs(expr, nil)
+}
+
+// CompileWithArgs parses expr with the variables in args defined, so that $name
+// refers to args["name"] anywhere in expr, like the --arg and --argjson options of jq.
+func CompileWithArgs(expr string, args map[string]interface{}) (*Expr, error) {
+	tt, err := lex(expr)
+	if err != nil {
+		return nil, fmt.Errorf("jq: %v", err)
+	}
+	p := &parser{toks: tt}
+	var e *env
+	for name, v := range args {
+		p.vars = append(p.vars, name)
+		e = &env{name: name, value: v, parent: e}
+	}
+	n, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("jq: %v", err)
+	}
+	return &Expr{src: expr, root: n, args: e}, nil
+}
+
+// MustCompile is like Compile but panics if expr cannot be parsed.
+func MustCompile(expr string) *Expr {
+	e, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// String returns the source of e.
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Run evaluates e with input root and returns all of its outputs.
+// If evaluation fails, it returns the outputs produced before the error along with the error.
+func (e *Expr) Run(root interface{}) ([]interface{}, error) {
+	return e.root.eval(root, e.args)
+}
+
+// First evaluates e with input root and returns its first output, or nil if there is none.
+func (e *Expr) First(root interface{}) (interface{}, error) {
+	out, err := e.Run(root)
+	if len(out) > 0 {
+		return out[0], nil
+	}
+	return nil, err
+}
+
+// env holds the variables bound during evaluation.
+type env struct {
+	name   string
+	value  interface{}
+	parent *env
+}
+
+// node is an expression in the syntax tree. Eval returns the outputs of the expression
+// for the input in, and the outputs produced before an error along with the error.
+type node interface {
+	eval(in interface{}, e *env) ([]interface{}, error)
+}
+
+type identityNode struct{}
+
+func (identityNode) eval(in interface{}, _ *env) ([]interface{}, error) {
+	return []interface{}{in}, nil
+}
+
+// bindNode implements source as $name | body, evaluating body with the original input
+// once for every output of source.
+type bindNode struct {
+	name         string
+	source, body node
+}
+
+func (n bindNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	vv, err := n.source.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, v := range vv {
+		rr, err := n.body.eval(in, &env{name: n.name, value: v, parent: e})
+		out = append(out, rr...)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+type varNode struct{ name string }
+
+func (n varNode) eval(_ interface{}, e *env) ([]interface{}, error) {
+	for ; e != nil; e = e.parent {
+		if e.name == n.name {
+			return []interface{}{e.value}, nil
+		}
+	}
+	return nil, fmt.Errorf("$%s is not defined", n.name) // prevented by the parser
+}
+
+type literalNode struct{ v interface{} }
+
+func (n literalNode) eval(interface{}, *env) ([]interface{}, error) {
+	return []interface{}{n.v}, nil
+}
+
+type pipeNode struct{ l, r node }
+
+func (n pipeNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	ll, err := n.l.eval(in, e)
+	var out []interface{}
+	for _, l := range ll {
+		rr, err := n.r.eval(l, e)
+		out = append(out, rr...)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, err
+}
+
+type commaNode struct{ l, r node }
+
+func (n commaNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	out, err := n.l.eval(in, e)
+	if err != nil {
+		return out, err
+	}
+	rr, err := n.r.eval(in, e)
+	return append(out, rr...), err
+}
+
+// indexNode selects the element key of the outputs of target, where key is evaluated with the original input.
+type indexNode struct{ target, key node }
+
+func (n indexNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	tt, err := n.target.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	kk, err := n.key.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, t := range tt {
+		for _, k := range kk {
+			v, err := indexValue(t, k)
+			if err != nil {
+				return out, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// indexValue returns v[k] with the semantics of jq: null for missing keys and out of range indices,
+// negative indices counting from the end, and an error for mismatched types.
+func indexValue(v, k interface{}) (interface{}, error) {
+	v = unwrap(v)
+	kind := KindOf(v)
+	if kind == KindNull {
+		return nil, nil
+	}
+	if s, ok := k.(string); ok && kind == KindObject {
+		r := Q(v, s)
+		if err, ok := r.(error); ok {
+			return nil, err
+		}
+		return r, nil
+	}
+	if f, ok := number(k); ok && kind == KindArray {
+		ee := elements(v)
+		i := int(math.Floor(f))
+		if i < 0 {
+			i += len(ee)
+		}
+		if i < 0 || i >= len(ee) {
+			return nil, nil
+		}
+		return ee[i], nil
+	}
+	return nil, fmt.Errorf("cannot index %s with %s", kind, describe(k))
+}
+
+// describe formats v for error messages.
+func describe(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return fmt.Sprintf("%s (%s)", KindOf(v), canonical(v))
+}
+
+type sliceNode struct{ target, from, to node }
+
+func (n sliceNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	tt, err := n.target.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	bound := func(b node, def int) ([]int, error) {
+		if b == nil {
+			return []int{def}, nil
+		}
+		bb, err := b.eval(in, e)
+		var ii []int
+		for _, v := range bb {
+			f, ok := number(v)
+			if !ok {
+				return nil, fmt.Errorf("cannot use %s as slice index", describe(v))
+			}
+			ii = append(ii, int(math.Floor(f)))
+		}
+		return ii, err
+	}
+	var out []interface{}
+	for _, t := range tt {
+		t = unwrap(t)
+		var length int
+		switch KindOf(t) {
+		case KindNull:
+			out = append(out, nil)
+			continue
+		case KindString:
+			length = utf8.RuneCountInString(asString(t))
+		case KindArray:
+			length = len(elements(t))
+		default:
+			return out, fmt.Errorf("cannot slice %s", KindOf(t))
+		}
+		ff, err := bound(n.from, 0)
+		if err != nil {
+			return out, err
+		}
+		toos, err := bound(n.to, length)
+		if err != nil {
+			return out, err
+		}
+		for _, to := range toos {
+			for _, from := range ff {
+				from, to := clamp(from, length), clamp(to, length)
+				if to < from {
+					to = from
+				}
+				if KindOf(t) == KindString {
+					out = append(out, string([]rune(asString(t))[from:to]))
+				} else {
+					out = append(out, append([]interface{}(nil), elements(t)[from:to]...))
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// clamp resolves a possibly negative slice index against length.
+func clamp(i, length int) int {
+	if i < 0 {
+		i += length
+	}
+	if i < 0 {
+		return 0
+	}
+	if i > length {
+		return length
+	}
+	return i
+}
+
+type iterateNode struct{ target node }
+
+func (n iterateNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	tt, err := n.target.eval(in, e)
+	var out []interface{}
+	for _, t := range tt {
+		t = unwrap(t)
+		switch k := KindOf(t); k {
+		case KindArray, KindObject:
+			out = append(out, elements(t)...)
+		default:
+			return out, fmt.Errorf("cannot iterate over %s", describe(t))
+		}
+	}
+	return out, err
+}
+
+type negNode struct{ body node }
+
+func (n negNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	vv, err := n.body.eval(in, e)
+	out := make([]interface{}, 0, len(vv))
+	for _, v := range vv {
+		f, ok := number(v)
+		if !ok {
+			return out, fmt.Errorf("cannot negate %s", describe(v))
+		}
+		out = append(out, -f)
+	}
+	return out, err
+}
+
+// altNode implements a // b: the outputs of a that are neither null nor false,
+// or the outputs of b if there are none or a fails.
+type altNode struct{ l, r node }
+
+func (n altNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	ll, _ := n.l.eval(in, e)
+	var out []interface{}
+	for _, l := range ll {
+		if truthy(l) {
+			out = append(out, l)
+		}
+	}
+	if len(out) > 0 {
+		return out, nil
+	}
+	return n.r.eval(in, e)
+}
+
+// ifNode evaluates then or otherwise for each output of cond.
+type ifNode struct{ cond, then, otherwise node }
+
+func (n ifNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	cc, err := n.cond.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, c := range cc {
+		branch := n.otherwise
+		if truthy(c) {
+			branch = n.then
+		}
+		rr, err := branch.eval(in, e)
+		out = append(out, rr...)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+type tryNode struct{ body node }
+
+func (n tryNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	out, _ := n.body.eval(in, e)
+	return out, nil
+}
+
+// arrayNode collects the outputs of body into an array; body is nil for [].
+type arrayNode struct{ body node }
+
+func (n arrayNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	a := []interface{}{}
+	if n.body != nil {
+		out, err := n.body.eval(in, e)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, out...)
+	}
+	return []interface{}{a}, nil
+}
+
+// logicNode implements 'and' and 'or', which evaluate r only if needed.
+type logicNode struct {
+	and  bool
+	l, r node
+}
+
+func (n logicNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	ll, err := n.l.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, l := range ll {
+		if truthy(l) != n.and {
+			out = append(out, !n.and)
+			continue
+		}
+		rr, err := n.r.eval(in, e)
+		for _, r := range rr {
+			out = append(out, truthy(r))
+		}
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+// binaryNode applies an operator to every combination of the outputs of l and r.
+type binaryNode struct {
+	op   string
+	l, r node
+}
+
+func (n binaryNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	rr, err := n.r.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	ll, err := n.l.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, r := range rr {
+		for _, l := range ll {
+			v, err := binary(n.op, l, r)
+			if err != nil {
+				return out, err
+			}
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// maxRepeatLen is the length in bytes up to which a string can be repeated with *.
+const maxRepeatLen = 1 << 26
+
+// binary applies op to l and r.
+func binary(op string, l, r interface{}) (interface{}, error) {
+	l, r = unwrap(l), unwrap(r)
+	switch op {
+	case "==":
+		return Equal(l, r), nil
+	case "!=":
+		return !Equal(l, r), nil
+	case "<":
+		return compare(l, r) < 0, nil
+	case "<=":
+		return compare(l, r) <= 0, nil
+	case ">":
+		return compare(l, r) > 0, nil
+	case ">=":
+		return compare(l, r) >= 0, nil
+	}
+
+	lf, lnum := number(l)
+	rf, rnum := number(r)
+	lk, rk := KindOf(l), KindOf(r)
+	switch {
+	case lnum && rnum:
+		switch op {
+		case "+":
+			return lf + rf, nil
+		case "-":
+			return lf - rf, nil
+		case "*":
+			return lf * rf, nil
+		case "/":
+			if rf == 0 {
+				return nil, fmt.Errorf("%s and %s cannot be divided because the divisor is zero", describe(l), describe(r))
+			}
+			return lf / rf, nil
+		case "%":
+			if int64(rf) == 0 {
+				return nil, fmt.Errorf("%s and %s cannot be divided because the divisor is zero", describe(l), describe(r))
+			}
+			m := int64(lf) % int64(math.Abs(rf))
+			return float64(m), nil
+		}
+	case op == "+" && lk == KindNull:
+		return r, nil
+	case op == "+" && rk == KindNull:
+		return l, nil
+	case lk == KindString && rk == KindString:
+		switch op {
+		case "+":
+			return asString(l) + asString(r), nil
+		case "/":
+			return splitString(asString(l), asString(r)), nil
+		}
+	case lk == KindString && rnum && op == "*":
+		if !(rf > 0) {
+			return nil, nil
+		}
+		s, n := asString(l), math.Ceil(rf)
+		if s == "" {
+			return "", nil
+		}
+		if n > maxRepeatLen || float64(len(s))*n > maxRepeatLen {
+			return nil, fmt.Errorf("%s repeated %v times is too long", describe(l), rf)
+		}
+		return strings.Repeat(s, int(n)), nil
+	case lk == KindArray && rk == KindArray:
+		switch op {
+		case "+":
+			return append(append([]interface{}{}, elements(l)...), elements(r)...), nil
+		case "-":
+			a := []interface{}{}
+			rr := elements(r)
+		next:
+			for _, v := range elements(l) {
+				for _, x := range rr {
+					if Equal(v, x) {
+						continue next
+					}
+				}
+				a = append(a, v)
+			}
+			return a, nil
+		}
+	case lk == KindObject && rk == KindObject:
+		switch op {
+		case "+":
+			return mergeObjects(l, r, false), nil
+		case "*":
+			return mergeObjects(l, r, true), nil
+		}
+	}
+	return nil, fmt.Errorf("%s and %s cannot be combined with %s", describe(l), describe(r), op)
+}
+
+// mergeObjects returns the entries of l and r in a new map, with those of r taking precedence,
+// merging objects found under the same key recursively if deep is set.
+func mergeObjects(l, r interface{}, deep bool) map[string]interface{} {
+	m, _ := merge(nil, l, r, deep, nil)
+	return m
+}
+
+// splitString splits s at every sep like jq does, returning an array.
+func splitString(s, sep string) []interface{} {
+	a := []interface{}{}
+	if s == "" {
+		return a
+	}
+	for _, p := range strings.Split(s, sep) {
+		a = append(a, p)
+	}
+	return a
+}
+
+// objectNode constructs objects from its entries, one for every combination of their outputs.
+type objectNode struct {
+	entries []objectEntry
+}
+
+type objectEntry struct {
+	key, value node
+}
+
+func (n objectNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	objs := []map[string]interface{}{{}}
+	for _, entry := range n.entries {
+		kk, err := entry.key.eval(in, e)
+		if err != nil {
+			return nil, err
+		}
+		vv, err := entry.value.eval(in, e)
+		if err != nil {
+			return nil, err
+		}
+		var next []map[string]interface{}
+		for _, o := range objs {
+			for _, k := range kk {
+				ks, ok := k.(string)
+				if !ok {
+					return nil, fmt.Errorf("object keys must be strings, got %s", describe(k))
+				}
+				for _, v := range vv {
+					m := make(map[string]interface{}, len(o)+1)
+					for ok, ov := range o {
+						m[ok] = ov
+					}
+					m[ks] = v
+					next = append(next, m)
+				}
+			}
+		}
+		objs = next
+	}
+	out := make([]interface{}, len(objs))
+	for i, o := range objs {
+		out[i] = o
+	}
+	return out, nil
+}
+
+// truthy reports whether v counts as true: anything but null and false.
+func truthy(v interface{}) bool {
+	switch order(v) {
+	case orderNull, orderFalse:
+		return false
+	}
+	return true
+}
+
+// callNode calls a builtin function.
+type callNode struct {
+	name string
+	args []node
+}
+
+// builtin implements a function. Its arguments are passed unevaluated, since most
+// functions evaluate them for each input, or not at all.
+type builtin func(in interface{}, args []node, e *env) ([]interface{}, error)
+
+// builtins holds the functions by name and number of arguments.
+var builtins map[string]builtin
+
+func init() {
+	builtins = map[string]builtin{
+		"empty/0": func(interface{}, []node, *env) ([]interface{}, error) { return nil, nil },
+		"not/0": func(in interface{}, _ []node, _ *env) ([]interface{}, error) {
+			return []interface{}{!truthy(in)}, nil
+		},
+		"length/0": func(in interface{}, _ []node, _ *env) ([]interface{}, error) {
+			n, err := length(in)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{n}, nil
+		},
+		"keys/0": func(in interface{}, _ []node, _ *env) ([]interface{}, error) {
+			in = unwrap(in)
+			switch KindOf(in) {
+			case KindObject, KindArray:
+				kk, _ := keys(in).([]interface{})
+				if kk == nil {
+					kk = []interface{}{}
+				}
+				return []interface{}{kk}, nil
+			}
+			return nil, fmt.Errorf("%s has no keys", describe(in))
+		},
+		"type/0": func(in interface{}, _ []node, _ *env) ([]interface{}, error) {
+			return []interface{}{KindOf(in).String()}, nil
+		},
+		"select/1": func(in interface{}, args []node, e *env) ([]interface{}, error) {
+			cc, err := args[0].eval(in, e)
+			var out []interface{}
+			for _, c := range cc {
+				if truthy(c) {
+					out = append(out, in)
+				}
+			}
+			return out, err
+		},
+		"map/1": func(in interface{}, args []node, e *env) ([]interface{}, error) {
+			out, err := iterateNode{identityNode{}}.eval(in, e)
+			if err != nil {
+				return nil, err
+			}
+			a := []interface{}{}
+			for _, v := range out {
+				rr, err := args[0].eval(v, e)
+				if err != nil {
+					return nil, err
+				}
+				a = append(a, rr...)
+			}
+			return []interface{}{a}, nil
+		},
+		"add/0": func(in interface{}, _ []node, _ *env) ([]interface{}, error) {
+			in = unwrap(in)
+			if k := KindOf(in); k != KindArray && k != KindObject {
+				return nil, fmt.Errorf("cannot add the elements of %s", describe(in))
+			}
+			var acc interface{}
+			for _, v := range elements(in) {
+				var err error
+				if acc, err = binary("+", acc, v); err != nil {
+					return nil, err
+				}
+			}
+			return []interface{}{acc}, nil
+		},
+		"tostring/0": func(in interface{}, _ []node, _ *env) ([]interface{}, error) {
+			if KindOf(in) == KindString {
+				return []interface{}{asString(in)}, nil
+			}
+			b, err := json.Marshal(in)
+			if err != nil {
+				return nil, err
+			}
+			return []interface{}{string(b)}, nil
+		},
+		"tonumber/0": func(in interface{}, _ []node, _ *env) ([]interface{}, error) {
+			if f, ok := number(in); ok {
+				return []interface{}{f}, nil
+			}
+			if KindOf(in) == KindString {
+				if f, err := strconv.ParseFloat(strings.TrimSpace(asString(in)), 64); err == nil {
+					return []interface{}{f}, nil
+				}
+			}
+			return nil, fmt.Errorf("cannot parse %s as a number", describe(in))
+		},
+		"split/1": func(in interface{}, args []node, e *env) ([]interface{}, error) {
+			if KindOf(in) != KindString {
+				return nil, fmt.Errorf("cannot split %s", describe(in))
+			}
+			return eachString(in, args[0], e, "separator", func(sep string) (interface{}, error) {
+				return splitString(asString(in), sep), nil
+			})
+		},
+		"join/1": func(in interface{}, args []node, e *env) ([]interface{}, error) {
+			in = unwrap(in)
+			if KindOf(in) != KindArray {
+				return nil, fmt.Errorf("cannot join %s", describe(in))
+			}
+			return eachString(in, args[0], e, "separator", func(sep string) (interface{}, error) {
+				var b strings.Builder
+				for i, v := range elements(in) {
+					if i > 0 {
+						b.WriteString(sep)
+					}
+					switch KindOf(v) {
+					case KindNull:
+					case KindString:
+						b.WriteString(asString(v))
+					case KindNumber, KindBool:
+						b.WriteString(canonical(v))
+					default:
+						return nil, fmt.Errorf("cannot join %s", describe(v))
+					}
+				}
+				return b.String(), nil
+			})
+		},
+		"ascii_downcase/0": func(in interface{}, _ []node, _ *env) ([]interface{}, error) {
+			return mapASCII(in, 'A', 'Z', 'a'-'A')
+		},
+		"ascii_upcase/0": func(in interface{}, _ []node, _ *env) ([]interface{}, error) {
+			return mapASCII(in, 'a', 'z', 'A'-'a')
+		},
+		"recurse/0": func(in interface{}, _ []node, _ *env) ([]interface{}, error) {
+			var out []interface{}
+			var rec func(v interface{})
+			rec = func(v interface{}) {
+				v = unwrap(v)
+				out = append(out, v)
+				if k := KindOf(v); k == KindArray || k == KindObject {
+					for _, c := range elements(v) {
+						rec(c)
+					}
+				}
+			}
+			rec(in)
+			return out, nil
+		},
+	}
+}
+
+// eachString calls fn with each output of arg, which must be a string.
+func eachString(in interface{}, arg node, e *env, what string, fn func(string) (interface{}, error)) ([]interface{}, error) {
+	aa, err := arg.eval(in, e)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	for _, a := range aa {
+		if KindOf(a) != KindString {
+			return out, fmt.Errorf("%s must be a string, got %s", what, describe(a))
+		}
+		r, err := fn(asString(a))
+		if err != nil {
+			return out, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// mapASCII shifts the ASCII letters between lo and hi in the string in by delta.
+func mapASCII(in interface{}, lo, hi, delta rune) ([]interface{}, error) {
+	if KindOf(in) != KindString {
+		return nil, fmt.Errorf("cannot change the case of %s", describe(in))
+	}
+	s := strings.Map(func(r rune) rune {
+		if lo <= r && r <= hi {
+			return r + delta
+		}
+		return r
+	}, asString(in))
+	return []interface{}{s}, nil
+}
+
+// check reports an error if c calls an unknown function.
+func (c callNode) check() error {
+	if _, ok := builtins[fmt.Sprintf("%s/%d", c.name, len(c.args))]; !ok {
+		return fmt.Errorf("%s/%d is not defined", c.name, len(c.args))
+	}
+	return nil
+}
+
+func (c callNode) eval(in interface{}, e *env) ([]interface{}, error) {
+	return builtins[fmt.Sprintf("%s/%d", c.name, len(c.args))](in, c.args, e)
+}
+
+// length returns the length of v like jq: the number of characters of a string, elements of an array
+// or entries of an object, the absolute value of a number and 0 for null.
+func length(v interface{}) (interface{}, error) {
+	v = unwrap(v)
+	switch k := KindOf(v); k {
+	case KindNull:
+		return 0, nil
+	case KindNumber:
+		f, _ := number(v)
+		return math.Abs(f), nil
+	case KindString:
+		return utf8.RuneCountInString(asString(v)), nil
+	case KindArray, KindObject:
+		kk, _ := keys(v).([]interface{})
+		return len(kk), nil
+	}
+	return nil, fmt.Errorf("%s has no length", describe(v))
+}
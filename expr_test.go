@@ -0,0 +1,297 @@
+package jq
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExpr(t *testing.T) {
+	people := []interface{}{
+		map[string]interface{}{"name": "ann", "age": 41., "tags": []interface{}{"a", "b"}},
+		map[string]interface{}{"name": "bob", "age": 29., "tags": []interface{}{}},
+		map[string]interface{}{"name": "cid", "age": nil},
+	}
+	for _, tc := range []struct {
+		expr   string
+		root   interface{}
+		expect interface{} // the outputs, or ee
+	}{
+		{`.`, 1., []interface{}{1.}},
+		{`.foo`, testObj, []interface{}{1.}},
+		{`.subobj.subsubobj.array[0]`, testObj, []interface{}{"hello"}},
+		{`.subobj.subarray[-1]`, testObj, []interface{}{3.}},
+		{`.subobj.subarray[5]`, testObj, []interface{}{nil}},
+		{`.subobj["foo"]`, testObj, []interface{}{1.}},
+		{`."subobj"."foo"`, testObj, []interface{}{1.}},
+		{`.nosuchkey.deeper`, testObj, []interface{}{nil}},
+		{`.subobj.subarray[]`, testObj, []interface{}{1., 2., 3.}},
+		{`.subobj.subarray[1:]`, testObj, []interface{}{[]interface{}{2., 3.}}},
+		{`.subobj.subarray[:-1]`, testObj, []interface{}{[]interface{}{1., 2.}}},
+		{`.test[0:5]`, testObj, []interface{}{"Hello"}},
+		{`.subobj.subsubobj.array | length`, testObj, []interface{}{2}},
+		{`.test | length`, testObj, []interface{}{13}},
+		{`.subobj | keys`, testObj, []interface{}{[]interface{}{"foo", "subarray", "subsubobj"}}},
+		{`.[] | .name`, people, []interface{}{"ann", "bob", "cid"}},
+		{`.[].name`, people, []interface{}{"ann", "bob", "cid"}},
+		{`[.[] | select(.age > 30) | .name]`, people, []interface{}{[]interface{}{"ann"}}},
+		{`.[] | select(.age == null and .name != "ann") | .name`, people, []interface{}{"cid"}},
+		{`.[] | select(.age >= 29 or .name == "cid").name`, people, []interface{}{"ann", "bob", "cid"}},
+		{`map(.tags | length)`, people[:2], []interface{}{[]interface{}{2, 0}}},
+		{`.[0].name, .[1].age`, people, []interface{}{"ann", 29.}},
+		{`[.[].tags[]?]`, people, []interface{}{[]interface{}{"a", "b"}}},
+		{`.[2].tags[]?`, people, []interface{}(nil)},
+		{`[..] | length`, map[string]interface{}{"a": []interface{}{1, 2}}, []interface{}{4}},
+		{`.[] | .age | type`, people[1:], []interface{}{"number", "null"}},
+		{`true, false, null, "s", 1.5`, nil, []interface{}{true, false, nil, "s", 1.5}},
+		{`(1, 2) | not`, nil, []interface{}{false, false}},
+		{`[]`, nil, []interface{}{[]interface{}{}}},
+		{`empty`, nil, []interface{}(nil)},
+		{`.Subobj.Subarray[1]`, testStruct, []interface{}{2}},
+		{`.Array[] | keys[0]`, testStruct, []interface{}{"Foo", "Foo", "Foo"}},
+
+		{`.foo.bar`, testObj, ee},
+		{`.[]`, 3, ee},
+		{`.["a"]`, []interface{}{}, ee},
+		{`length`, true, ee},
+	} {
+		e, err := Compile(tc.expr)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		out, err := e.Run(tc.root)
+		if tc.expect == ee {
+			if err == nil {
+				t.Errorf("%s:  expected error, got %v", tc.expr, out)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, tc.expect) {
+			t.Errorf("%s:  expected %#v, got %#v", tc.expr, tc.expect, out)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`.foo |`,
+		`.[`,
+		`.foo]`,
+		`"unterminated`,
+		`nosuchfunction`,
+		`select`,
+		`(.a`,
+		`.a @ .b`,
+	} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("MustCompile: expected panic")
+		}
+	}()
+	MustCompile(`.[`)
+}
+
+func TestExprFirst(t *testing.T) {
+	e := MustCompile(`.subobj.subarray[]`)
+	if v, err := e.First(testObj); v != 1. || err != nil {
+		t.Errorf("First: got %v, %v", v, err)
+	}
+	if v, err := MustCompile(`empty`).First(testObj); v != nil || err != nil {
+		t.Errorf("First of empty: got %v, %v", v, err)
+	}
+	if e.String() != `.subobj.subarray[]` {
+		t.Errorf("String: got %q", e.String())
+	}
+}
+
+func TestExprArithmetic(t *testing.T) {
+	root := map[string]interface{}{
+		"first": "Ada", "last": "Lovelace", "born": 1815., "died": json.Number("1852"),
+		"tags": []interface{}{"math", "poetry", "math"},
+		"a":    map[string]interface{}{"x": 1., "o": map[string]interface{}{"p": 1.}},
+		"b":    map[string]interface{}{"y": 2., "o": map[string]interface{}{"q": 2.}},
+		"at":   time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+	}
+	for _, tc := range []struct {
+		expr   string
+		expect interface{}
+	}{
+		{`1 + 2 * 3`, []interface{}{7.}},
+		{`(1 + 2) * 3`, []interface{}{9.}},
+		{`10 / 4, 10 % 3, -5 % 3, 7 - 10`, []interface{}{2.5, 1., -2., -3.}},
+		{`.died - .born`, []interface{}{37.}},
+		{`.first + " " + .last`, []interface{}{"Ada Lovelace"}},
+		{`.first * 2`, []interface{}{"AdaAda"}},
+		{`null + 1, 1 + null`, []interface{}{1., 1.}},
+		{`[1, 2] + [3]`, []interface{}{[]interface{}{1., 2., 3.}}},
+		{`.tags - ["math"]`, []interface{}{[]interface{}{"poetry"}}},
+		{`.a + .b | keys`, []interface{}{[]interface{}{"o", "x", "y"}}},
+		{`(.a * .b).o`, []interface{}{map[string]interface{}{"p": 1., "q": 2.}}},
+		{`"a,b,c" / ","`, []interface{}{[]interface{}{"a", "b", "c"}}},
+		{`.tags | join(", ")`, []interface{}{"math, poetry, math"}},
+		{`[1, null, "x", true] | join("-")`, []interface{}{"1--x-true"}},
+		{`"a-b" | split("-")`, []interface{}{[]interface{}{"a", "b"}}},
+		{`"" | split("-")`, []interface{}{[]interface{}{}}},
+		{`.first | ascii_downcase, ascii_upcase`, []interface{}{"ada", "ADA"}},
+		{`.born | tostring`, []interface{}{"1815"}},
+		{`.tags | tostring`, []interface{}{`["math","poetry","math"]`}},
+		{`"12.5" | tonumber`, []interface{}{12.5}},
+		{`.died | tonumber`, []interface{}{1852.}},
+		{`[1, 2, 3] | add`, []interface{}{6.}},
+		{`.tags | add`, []interface{}{"mathpoetrymath"}},
+		{`[] | add`, []interface{}{nil}},
+		{`.tags | length`, []interface{}{3}},
+		{`{name: (.first + " " + .last), born, "age": (.died - .born)}`, []interface{}{map[string]interface{}{"name": "Ada Lovelace", "born": 1815., "age": 37.}}},
+		{`{(.first): .born}`, []interface{}{map[string]interface{}{"Ada": 1815.}}},
+		{`{a: (1, 2)} | .a`, []interface{}{1., 2.}},
+		{`{}`, []interface{}{map[string]interface{}{}}},
+		{`[.tags[] | {tag: .}] | length`, []interface{}{3}},
+		{`.at | tostring`, []interface{}{"2024-03-01T10:00:00Z"}},
+		{`.at | length`, []interface{}{20}},
+		{`"at " + .at`, []interface{}{"at 2024-03-01T10:00:00Z"}},
+
+		{`1 / 0`, ee},
+		{`"a" - "b"`, ee},
+		{`{} + []`, ee},
+		{`"x" | tonumber`, ee},
+		{`{(1): 2}`, ee},
+		{`1 | split(",")`, ee},
+		{`[{}] | join(",")`, ee},
+		{`"ab" * 0, "ab" * -1`, []interface{}{nil, nil}},
+		{`"ab" * 1.5`, []interface{}{"abab"}},
+		{`"" * 1e19`, []interface{}{""}},
+		{`"ab" * 1e19`, ee},
+		{`"ab" * 1e300`, ee},
+		{`"ab" * 5e18`, ee},
+		{`"ab" * 1e8`, ee},
+	} {
+		out, err := MustCompile(tc.expr).Run(root)
+		if tc.expect == ee {
+			if err == nil {
+				t.Errorf("%s:  expected error, got %v", tc.expr, out)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, tc.expect) {
+			t.Errorf("%s:  expected %#v, got %#v", tc.expr, tc.expect, out)
+		}
+	}
+	for _, expr := range []string{`{a:}`, `{1: 2}`, `{(.a)}`, `1 +`} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestExprConditionals(t *testing.T) {
+	root := map[string]interface{}{"name": "ann", "nick": nil, "age": 41., "ok": false, "items": []interface{}{1., 5., 10.}}
+	for _, tc := range []struct {
+		expr   string
+		expect []interface{}
+	}{
+		{`.nick // .name`, []interface{}{"ann"}},
+		{`.name // "anonymous"`, []interface{}{"ann"}},
+		{`.ok // "default"`, []interface{}{"default"}},
+		{`.missing // .nick // "x"`, []interface{}{"x"}},
+		{`.name.first // "not an object"`, []interface{}{"not an object"}},
+		{`(.nick, .name, .age) // 0`, []interface{}{"ann", 41.}},
+		{`empty // 1`, []interface{}{1.}},
+		{`.nick // empty`, nil},
+		{`1, null // 2`, []interface{}{1., 2.}},
+		{`{n: .nick // "-"}`, []interface{}{map[string]interface{}{"n": "-"}}},
+		{`if .age > 40 then "senior" else "junior" end`, []interface{}{"senior"}},
+		{`if .ok then 1 end`, []interface{}{root}},
+		{`.items[] | if . < 2 then "small" elif . < 6 then "medium" else "large" end`, []interface{}{"small", "medium", "large"}},
+		{`if (true, false) then "a" else "b" end`, []interface{}{"a", "b"}},
+		{`[.items[] | if . > 4 then . * 2 else empty end]`, []interface{}{[]interface{}{10., 20.}}},
+		{`if .nick then .nick else .name | ascii_upcase end`, []interface{}{"ANN"}},
+	} {
+		out, err := MustCompile(tc.expr).Run(root)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, tc.expect) {
+			t.Errorf("%s:  expected %#v, got %#v", tc.expr, tc.expect, out)
+		}
+	}
+	for _, expr := range []string{`if . end`, `if . then 1`, `if . then 1 else 2`, `1 //`, `if . then 1 elif 2 end`} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestExprVariables(t *testing.T) {
+	root := map[string]interface{}{
+		"threshold": 30.,
+		"people": []interface{}{
+			map[string]interface{}{"name": "ann", "age": 41.},
+			map[string]interface{}{"name": "bob", "age": 29.},
+		},
+	}
+	for _, tc := range []struct {
+		expr   string
+		args   map[string]interface{}
+		expect []interface{}
+	}{
+		{`.threshold as $t | [.people[] | select(.age > $t) | .name]`, nil, []interface{}{[]interface{}{"ann"}}},
+		{`.people[] as $p | $p.name + ":" + ($p.age | tostring)`, nil, []interface{}{"ann:41", "bob:29"}},
+		{`.people[0].name as $n | .people[1].name as $m | [$n, $m]`, nil, []interface{}{[]interface{}{"ann", "bob"}}},
+		{`1 as $x | 2 as $x | $x`, nil, []interface{}{2.}},
+		{`(1, 2) as $x | $x * 10`, nil, []interface{}{10., 20.}},
+		{`.people[0] as $p | {$p}`, nil, []interface{}{map[string]interface{}{"p": map[string]interface{}{"name": "ann", "age": 41.}}}},
+		{`[.people[] | select(.age >= $min) | .name]`, map[string]interface{}{"min": 40}, []interface{}{[]interface{}{"ann"}}},
+		{`$greeting + ", " + .people[1].name`, map[string]interface{}{"greeting": "hi"}, []interface{}{"hi, bob"}},
+		{`{$user, n: $n}`, map[string]interface{}{"user": "x", "n": 1}, []interface{}{map[string]interface{}{"user": "x", "n": 1}}},
+		{`. as $root | .people[] | $root.threshold - .age`, nil, []interface{}{-11., 1.}},
+	} {
+		e, err := CompileWithArgs(tc.expr, tc.args)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		out, err := e.Run(root)
+		if err != nil {
+			t.Errorf("%s: %v", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(out, tc.expect) {
+			t.Errorf("%s:  expected %#v, got %#v", tc.expr, tc.expect, out)
+		}
+	}
+	for _, expr := range []string{`$x`, `1 as $x | $y`, `(1 as $x | $x), $x`, `1 as x | .`, `1 as $x`, `{$nope}`} {
+		if _, err := Compile(expr); err == nil {
+			t.Errorf("%q: expected error", expr)
+		}
+	}
+}
+
+func TestExprBindingPrecedence(t *testing.T) {
+	for expr, expect := range map[string][]interface{}{
+		`1, 2 as $x | $x * 10`:      {1., 20.},
+		`1 + (2 as $x | $x * 10)`:   {21.},
+		`1 + 2 as $x | $x * 10`:     {21.},
+		`[3 as $x | $x, $x + 1], 0`: {[]interface{}{3., 4.}, 0.},
+	} {
+		out, err := MustCompile(expr).Run(nil)
+		if err != nil || !reflect.DeepEqual(out, expect) {
+			t.Errorf("%s: expected %v, got %v, %v", expr, expect, out, err)
+		}
+	}
+}
@@ -0,0 +1,586 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies the tokens of an expression.
+type tokenKind int
+
+const (
+	tEOF   tokenKind = iota
+	tIdent           // names of functions and keywords
+	tField           // .name or ."name"
+	tVar             // $name
+	tNum
+	tStr
+	tOp // punctuation and operators
+)
+
+type token struct {
+	kind tokenKind
+	text string      // the name, operator, or source text of a literal
+	val  interface{} // the value of a number or string literal
+	pos  int
+}
+
+// operators lists the operator tokens, longer ones before their prefixes.
+var operators = []string{"..", "==", "!=", "<=", ">=", "//", ".", "[", "]", "(", ")", "{", "}", "|", ",", ":", ";", "?", "<", ">", "+", "-", "*", "/", "%"}
+
+// lex splits src into tokens.
+func lex(src string) ([]token, error) {
+	var tt []token
+	isIdent := func(r byte, first bool) bool {
+		return r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || !first && '0' <= r && r <= '9'
+	}
+	ident := func(i int) int {
+		for i < len(src) && isIdent(src[i], false) {
+			i++
+		}
+		return i
+	}
+	str := func(i int) (string, int, error) {
+		j := i + 1
+		for ; j < len(src) && src[j] != '"'; j++ {
+			if src[j] == '\\' {
+				j++
+			}
+		}
+		if j >= len(src) {
+			return "", 0, fmt.Errorf("unterminated string at %d", i)
+		}
+		var s string
+		if err := json.Unmarshal([]byte(src[i:j+1]), &s); err != nil {
+			return "", 0, fmt.Errorf("invalid string at %d: %v", i, err)
+		}
+		return s, j + 1, nil
+	}
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '.' && i+1 < len(src) && isIdent(src[i+1], true):
+			j := ident(i + 1)
+			tt = append(tt, token{kind: tField, text: src[i+1 : j], pos: i})
+			i = j
+		case c == '.' && i+1 < len(src) && src[i+1] == '"':
+			s, j, err := str(i + 1)
+			if err != nil {
+				return nil, err
+			}
+			tt = append(tt, token{kind: tField, text: s, pos: i})
+			i = j
+		case c == '$' && i+1 < len(src) && isIdent(src[i+1], true):
+			j := ident(i + 1)
+			tt = append(tt, token{kind: tVar, text: src[i+1 : j], pos: i})
+			i = j
+		case isIdent(c, true):
+			j := ident(i)
+			tt = append(tt, token{kind: tIdent, text: src[i:j], pos: i})
+			i = j
+		case '0' <= c && c <= '9':
+			j := i
+			for j < len(src) && ('0' <= src[j] && src[j] <= '9' || src[j] == '.' ||
+				src[j] == 'e' || src[j] == 'E' || (src[j] == '+' || src[j] == '-') && (src[j-1] == 'e' || src[j-1] == 'E')) {
+				j++
+			}
+			f, err := strconv.ParseFloat(src[i:j], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at %d", src[i:j], i)
+			}
+			tt = append(tt, token{kind: tNum, text: src[i:j], val: f, pos: i})
+			i = j
+		case c == '"':
+			s, j, err := str(i)
+			if err != nil {
+				return nil, err
+			}
+			tt = append(tt, token{kind: tStr, text: src[i:j], val: s, pos: i})
+			i = j
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+			}
+			tt = append(tt, token{kind: tOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tt, token{kind: tEOF, pos: len(src)}), nil
+}
+
+// parser builds the syntax tree of an expression by recursive descent.
+// The precedence of operators follows jq, from lowest to highest:
+// '|', ',', '//', 'or', 'and', comparisons, '+' and '-', '*', '/' and '%', and postfix terms.
+type parser struct {
+	toks []token
+	pos  int
+	vars []string // variables in scope, innermost last
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isOp reports whether the next token is the operator op.
+func (p *parser) isOp(op string) bool {
+	t := p.peek()
+	return t.kind == tOp && t.text == op
+}
+
+// isKeyword reports whether the next token is the keyword kw.
+func (p *parser) isKeyword(kw string) bool {
+	t := p.peek()
+	return t.kind == tIdent && t.text == kw
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	near := t.text
+	if t.kind == tEOF {
+		near = "end of expression"
+	}
+	return fmt.Errorf("syntax error at %d near %q: %s", t.pos, near, fmt.Sprintf(format, args...))
+}
+
+// expect consumes the operator op or fails.
+func (p *parser) expect(op string) error {
+	if !p.isOp(op) {
+		return p.errorf("expected %q", op)
+	}
+	p.next()
+	return nil
+}
+
+// parse parses a complete expression.
+func (p *parser) parse() (node, error) {
+	n, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tEOF {
+		return nil, p.errorf("unexpected token")
+	}
+	return n, nil
+}
+
+func (p *parser) parsePipe() (node, error) {
+	l, err := p.parseComma()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("|") {
+		p.next()
+		r, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return pipeNode{l, r}, nil
+	}
+	return l, nil
+}
+
+// parseBinding parses the rest of source as $name | body.
+func (p *parser) parseBinding(source node) (node, error) {
+	p.next()
+	t := p.next()
+	if t.kind != tVar {
+		if t.kind != tEOF {
+			p.pos--
+		}
+		return nil, p.errorf("expected $name")
+	}
+	if err := p.expect("|"); err != nil {
+		return nil, err
+	}
+	p.vars = append(p.vars, t.text)
+	body, err := p.parsePipe()
+	p.vars = p.vars[:len(p.vars)-1]
+	if err != nil {
+		return nil, err
+	}
+	return bindNode{name: t.text, source: source, body: body}, nil
+}
+
+func (p *parser) parseComma() (node, error) {
+	l, err := p.parseAlt()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp(",") {
+		p.next()
+		r, err := p.parseAlt()
+		if err != nil {
+			return nil, err
+		}
+		l = commaNode{l, r}
+	}
+	return l, nil
+}
+
+// parseAlt parses the alternative operator, which is right associative.
+func (p *parser) parseAlt() (node, error) {
+	l, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("//") {
+		p.next()
+		r, err := p.parseAlt()
+		if err != nil {
+			return nil, err
+		}
+		return altNode{l, r}, nil
+	}
+	return l, nil
+}
+
+func (p *parser) parseOr() (node, error) {
+	l, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		p.next()
+		r, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l = logicNode{and: false, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	l, err := p.parseCompare()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		p.next()
+		r, err := p.parseCompare()
+		if err != nil {
+			return nil, err
+		}
+		l = logicNode{and: true, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *parser) parseCompare() (node, error) {
+	l, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<", "<=", ">", ">="} {
+		if p.isOp(op) {
+			p.next()
+			r, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			return binaryNode{op, l, r}, nil
+		}
+	}
+	return l, nil
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	return p.parseBinary(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	return p.parseBinary(p.parseTerm, "*", "/", "%")
+}
+
+// parseBinary parses a left associative sequence of operands separated by any of ops.
+func (p *parser) parseBinary(operand func() (node, error), ops ...string) (node, error) {
+	l, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ""
+		for _, o := range ops {
+			if p.isOp(o) {
+				op = o
+			}
+		}
+		if op == "" {
+			return l, nil
+		}
+		p.next()
+		r, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		l = binaryNode{op, l, r}
+	}
+}
+
+// parseTerm parses a primary expression followed by any number of suffixes:
+// .name, [index], [from:to], [] and ?, and possibly a binding with as.
+func (p *parser) parseTerm() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch t := p.peek(); {
+		case t.kind == tField:
+			p.next()
+			n = indexNode{n, literalNode{t.text}}
+		case p.isOp("["):
+			p.next()
+			if n, err = p.parseBracket(n); err != nil {
+				return nil, err
+			}
+		case p.isOp("?"):
+			p.next()
+			n = tryNode{n}
+		case p.isOp(".") && p.toks[p.pos+1].kind == tOp && p.toks[p.pos+1].text == "[":
+			p.next() // .[ after a term, as in .foo.[0]
+		case p.isKeyword("as"):
+			// the body extends as far as possible, so 1, 2 as $x | body is 1, (2 as $x | body)
+			return p.parseBinding(n)
+		default:
+			return n, nil
+		}
+	}
+}
+
+// parseBracket parses the rest of [], [index] or [from:to] applied to target.
+func (p *parser) parseBracket(target node) (node, error) {
+	if p.isOp("]") {
+		p.next()
+		return iterateNode{target}, nil
+	}
+	var from, to node
+	var err error
+	if !p.isOp(":") {
+		if from, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+		if p.isOp("]") {
+			p.next()
+			return indexNode{target, from}, nil
+		}
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	if !p.isOp("]") {
+		if to, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return sliceNode{target, from, to}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tField:
+		return indexNode{identityNode{}, literalNode{t.text}}, nil
+	case tNum, tStr:
+		return literalNode{t.val}, nil
+	case tVar:
+		if !p.defined(t.text) {
+			p.pos--
+			return nil, p.errorf("$%s is not defined", t.text)
+		}
+		return varNode{t.text}, nil
+	case tIdent:
+		switch t.text {
+		case "true":
+			return literalNode{true}, nil
+		case "false":
+			return literalNode{false}, nil
+		case "null":
+			return literalNode{nil}, nil
+		case "if":
+			return p.parseIf()
+		}
+		return p.parseCall(t.text)
+	case tOp:
+		switch t.text {
+		case ".":
+			return identityNode{}, nil
+		case "..":
+			return callNode{name: "recurse"}, nil
+		case "-":
+			n, err := p.parseTerm()
+			if err != nil {
+				return nil, err
+			}
+			return negNode{n}, nil
+		case "(":
+			n, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			if p.isOp("]") {
+				p.next()
+				return arrayNode{}, nil
+			}
+			n, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			return arrayNode{n}, p.expect("]")
+		case "{":
+			return p.parseObject()
+		}
+	}
+	if t.kind != tEOF {
+		p.pos--
+	}
+	return nil, p.errorf("unexpected token")
+}
+
+// parseCall parses the arguments, if any, of a call to the function name.
+func (p *parser) parseCall(name string) (node, error) {
+	c := callNode{name: name}
+	if !p.isOp("(") {
+		return c, c.check()
+	}
+	p.next()
+	for {
+		a, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, a)
+		if p.isOp(";") {
+			p.next()
+			continue
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return c, c.check()
+	}
+}
+
+// parseObject parses the entries of an object construction after the opening brace.
+func (p *parser) parseObject() (node, error) {
+	var o objectNode
+	for !p.isOp("}") {
+		var entry objectEntry
+		switch t := p.next(); {
+		case t.kind == tIdent:
+			entry.key = literalNode{t.text}
+		case t.kind == tStr:
+			entry.key = literalNode{t.val}
+		case t.kind == tVar:
+			if !p.defined(t.text) {
+				p.pos--
+				return nil, p.errorf("$%s is not defined", t.text)
+			}
+			entry.key, entry.value = literalNode{t.text}, varNode{t.text} // {$x} is short for {x: $x}
+		case t.kind == tOp && t.text == "(":
+			k, err := p.parsePipe()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			entry.key = k
+		default:
+			if t.kind != tEOF {
+				p.pos--
+			}
+			return nil, p.errorf("expected object key")
+		}
+		if entry.value != nil {
+			// already set by {$x}
+		} else if p.isOp(":") {
+			p.next()
+			v, err := p.parseAlt()
+			if err != nil {
+				return nil, err
+			}
+			entry.value = v
+		} else if lit, ok := entry.key.(literalNode); ok {
+			entry.value = indexNode{identityNode{}, lit} // {a} is short for {a: .a}
+		} else {
+			return nil, p.errorf("expected \":\"")
+		}
+		o.entries = append(o.entries, entry)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	return o, p.expect("}")
+}
+
+// parseIf parses the rest of if c then a elif c then a else b end, where elif and else are optional.
+func (p *parser) parseIf() (node, error) {
+	cond, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isKeyword("then") {
+		return nil, p.errorf("expected then")
+	}
+	p.next()
+	n := ifNode{cond: cond, otherwise: identityNode{}}
+	if n.then, err = p.parsePipe(); err != nil {
+		return nil, err
+	}
+	switch {
+	case p.isKeyword("elif"):
+		p.next()
+		n.otherwise, err = p.parseIf()
+		return n, err
+	case p.isKeyword("else"):
+		p.next()
+		if n.otherwise, err = p.parsePipe(); err != nil {
+			return nil, err
+		}
+	}
+	if !p.isKeyword("end") {
+		return nil, p.errorf("expected end")
+	}
+	p.next()
+	return n, nil
+}
+
+// defined reports whether the variable name is in scope.
+func (p *parser) defined(name string) bool {
+	for _, v := range p.vars {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
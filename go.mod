@@ -0,0 +1,3 @@
+module github.com/gtrevg/go-jq
+
+go 1.25.0
@@ -0,0 +1,32 @@
+package jq
+
+import (
+	"bufio"
+	"crypto/sha256"
+)
+
+// Hash returns the SHA-256 hash of a canonical encoding of v, which is the same for values
+// that are equal according to Equal, regardless of the order of their map keys and of the
+// types of their numbers, for change detection and cache keys. Times are encoded by the instant
+// they stand for, and integers exactly, however large. The encoding is streamed into
+// the hash, so v is not marshaled. Hashes are the same in every process, so they can be stored,
+// except for values that are not JSON-like, such as channels, which are hashed by their printed form.
+func Hash(v interface{}) [32]byte {
+	h := sha256.New()
+	w := bufio.NewWriter(h)
+	writeCanonical(w, v)
+	w.Flush()
+	var sum [32]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+// HashAt returns the Hash of the value at the slash separated path in root. It returns
+// an error wrapping ErrNotFound if the value is not present.
+func HashAt(root interface{}, path string) ([32]byte, error) {
+	v, err := resolve(root, path)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return Hash(v), nil
+}
@@ -0,0 +1,61 @@
+package jq
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHash(t *testing.T) {
+	a := map[string]interface{}{"x": 1, "y": []interface{}{"a", true, nil}, "z": map[string]interface{}{"n": 2.5}}
+	b := map[string]interface{}{"z": map[string]interface{}{"n": json.Number("2.5")}, "y": []interface{}{"a", true, nil}, "x": 1.0}
+	if Hash(a) != Hash(b) {
+		t.Errorf("equal documents have different hashes")
+	}
+	type point struct{ X, Y int }
+	if Hash(point{1, 2}) != Hash(map[string]interface{}{"X": 1, "Y": 2}) {
+		t.Errorf("struct and equal map have different hashes")
+	}
+
+	for _, c := range []interface{}{
+		map[string]interface{}{"x": 2, "y": []interface{}{"a", true, nil}, "z": map[string]interface{}{"n": 2.5}},
+		map[string]interface{}{"x": 1, "y": []interface{}{true, "a", nil}, "z": map[string]interface{}{"n": 2.5}},
+		map[string]interface{}{"x": 1, "y": []interface{}{"a", true}, "z": map[string]interface{}{"n": 2.5}},
+		map[string]interface{}{"x": "1", "y": []interface{}{"a", true, nil}, "z": map[string]interface{}{"n": 2.5}},
+	} {
+		if Hash(a) == Hash(c) {
+			t.Errorf("%v: same hash as %v", c, a)
+		}
+	}
+	if Hash([]interface{}{"a,b"}) == Hash([]interface{}{"a", "b"}) {
+		t.Errorf("ambiguous encoding of strings")
+	}
+	for _, tc := range []struct {
+		a, b interface{}
+		same bool
+	}{
+		{time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{time.Unix(0, 1), time.Unix(0, 2), false},
+		{time.Unix(1e9, 0).UTC(), time.Unix(1e9, 0).In(time.FixedZone("X", -3600)), true},
+		{int64(9007199254740993), int64(9007199254740992), false},
+		{uint64(18446744073709551615), uint64(18446744073709551614), false},
+		{int64(9007199254740993), json.Number("9007199254740993"), true},
+		{int64(1 << 60), float64(1 << 60), true},
+	} {
+		if same := Hash(tc.a) == Hash(tc.b); same != tc.same {
+			t.Errorf("Hash(%v) == Hash(%v): expected %v", tc.a, tc.b, tc.same)
+		}
+	}
+
+	h, err := HashAt(a, "z")
+	if err != nil || h != Hash(map[string]interface{}{"n": 2.5}) {
+		t.Errorf("HashAt: got %x, %v", h, err)
+	}
+	if _, err := HashAt(a, "nosuchkey"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("HashAt of missing value: got %v", err)
+	}
+	if h, err := HashAt(map[string]interface{}{"n": nil}, "n"); err != nil || h != Hash(nil) {
+		t.Errorf("HashAt of null: got %x, %v", h, err)
+	}
+}
@@ -0,0 +1,118 @@
+package jq
+
+import "context"
+
+// A Hook observes the steps of a query, and can replace the values it reaches.
+// Paths passed to a Hook hold the index elements that led to the value, with
+// elements selected by ALL replaced by their keys, and must not be retained.
+type Hook interface {
+	// Before is called before index element i is resolved in value, found at path.
+	Before(path []interface{}, value interface{}, i interface{})
+	// After is called with each value the query reaches, including the root at the empty path,
+	// and returns the value to continue with, so it can, for example, decrypt sealed fields.
+	After(path []interface{}, value interface{}) interface{}
+	// Error is called once for each error, at the path of the value the error occurred in.
+	Error(path []interface{}, err error)
+}
+
+// A ContextHook is a Hook that needs the context of the queries it observes, for example
+// to trace them. Queries run with QCtx, QChan or a Result bound with Context call WithContext
+// once before they start, and call the Hook it returns instead.
+type ContextHook interface {
+	Hook
+	WithContext(ctx context.Context) Hook
+}
+
+// HookFuncs implements Hook with optional functions.
+type HookFuncs struct {
+	BeforeFunc func(path []interface{}, value interface{}, i interface{})
+	AfterFunc  func(path []interface{}, value interface{}) interface{}
+	ErrorFunc  func(path []interface{}, err error)
+}
+
+// Before calls h.BeforeFunc if it is set.
+func (h HookFuncs) Before(path []interface{}, value interface{}, i interface{}) {
+	if h.BeforeFunc != nil {
+		h.BeforeFunc(path, value, i)
+	}
+}
+
+// After calls h.AfterFunc if it is set and otherwise returns value.
+func (h HookFuncs) After(path []interface{}, value interface{}) interface{} {
+	if h.AfterFunc != nil {
+		return h.AfterFunc(path, value)
+	}
+	return value
+}
+
+// Error calls h.ErrorFunc if it is set.
+func (h HookFuncs) Error(path []interface{}, err error) {
+	if h.ErrorFunc != nil {
+		h.ErrorFunc(path, err)
+	}
+}
+
+// WithHook adds h to the hooks called during a query. Hooks are called in the order they were added.
+func WithHook(h Hook) Option {
+	return func(c *config) { c.hooks = append(c.hooks, h) }
+}
+
+// boundHooks returns the hooks of c, with context hooks bound to the context of c.
+func (c *config) boundHooks() []Hook {
+	if c.ctx == nil {
+		return c.hooks
+	}
+	var hh []Hook
+	for i, h := range c.hooks {
+		ch, ok := h.(ContextHook)
+		if !ok {
+			continue
+		}
+		if hh == nil {
+			hh = append(make([]Hook, 0, len(c.hooks)), c.hooks...)
+		}
+		hh[i] = ch.WithContext(c.ctx)
+	}
+	if hh == nil {
+		return c.hooks
+	}
+	return hh
+}
+
+// hookState is shared by the steps of a query with hooks.
+type hookState struct {
+	reported error // the last error passed to the hooks, not to report it again as it is returned up the path
+}
+
+// q resolves index in root, calling the hooks in c if there are any.
+func (c *config) q(root interface{}, index []interface{}) interface{} {
+	if len(c.hooks) == 0 {
+		return c.step(root, index)
+	}
+	if len(index) > 0 {
+		for _, h := range c.hooks {
+			h.Before(c.path, root, index[0])
+		}
+	}
+	r := c.step(root, index)
+	if err, ok := r.(error); ok && err != c.hs.reported {
+		c.hs.reported = err
+		for _, h := range c.hooks {
+			h.Error(c.path, err)
+		}
+	}
+	return r
+}
+
+// next resolves index in v, found under key, calling the hooks in c if there are any.
+func (c *config) next(v, key interface{}, index []interface{}) interface{} {
+	if len(c.hooks) == 0 {
+		return c.step(v, index)
+	}
+	cc := *c
+	cc.path = append(c.path[:len(c.path):len(c.path)], key)
+	for _, h := range c.hooks {
+		v = h.After(cc.path, v)
+	}
+	return cc.q(v, index)
+}
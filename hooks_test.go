@@ -0,0 +1,79 @@
+package jq
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHooks(t *testing.T) {
+	root := map[string]interface{}{
+		"user": map[string]interface{}{
+			"name":   "ann",
+			"secret": "sealed:drowssap",
+		},
+		"list": []interface{}{1, 2},
+	}
+
+	var trace []string
+	var errs []string
+	tracer := HookFuncs{
+		BeforeFunc: func(path []interface{}, _ interface{}, i interface{}) {
+			trace = append(trace, fmt.Sprintf("%v<-%v", path, i))
+		},
+		ErrorFunc: func(path []interface{}, err error) {
+			errs = append(errs, fmt.Sprintf("%v: %v", path, err))
+		},
+	}
+	unseal := HookFuncs{
+		AfterFunc: func(path []interface{}, v interface{}) interface{} {
+			if s, ok := v.(string); ok && strings.HasPrefix(s, "sealed:") {
+				r := []rune(strings.TrimPrefix(s, "sealed:"))
+				for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+					r[i], r[j] = r[j], r[i]
+				}
+				return string(r)
+			}
+			return v
+		},
+	}
+	e := NewEngine(WithHook(tracer), WithHook(unseal))
+
+	if v := e.Q(root, "user", "secret"); v != "password" {
+		t.Errorf("After: expected password, got %v", v)
+	}
+	if v := Q(root, "user", "secret"); v != "sealed:drowssap" {
+		t.Errorf("package-level Q: got %v", v)
+	}
+	if expect := []string{"[]<-user", "[user]<-secret"}; !reflect.DeepEqual(trace, expect) {
+		t.Errorf("Before: expected %q, got %q", expect, trace)
+	}
+
+	trace = nil
+	if v := e.Q(root, "list", ALL); !reflect.DeepEqual(v, []interface{}{1, 2}) {
+		t.Errorf("ALL: got %v", v)
+	}
+	if expect := []string{"[]<-list", "[list]<-ALL"}; !reflect.DeepEqual(trace, expect) {
+		t.Errorf("Before ALL: expected %q, got %q", expect, trace)
+	}
+
+	var paths []string
+	e = NewEngine(WithHook(HookFuncs{AfterFunc: func(path []interface{}, v interface{}) interface{} {
+		paths = append(paths, fmt.Sprint(path))
+		return v
+	}}))
+	e.Q(root, "list", ALL)
+	if expect := []string{"[]", "[list]", "[list 0]", "[list 1]"}; !reflect.DeepEqual(paths, expect) {
+		t.Errorf("After ALL: expected %q, got %q", expect, paths)
+	}
+
+	trace = nil
+	e = NewEngine(WithHook(tracer))
+	if v := e.Q(root, "user", "name", "x", "y"); !isError(v) {
+		t.Errorf("expected error, got %v", v)
+	}
+	if len(errs) != 1 || !strings.HasPrefix(errs[0], "[user name]: ") {
+		t.Errorf("Error: expected one error at [user name], got %q", errs)
+	}
+}
@@ -0,0 +1,136 @@
+package jq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// ErrRange is returned, wrapped, by the checked integer getters such as Int64E when a number
+// cannot be represented exactly in the requested type, because it is too large or has a fraction.
+var ErrRange = errors.New("value out of range")
+
+// Int64E returns the integer at index in root. Unlike Int, it does not truncate or wrap
+// around: it returns an error wrapping ErrRange if the value is a number with a fractional
+// part or outside the range of int64, an error wrapping ErrNotFound if the value is not present,
+// and an error if it is not a number. json.Number values are converted exactly, even beyond
+// the precision of float64, so they are safe for large IDs.
+func Int64E(root interface{}, index ...interface{}) (int64, error) {
+	n, err := integerAt(root, index)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("%s: %v does not fit in int64: %w", pathString(index), n, ErrRange)
+	}
+	return n.Int64(), nil
+}
+
+// UintE is like Int64E for unsigned integers: it also returns an error wrapping ErrRange
+// for negative numbers and numbers outside the range of uint.
+func UintE(root interface{}, index ...interface{}) (uint, error) {
+	n, err := integerAt(root, index)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsUint64() || n.Uint64() > math.MaxUint {
+		return 0, fmt.Errorf("%s: %v does not fit in uint: %w", pathString(index), n, ErrRange)
+	}
+	return uint(n.Uint64()), nil
+}
+
+// Uint64E is like UintE for uint64.
+func Uint64E(root interface{}, index ...interface{}) (uint64, error) {
+	n, err := integerAt(root, index)
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsUint64() {
+		return 0, fmt.Errorf("%s: %v does not fit in uint64: %w", pathString(index), n, ErrRange)
+	}
+	return n.Uint64(), nil
+}
+
+// Uint returns the unsigned integer found at path or 0 in all other cases, including for
+// negative numbers, which are never wrapped around to large values, and for numbers with
+// a fraction or too large for uint. Unlike Int, it also converts floats without a fraction.
+func Uint(root interface{}, index ...interface{}) uint {
+	n, _ := asUint(Q(root, index...))
+	return n
+}
+
+// UintOK is like Uint, but also reports whether the value is a number that fits in uint.
+func UintOK(root interface{}, index ...interface{}) (uint, bool) {
+	return asUint(Q(root, index...))
+}
+
+func asUint(v interface{}) (uint, bool) {
+	n, err := integer(v)
+	if err != nil || !n.IsUint64() || n.Uint64() > math.MaxUint {
+		return 0, false
+	}
+	return uint(n.Uint64()), true
+}
+
+// integerAt returns the exact integer value of the number at index in root.
+func integerAt(root interface{}, index []interface{}) (*big.Int, error) {
+	v, ok := lookup(root, index)
+	if !ok {
+		if err, isErr := Q(root, index...).(error); isErr {
+			return nil, fmt.Errorf("%s: %v", pathString(index), err)
+		}
+		return nil, fmt.Errorf("%s: %w", pathString(index), ErrNotFound)
+	}
+	n, err := integer(v)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", pathString(index), err)
+	}
+	return n, nil
+}
+
+// maxInteger is 2^64, the magnitude from which no integer fits in the types of the getters.
+var maxInteger = new(big.Float).SetMantExp(big.NewFloat(1), 64)
+
+// integer converts the number v to an integer, failing with ErrRange if it has a fraction.
+func integer(v interface{}) (*big.Int, error) {
+	var r *big.Rat
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%v is not an integer: %w", f, ErrRange)
+		}
+		r = new(big.Rat).SetFloat64(f)
+	}
+	if n, ok := v.(json.Number); ok {
+		// check the magnitude first: big.Rat expands exponents such as 1e999999 exactly
+		f, _, err := big.ParseFloat(string(n), 10, 64, big.ToNearestEven)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", string(n))
+		}
+		if f.IsInf() || new(big.Float).Abs(f).Cmp(maxInteger) >= 0 {
+			return nil, fmt.Errorf("%q does not fit in 64 bits: %w", string(n), ErrRange)
+		}
+		if f.Sign() != 0 && new(big.Float).Abs(f).Cmp(big.NewFloat(1)) < 0 {
+			return nil, fmt.Errorf("%q is not an integer: %w", string(n), ErrRange)
+		}
+		var valid bool
+		if r, valid = new(big.Rat).SetString(string(n)); !valid {
+			return nil, fmt.Errorf("invalid number %q", string(n))
+		}
+	}
+	if r == nil {
+		return nil, fmt.Errorf("cannot use %v (type %T) as integer", v, v)
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%s is not an integer: %w", r.FloatString(3), ErrRange)
+	}
+	return r.Num(), nil
+}
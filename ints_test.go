@@ -0,0 +1,147 @@
+package jq
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+var numbers = map[string]interface{}{
+	"small":    json.Number("42"),
+	"big":      json.Number("9007199254740993"),
+	"max":      json.Number("9223372036854775807"),
+	"over":     json.Number("9223372036854775808"),
+	"maxuint":  json.Number("18446744073709551615"),
+	"exp":      json.Number("1e3"),
+	"frac":     json.Number("1.5"),
+	"fracbig":  json.Number("9007199254740993.5"),
+	"invalid":  json.Number("x"),
+	"huge":     json.Number("1e999999"),
+	"tiny":     json.Number("1e-999999"),
+	"negmax":   json.Number("-9223372036854775808"),
+	"float":    2.0,
+	"floatf":   2.5,
+	"floatbig": 1e19,
+	"nan":      math.NaN(),
+	"neg":      -3,
+	"u64":      uint64(math.MaxUint64),
+	"string":   "42",
+	"null":     nil,
+}
+
+func TestInt64E(t *testing.T) {
+	for _, tc := range []struct {
+		key    string
+		expect int64
+		err    error
+	}{
+		{"small", 42, nil},
+		{"big", 9007199254740993, nil},
+		{"max", math.MaxInt64, nil},
+		{"over", 0, ErrRange},
+		{"exp", 1000, nil},
+		{"frac", 0, ErrRange},
+		{"fracbig", 0, ErrRange},
+		{"invalid", 0, ee},
+		{"float", 2, nil},
+		{"floatf", 0, ErrRange},
+		{"floatbig", 0, ErrRange},
+		{"nan", 0, ErrRange},
+		{"neg", -3, nil},
+		{"u64", 0, ErrRange},
+		{"huge", 0, ErrRange},
+		{"tiny", 0, ErrRange},
+		{"negmax", math.MinInt64, nil},
+		{"string", 0, ee},
+		{"null", 0, ee},
+		{"nosuchkey", 0, ErrNotFound},
+		{"small/x", 0, ee},
+	} {
+		n, err := Int64E(numbers, split(tc.key)...)
+		checkIntErr(t, "Int64E", tc.key, n == tc.expect, n, err, tc.err)
+	}
+}
+
+func TestUintE(t *testing.T) {
+	for _, tc := range []struct {
+		key    string
+		expect uint
+		err    error
+	}{
+		{"small", 42, nil},
+		{"maxuint", math.MaxUint, nil},
+		{"u64", math.MaxUint, nil},
+		{"floatbig", 1e19, nil},
+		{"neg", 0, ErrRange},
+		{"frac", 0, ErrRange},
+		{"huge", 0, ErrRange},
+		{"string", 0, ee},
+		{"nosuchkey", 0, ErrNotFound},
+	} {
+		n, err := UintE(numbers, tc.key)
+		checkIntErr(t, "UintE", tc.key, n == tc.expect, n, err, tc.err)
+	}
+	for _, key := range []string{"huge", "tiny"} {
+		if _, err := Uint64E(numbers, key); err == nil || len(err.Error()) > 100 || !strings.Contains(err.Error(), string(numbers[key].(json.Number))) {
+			t.Errorf("Uint64E(%q): expected a short error quoting the number, got %.100v", key, err)
+		}
+	}
+}
+
+// checkIntErr reports a result of a checked integer getter that is not the expected one.
+// An expected error of ee accepts any error.
+func checkIntErr(t *testing.T, name, key string, ok bool, n interface{}, err, expect error) {
+	t.Helper()
+	switch {
+	case expect == nil && (err != nil || !ok):
+		t.Errorf("%s(%q): got %v, %v", name, key, n, err)
+	case expect == ee && err == nil:
+		t.Errorf("%s(%q): expected error, got %v", name, key, n)
+	case expect != nil && expect != ee && !errors.Is(err, expect):
+		t.Errorf("%s(%q): expected %v, got %v, %v", name, key, expect, n, err)
+	}
+}
+
+func TestUint(t *testing.T) {
+	for _, tc := range []struct {
+		key    string
+		expect uint
+		ok     bool
+	}{
+		{"small", 42, true},
+		{"float", 2, true},
+		{"u64", math.MaxUint, true},
+		{"neg", 0, false},
+		{"frac", 0, false},
+		{"over", 1 << 63, true},
+		{"string", 0, false},
+		{"nosuchkey", 0, false},
+	} {
+		if n, ok := UintOK(numbers, tc.key); n != tc.expect || ok != tc.ok {
+			t.Errorf("UintOK(%q): expected %v, %v, got %v, %v", tc.key, tc.expect, tc.ok, n, ok)
+		}
+		if n := Uint(numbers, tc.key); n != tc.expect {
+			t.Errorf("Uint(%q): expected %v, got %v", tc.key, tc.expect, n)
+		}
+		if n := New(numbers).Path(tc.key).Uint(); n != tc.expect {
+			t.Errorf("Result.Uint(%q): expected %v, got %v", tc.key, tc.expect, n)
+		}
+	}
+
+	if n, err := Uint64E(numbers, "maxuint"); n != math.MaxUint64 || err != nil {
+		t.Errorf("Uint64E: got %v, %v", n, err)
+	}
+	if _, err := Uint64E(numbers, "neg"); !errors.Is(err, ErrRange) {
+		t.Errorf("Uint64E of negative number: got %v", err)
+	}
+
+	e := NewEngine(NumberFormat(',', '.'))
+	if n := e.Uint(map[string]interface{}{"n": "1.234"}, "n"); n != 1234 {
+		t.Errorf("Engine.Uint: got %v", n)
+	}
+	if n := e.Uint(map[string]interface{}{"n": "-1"}, "n"); n != 0 {
+		t.Errorf("Engine.Uint of negative number: got %v", n)
+	}
+}
@@ -0,0 +1,26 @@
+package jq
+
+import "iter"
+
+// Iter returns an iterator over the values that QQ(root, path) would select, paired with
+// the index leading to each of them from root. For paths containing ALL, the values are
+// produced one at a time as the loop consumes them, so large results are never held in
+// a slice, and breaking out of the loop stops the traversal:
+//
+//	for p, v := range jq.Iter(root, "items/*/name") {
+//		fmt.Println(p, v)
+//	}
+//
+// Errors are yielded as values, like Q returns them. Each path is a fresh slice that may be retained.
+func Iter(root interface{}, path string) iter.Seq2[[]interface{}, interface{}] {
+	index, err := parsePath(path)
+	return func(yield func([]interface{}, interface{}) bool) {
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		stream(root, index, nil, func(p []interface{}, v interface{}) bool {
+			return yield(append([]interface{}(nil), p...), v)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package jq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIter(t *testing.T) {
+	root := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "a"},
+			map[string]interface{}{"name": "b"},
+			map[string]interface{}{"name": "c"},
+		},
+		"n": 1,
+	}
+	tests := []struct {
+		path  string
+		paths [][]interface{}
+		vals  []interface{}
+	}{
+		{"n", [][]interface{}{{"n"}}, []interface{}{1}},
+		{"items/*/name", [][]interface{}{{"items", 0, "name"}, {"items", 1, "name"}, {"items", 2, "name"}}, []interface{}{"a", "b", "c"}},
+		{"items/1", [][]interface{}{{"items", "1"}}, []interface{}{map[string]interface{}{"name": "b"}}},
+		{"missing", [][]interface{}{{"missing"}}, []interface{}{nil}},
+	}
+	for _, test := range tests {
+		var paths [][]interface{}
+		var vals []interface{}
+		for p, v := range Iter(root, test.path) {
+			paths = append(paths, p)
+			vals = append(vals, v)
+		}
+		if !reflect.DeepEqual(paths, test.paths) || !reflect.DeepEqual(vals, test.vals) {
+			t.Errorf("Iter(%q): expected %v %v, got %v %v", test.path, test.paths, test.vals, paths, vals)
+		}
+	}
+
+	var n int
+	for range Iter(root, "items/*/name") {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("break: expected 1 iteration, got %d", n)
+	}
+
+	for _, v := range Iter(root, "n/x") {
+		if !isError(v) {
+			t.Errorf("expected an error, got %v", v)
+		}
+	}
+}
@@ -7,11 +7,18 @@
 package jq
 
 import (
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
+	"net/textproto"
+	"net/url"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,12 +26,15 @@ type quantifier int
 
 const (
 	ALL quantifier = iota
+	KEYS
 )
 
 func (q quantifier) String() string {
 	switch q {
 	case ALL:
 		return "ALL"
+	case KEYS:
+		return "KEYS"
 	}
 	return fmt.Sprintf("<quantifier %d>", int(q))
 }
@@ -37,6 +47,55 @@ func isSigned(k reflect.Kind) bool {
 	return false
 }
 
+func isUnsigned(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// keyMatches reports whether the dynamic map key k is selected by index i.
+// String keys must match exactly, integer keys of any size and sign match an
+// integer index or a string that parses as one, and a nil key matches a nil index.
+func keyMatches(k, i reflect.Value) bool {
+	if !k.IsValid() {
+		return !i.IsValid()
+	}
+	if k.Kind() == reflect.String {
+		return i.Kind() == reflect.String && k.String() == i.String()
+	}
+
+	var (
+		neg bool
+		u   uint64
+	)
+	switch i.Kind() {
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u = i.Uint()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		neg, u = i.Int() < 0, uint64(i.Int())
+	case reflect.String:
+		if n, err := strconv.ParseInt(i.String(), 0, 64); err == nil {
+			neg, u = n < 0, uint64(n)
+		} else if n, err := strconv.ParseUint(i.String(), 0, 64); err == nil {
+			u = n
+		} else {
+			return false
+		}
+	default:
+		return false
+	}
+
+	switch k.Kind() {
+	case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return !neg && k.Uint() == u
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return (k.Int() < 0) == neg && uint64(k.Int()) == u
+	}
+	return false
+}
+
 // Q recursively queries the root object with the path composed of the indices.
 //
 // If index has no elements, it returns root.
@@ -58,14 +117,132 @@ func isSigned(k reflect.Kind) bool {
 // If root is a slice or array, Q returns a slice of the results of Q
 // applied to the elements of root with the remainder of the index.
 //
+// If the first element of index is the special value KEYS, Q returns
+// Q applied to a slice of the keys of root with the remainder of the index.
+// The keys of maps are sorted, the exported field names of structs are returned
+// in declaration order, and the keys of arrays and slices are their indices.
+//
+// If root is an OrderedMap, keys are looked up with Get, and ALL returns an *Ordered
+// and KEYS a slice with the keys in the order of root.
+//
+// If root is a map with interface key type, as decoded from CBOR,
+// string keys are matched against string elements of index and
+// integer keys against integer elements or strings that parse as integers.
+//
+// If root is a url.Values or http.Header, a key selects the first value stored
+// under it, and a key followed by ALL selects all of its values.
+// Header keys are canonicalized before lookup.
+//
+// If root is a *sync.Map, keys are looked up with Load and matched like
+// the keys of a map with interface key type, and ALL visits the entries with Range.
+//
+// If root is a map whose key type implements encoding.TextMarshaler or fmt.Stringer,
+// string elements of index are also matched against the text of the keys.
+//
+// If root is a time.Time, a string element of index selects one of its components:
+// year, month (1 to 12), day, hour, minute, second, nanosecond, weekday (its English name),
+// yearday, unix, unixmilli, unixnano, zone (its abbreviation) or date ("2006-01-02").
+//
+// If root is a Layered, the index is resolved against each of its layers in turn.
+//
+// If root is a Provider or a func() interface{}, Q is applied to the document it provides.
+//
+// If the first element of index is an Operator, such as Base64Decode, Q is applied to
+// the decoded root with the remainder of the index.
+//
+// If root is a json.RawMessage, as found in partially decoded documents
+// such as map[string]json.RawMessage, it is decoded and Q is applied to the result.
+// Only the raw messages that the path descends into are decoded.
+//
 // If the value is not present, Q returns nil, but if the
 // index has the wrong type for the root element it will return an error.
 func Q(root interface{}, index ...interface{}) interface{} {
+	return plain.q(root, index)
+}
+
+// step implements Q, applying the options in c.
+func (c *config) step(root interface{}, index []interface{}) interface{} {
+	if c.ctx != nil {
+		if err := c.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	root, err := c.provided(root)
+	if err != nil {
+		return err
+	}
 	if len(index) == 0 {
 		return root
 	}
 
+	if l, ok := root.(Layered); ok {
+		return c.qLayered(l, index)
+	}
+
+	if r, ok := root.(json.RawMessage); ok {
+		v, err := decodeRawMessage(r)
+		if err != nil {
+			return err
+		}
+		return c.step(v, index)
+	}
+
+	if s, ok := root.(string); ok && c.decodeStrings {
+		if v, ok := decodeJSONString(s); ok {
+			return c.step(v, index)
+		}
+	}
+
+	if op, ok := index[0].(*Operator); ok {
+		if root == nil {
+			return c.missing(op)
+		}
+		v, err := op.apply(root)
+		if err != nil {
+			return err
+		}
+		return c.next(v, op, index[1:])
+	}
+
+	if i, ok := index[0].(quantifier); ok && i == KEYS {
+		k := c.keys(root)
+		if _, ok := k.(error); ok {
+			return k
+		}
+		return c.next(k, KEYS, index[1:])
+	}
+
+	switch r := root.(type) {
+	case time.Time:
+		if name, ok := index[0].(string); ok {
+			if v, ok := timeComponent(r, name); ok {
+				return c.next(v, index[0], index[1:])
+			}
+			return c.missing(index[0])
+		}
+	case url.Values:
+		return c.qValues(r, nil, index)
+	case http.Header:
+		return c.qValues(r, textproto.CanonicalMIMEHeaderKey, index)
+	case *sync.Map:
+		return c.qSyncMap(r, index)
+	case OrderedMap:
+		return c.qOrdered(r, index)
+	}
+
 	if i, ok := index[0].(quantifier); ok && i == ALL {
+		if v := reflect.ValueOf(root); v.Kind() == reflect.Array || v.Kind() == reflect.Slice {
+			lo, hi, c := c.window(v.Len())
+			var a []interface{}
+			for ii := lo; ii < hi; ii++ {
+				r := v.Index(ii)
+				if r.IsValid() {
+					a = append(a, c.next(r.Interface(), ii, index[1:]))
+				}
+			}
+			return a
+		}
+		c := c.unbounded()
 		switch v := reflect.ValueOf(root); v.Kind() {
 		case reflect.Struct:
 			m := make(map[string]interface{})
@@ -78,14 +255,14 @@ func Q(root interface{}, index ...interface{}) interface{} {
 				if !r.IsValid() {
 					continue
 				}
-				rr := Q(r.Interface(), index[1:]...)
+				rr := c.next(r.Interface(), c.fieldName(f), index[1:])
 				// Fields will typically vary in type, and many of them may not be indexable
 				// like the rest of the query requires.  It seems more convenient for the user
 				// to just filter these elements out here.
 				if _, ok := rr.(error); ok {
 					continue
 				}
-				m[f.Name] = rr
+				m[c.fieldName(f)] = rr
 			}
 			return m
 
@@ -95,7 +272,7 @@ func Q(root interface{}, index ...interface{}) interface{} {
 			m := reflect.MakeMap(reflect.MapOf(k, reflect.TypeOf(dum).Elem()))
 			for _, kk := range v.MapKeys() {
 				vv := v.MapIndex(kk)
-				rr := Q(vv.Interface(), index[1:]...)
+				rr := c.next(vv.Interface(), kk.Interface(), index[1:])
 				if rr == nil {
 					continue
 				}
@@ -106,16 +283,6 @@ func Q(root interface{}, index ...interface{}) interface{} {
 				m.SetMapIndex(kk, reflect.ValueOf(rr))
 			}
 			return m.Interface()
-
-		case reflect.Array, reflect.Slice:
-			var a []interface{}
-			for ii := 0; ii < v.Len(); ii++ {
-				r := v.Index(ii)
-				if r.IsValid() {
-					a = append(a, Q(r.Interface(), index[1:]...))
-				}
-			}
-			return a
 		}
 		return fmt.Errorf("type %T does not support retrieving ALL", root)
 	}
@@ -128,23 +295,37 @@ func Q(root interface{}, index ...interface{}) interface{} {
 	case reflect.Struct:
 		switch i := reflect.ValueOf(index[0]); i.Kind() {
 		case reflect.String:
-			r := v.FieldByName(strings.Title(i.String())) // get the corresponding exported field only
-			if r.IsValid() {
-				return Q(r.Interface(), index[1:]...)
+			if r := c.field(v, i.String()); r.IsValid() {
+				return c.next(r.Interface(), index[0], index[1:])
 			}
-			return nil
+			return c.missing(index[0])
 		}
 		return fmt.Errorf("cannot use %v (type %T) as struct field name", index[0], index[0])
 
 	case reflect.Map:
+		if s, ok := index[0].(string); ok && rendersKeys(v.Type().Key()) {
+			if kk, ok := c.renderedKey(v, s); ok {
+				return c.next(v.MapIndex(kk).Interface(), kk.Interface(), index[1:])
+			}
+			if k := v.Type().Key().Kind(); k == reflect.Struct || k == reflect.Array || k == reflect.Ptr {
+				return c.missing(index[0])
+			}
+		}
 		switch k := v.Type().Key(); k.Kind() {
 		case reflect.String:
 			switch i := reflect.ValueOf(index[0]); i.Kind() {
 			case reflect.String:
-				if vv := v.MapIndex(i); vv.IsValid() {
-					return Q(vv.Interface(), index[1:]...)
+				if vv := v.MapIndex(i.Convert(k)); vv.IsValid() {
+					return c.next(vv.Interface(), index[0], index[1:])
 				}
-				return nil
+				if c.fold {
+					for _, kk := range v.MapKeys() {
+						if strings.EqualFold(kk.String(), i.String()) {
+							return c.next(v.MapIndex(kk).Interface(), kk.Interface(), index[1:])
+						}
+					}
+				}
+				return c.missing(index[0])
 			}
 			return fmt.Errorf("cannot use %v (type %T) as map key of type %s", index[0], index[0], k)
 
@@ -154,9 +335,9 @@ func Q(root interface{}, index ...interface{}) interface{} {
 			case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
 				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 				if vv := v.MapIndex(i.Convert(k)); vv.IsValid() {
-					return Q(vv.Interface(), index[1:]...)
+					return c.next(vv.Interface(), index[0], index[1:])
 				}
-				return nil
+				return c.missing(index[0])
 			case reflect.String:
 				var idxv reflect.Value
 				if isSigned(k.Kind()) {
@@ -173,73 +354,236 @@ func Q(root interface{}, index ...interface{}) interface{} {
 					idxv = reflect.ValueOf(idx)
 				}
 				if vv := v.MapIndex(idxv.Convert(k)); vv.IsValid() {
-					return Q(vv.Interface(), index[1:]...)
+					return c.next(vv.Interface(), index[0], index[1:])
 				}
-				return nil
+				return c.missing(index[0])
 			}
 			return fmt.Errorf("cannot use %v (type %T) as map key of type %s", index[0], index[0], k)
+
+		case reflect.Interface:
+			// maps with mixed key types, as produced by CBOR and YAML decoders.
+			i := reflect.ValueOf(index[0])
+			switch i.Kind() {
+			case reflect.Invalid, reflect.String, reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+				reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			default:
+				return fmt.Errorf("cannot use %v (type %T) as map key of type %s", index[0], index[0], k)
+			}
+			for _, kk := range v.MapKeys() {
+				if keyMatches(kk.Elem(), i) || c.fold && kk.Elem().Kind() == reflect.String && i.Kind() == reflect.String && strings.EqualFold(kk.Elem().String(), i.String()) {
+					return c.next(v.MapIndex(kk).Interface(), kk.Interface(), index[1:])
+				}
+			}
+			return c.missing(index[0])
 		}
 		return fmt.Errorf("map key type %s not supported", v.Type().Key())
 
 	case reflect.Array, reflect.Slice:
+		var idx int64
 		switch i := reflect.ValueOf(index[0]); i.Kind() {
 		case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			if ii := i.Uint(); ii < uint64(v.Len()) {
-				return Q(v.Index(int(ii)).Interface(), index[1:]...)
-			}
-			return nil
+			idx = int64(min(i.Uint(), math.MaxInt64))
 		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			if ii := i.Int(); 0 <= ii && ii < int64(v.Len()) {
-				return Q(v.Index(int(ii)).Interface(), index[1:]...)
-			}
-			return nil
+			idx = i.Int()
 		case reflect.String:
-			idx, err := strconv.ParseInt(i.String(), 0, 64)
+			var err error
+			idx, err = strconv.ParseInt(i.String(), 0, 64)
 			if err != nil {
 				return fmt.Errorf("cannot parse %v (type %T) as array index: %v)", index[0], index[0], err)
 			}
-			if 0 <= idx && idx < int64(v.Len()) {
-				return Q(v.Index(int(idx)).Interface(), index[1:]...)
-			}
-			return nil
+		default:
+			return fmt.Errorf("cannot use %v (type %T) as array index", index[0], index[0])
+		}
+		if ii, ok := c.position(idx, v.Len()); ok {
+			return c.next(v.Index(ii).Interface(), index[0], index[1:])
+		}
+		if c.indexPolicy == IndexError {
+			return fmt.Errorf("index %v out of range for length %d", index[0], v.Len())
 		}
-		return fmt.Errorf("cannot use %v (type %T) as array index", index[0], index[0])
+		return c.missing(index[0])
 	}
 
 	return fmt.Errorf("type %T does not support indexing", root)
 }
 
+// timeComponent returns the component of t named name, ignoring case, as described for Q.
+func timeComponent(t time.Time, name string) (interface{}, bool) {
+	switch strings.ToLower(name) {
+	case "year":
+		return t.Year(), true
+	case "month":
+		return int(t.Month()), true
+	case "day":
+		return t.Day(), true
+	case "hour":
+		return t.Hour(), true
+	case "minute":
+		return t.Minute(), true
+	case "second":
+		return t.Second(), true
+	case "nanosecond":
+		return t.Nanosecond(), true
+	case "weekday":
+		return t.Weekday().String(), true
+	case "yearday":
+		return t.YearDay(), true
+	case "unix":
+		return t.Unix(), true
+	case "unixmilli":
+		return t.UnixMilli(), true
+	case "unixnano":
+		return t.UnixNano(), true
+	case "zone":
+		z, _ := t.Zone()
+		return z, true
+	case "date":
+		return t.Format(time.DateOnly), true
+	}
+	return nil, false
+}
+
+var (
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// rendersKeys reports whether map keys of type t can be matched by their text,
+// as rendered by MarshalText or String.
+func rendersKeys(t reflect.Type) bool {
+	return t.Implements(textMarshalerType) || t.Implements(stringerType)
+}
+
+// renderedKey returns the key of the map v whose text is s, ignoring case if c says so.
+// Keys are rendered with MarshalText if they implement encoding.TextMarshaler, like
+// encoding/json does, and with String otherwise.
+func (c *config) renderedKey(v reflect.Value, s string) (reflect.Value, bool) {
+	var folded reflect.Value
+	for _, k := range v.MapKeys() {
+		var text string
+		switch kk := k.Interface().(type) {
+		case encoding.TextMarshaler:
+			b, err := kk.MarshalText()
+			if err != nil {
+				continue
+			}
+			text = string(b)
+		case fmt.Stringer:
+			text = kk.String()
+		}
+		if text == s {
+			return k, true
+		}
+		if c.fold && !folded.IsValid() && strings.EqualFold(text, s) {
+			folded = k
+		}
+	}
+	return folded, folded.IsValid()
+}
+
 // QQ splits the single argument 'index' on slashes and calls Q with the resulting index array.
-// an index element named "*" will be mapped to the jq.ALL value.
+// an index element named "*" will be mapped to the jq.ALL value,
+// and the names of operators, such as "@base64d", to the Operator values.
+// A path with an empty element, such as "a//b" or "a/", yields an error wrapping ErrBadPath.
 func QQ(root interface{}, index string) interface{} {
+	pp, err := parsePath(index)
+	if err != nil {
+		return err
+	}
+	return Q(root, pp...)
+}
+
+// ErrBadPath is returned, wrapped, for malformed paths by QQ, QQE and the other functions
+// taking slash separated paths that report errors.
+var ErrBadPath = errors.New("malformed path")
+
+// QQE is like QQ, but returns the value and the error separately, and tells the reasons
+// a query fails apart: the error wraps ErrBadPath if path is malformed and ErrNotFound
+// if the value is not present; other errors are those of the query, such as indexing a number.
+// A present nil value, such as a JSON null, is returned without error.
+func QQE(root interface{}, path string) (interface{}, error) {
+	index, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := lookup(root, index); ok {
+		return v, nil
+	}
+	if err, ok := Q(root, index...).(error); ok {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return nil, fmt.Errorf("%s: %w", path, ErrNotFound)
+}
+
+// parsePath is like split, but rejects empty path elements. All functions taking
+// slash separated paths use it, so that a path is accepted by all of them or by none.
+func parsePath(path string) ([]interface{}, error) {
+	return plain.parsePath(path)
+}
+
+// parsePath implements parsePath, using the separator in c.
+func (c *config) parsePath(path string) ([]interface{}, error) {
+	pp := c.split(path)
+	for i, p := range pp {
+		if p == "" {
+			return nil, fmt.Errorf("%q: element %d is empty: %w", path, i+1, ErrBadPath)
+		}
+	}
+	return pp, nil
+}
+
+// split turns a slash separated path into an index array for Q.
+func split(index string) []interface{} {
+	return splitSep(index, "/")
+}
+
+// splitSep turns a path separated by sep into an index array for Q.
+func splitSep(index, sep string) []interface{} {
 	var pp []interface{}
 	if index != "" {
-		parts := strings.Split(index, "/")
+		parts := strings.Split(index, sep)
 		for _, v := range parts {
 			if v == "*" {
 				pp = append(pp, ALL)
+			} else if op, ok := pathOperators[v]; ok {
+				pp = append(pp, op)
 			} else {
 				pp = append(pp, v)
 			}
 		}
 	}
-	return Q(root, pp...)
+	return pp
 }
 
 // String returns the string found at path or the empty string in all other cases.
+// Byte slices, as produced for CBOR byte strings, are returned as strings,
+// and times are formatted as RFC 3339, as encoding/json formats them.
 func String(root interface{}, index ...interface{}) string {
-	switch vv := Q(root, index...).(type) {
+	return asString(Q(root, index...))
+}
+
+// asString converts the result of a query like String.
+func asString(v interface{}) string {
+	switch vv := v.(type) {
 	case string:
 		return vv
+	case []byte:
+		return string(vv)
 	case json.Number:
 		return vv.String()
+	case time.Time:
+		return vv.Format(time.RFC3339Nano)
 	}
 	return ""
 }
 
 // Bool returns the truth value according to javascript rules.
 func Bool(root interface{}, index ...interface{}) bool {
-	switch vv := Q(root, index...).(type) {
+	return asBool(Q(root, index...))
+}
+
+// asBool converts the result of a query like Bool.
+func asBool(v interface{}) bool {
+	switch vv := v.(type) {
 	case string:
 		return vv != ""
 	case bool:
@@ -278,7 +622,12 @@ func Bool(root interface{}, index ...interface{}) bool {
 // Integers of any size and sign are cast to plain int, with possible loss of information.
 // Int also handles the json.Number type that may be returned by json.Unmarshal.
 func Int(root interface{}, index ...interface{}) int {
-	switch vv := Q(root, index...).(type) {
+	return asInt(Q(root, index...))
+}
+
+// asInt converts the result of a query like Int.
+func asInt(v interface{}) int {
+	switch vv := v.(type) {
 	case int:
 		return vv
 	case int8:
@@ -312,10 +661,53 @@ func Int(root interface{}, index ...interface{}) int {
 var zeroTime time.Time
 
 // Time returns the string found at path, parsed as an RFC3339 formatted date
-// and time "2006-01-02T15:04:05Z07:00" with optional fractional second or the
+// and time "2006-01-02T15:04:05Z07:00" with optional fractional second, or the
 // time object found at that path, or the zero time in all other cases.
+// Strings without a zone, "2006-01-02T15:04:05" or "2006-01-02 15:04:05" with optional
+// fractional second, and dates "2006-01-02" are parsed as UTC; use TimeIn to parse them in another zone.
+// Other formats can be added with RegisterTimeFormat.
 func Time(root interface{}, index ...interface{}) time.Time {
-	switch v := Q(root, index...).(type) {
+	return asTime(Q(root, index...), time.UTC, nil)
+}
+
+// TimeIn is like Time, but parses strings without a zone in loc, and returns the time in loc.
+func TimeIn(root interface{}, loc *time.Location, index ...interface{}) time.Time {
+	return timeIn(asTime(Q(root, index...), loc, nil), loc)
+}
+
+// localLayouts are the layouts without a zone that Time accepts.
+var localLayouts = []string{
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	time.DateOnly,
+}
+
+// timeFormats holds the layouts added by RegisterTimeFormat.
+var timeFormats struct {
+	sync.RWMutex
+	layouts []string
+}
+
+// RegisterTimeFormat makes Time and all other functions converting strings to times like it
+// try layout, as understood by time.Parse, after the layouts they accept by default.
+// Layouts without a zone are parsed in UTC, or in the location given to TimeIn or Location.
+// It is meant to be called during initialization, for formats used throughout an application;
+// to add a format for some queries only, use an Engine with the TimeFormat option.
+func RegisterTimeFormat(layout string) {
+	timeFormats.Lock()
+	defer timeFormats.Unlock()
+	for _, l := range timeFormats.layouts {
+		if l == layout {
+			return
+		}
+	}
+	timeFormats.layouts = append(timeFormats.layouts, layout)
+}
+
+// asTime converts the result of a query like Time, trying layouts after the default ones and
+// before those registered with RegisterTimeFormat, and parsing strings without a zone in loc.
+func asTime(v interface{}, loc *time.Location, layouts []string) time.Time {
+	switch v := v.(type) {
 	case string:
 		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
 			return t
@@ -323,8 +715,33 @@ func Time(root interface{}, index ...interface{}) time.Time {
 		if t, err := time.Parse(time.RFC3339, v); err == nil {
 			return t
 		}
+		for _, layout := range localLayouts {
+			if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+				return t
+			}
+		}
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+				return t
+			}
+		}
+		timeFormats.RLock()
+		defer timeFormats.RUnlock()
+		for _, layout := range timeFormats.layouts {
+			if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+				return t
+			}
+		}
 	case time.Time:
 		return v
 	}
 	return zeroTime
 }
+
+// timeIn returns t in loc, leaving the zero time unchanged.
+func timeIn(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(loc)
+}
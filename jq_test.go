@@ -3,8 +3,10 @@ package jq
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 )
 
 var ee = errors.New("") // dummy error to flag expecting error
@@ -184,6 +186,9 @@ func TestQQ(t *testing.T) {
 		{testStruct, "subobj/subsubobj/array/1", "world"},
 		{testStruct, "subobj/subarray/0", 1},
 		{testStruct, "subobj/subarray/-1", nil},
+		{testObj, "subobj//foo", ee},
+		{testObj, "subobj/", ee},
+		{testObj, "/foo", ee},
 	} {
 		if _, ok := tc.expect.(error); ok {
 			v := QQ(tc.root, tc.path)
@@ -203,10 +208,82 @@ func TestQQ(t *testing.T) {
 	}
 }
 
+func TestQQE(t *testing.T) {
+	for _, tc := range []struct {
+		path   string
+		expect interface{}
+		err    error
+	}{
+		{"subobj/foo", 1., nil},
+		{"", testObj, nil},
+		{"subobj/subsubobj/array/*", []interface{}{"hello", "world"}, nil},
+		{"nosuchkey", nil, ErrNotFound},
+		{"array/0/bar", nil, ErrNotFound},
+		{"subobj//foo", nil, ErrBadPath},
+		{"subobj/", nil, ErrBadPath},
+		{"foo/x", nil, ee},
+	} {
+		v, err := QQE(testObj, tc.path)
+		switch {
+		case tc.err == ee:
+			if err == nil || errors.Is(err, ErrNotFound) || errors.Is(err, ErrBadPath) {
+				t.Errorf("%q: expected a query error, got %v", tc.path, err)
+			}
+		case !errors.Is(err, tc.err):
+			t.Errorf("%q: expected error %v, got %v", tc.path, tc.err, err)
+		case !reflect.DeepEqual(v, tc.expect):
+			t.Errorf("%q: expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+	if v, err := QQE(map[string]interface{}{"n": nil}, "n"); v != nil || err != nil {
+		t.Errorf("null: got %v, %v", v, err)
+	}
+}
+
+func TestBadPathEverywhere(t *testing.T) {
+	root := map[string]interface{}{"a": map[string]interface{}{"b": 1.}, "list": []interface{}{map[string]interface{}{"b": 2.}}}
+	for _, path := range []string{"a//b", "a/b/", "/a"} {
+		if v := GetMany(root, path); !errors.Is(v[0].(error), ErrBadPath) {
+			t.Errorf("GetMany %q: got %v", path, v)
+		}
+		if _, err := resolve(root, path); !errors.Is(err, ErrBadPath) {
+			t.Errorf("resolve %q: got %v", path, err)
+		}
+		if err := Expect(root, map[string]Kind{path: KindNumber}); !errors.Is(err, ErrBadPath) {
+			t.Errorf("Expect %q: got %v", path, err)
+		}
+		if v := Reduce(root, path, 0., func(acc, v interface{}) interface{} { return acc.(float64) + v.(float64) }); v != 0. {
+			t.Errorf("Reduce %q: got %v", path, v)
+		}
+		if v := Pluck(root, "list", path); !reflect.DeepEqual(v, []interface{}{nil}) {
+			t.Errorf("Pluck %q: got %v", path, v)
+		}
+		if v := QQWith(root, nil, path); !isError(v) {
+			t.Errorf("QQWith %q: got %v", path, v)
+		}
+		if v := NewEngine().QQ(root, path); !isError(v) {
+			t.Errorf("Engine.QQ %q: got %v", path, v)
+		}
+		if err := New(root).Path(path).Err(); !errors.Is(err, ErrBadPath) {
+			t.Errorf("Result.Path %q: got %v", path, err)
+		}
+		if _, err := At(path).Run(root); !errors.Is(err, ErrBadPath) {
+			t.Errorf("At %q: got %v", path, err)
+		}
+		if n := From(root, path).Count(); n != 0 {
+			t.Errorf("From %q: got %d values", path, n)
+		}
+	}
+}
+
 func TestString(t *testing.T) {
 	if v := String(testStruct, "subobj", "subsubobj", "array", "1"); v != "world" {
 		t.Errorf("%#v [%q]:  expected %v, got %v (%T)", testStruct, "subobj/subsubobj/array/1", "world", v, v)
 	}
+	at := time.Date(2024, 3, 1, 10, 0, 0, 5e8, time.FixedZone("", 3600))
+	if v := String(map[string]interface{}{"at": at}, "at"); v != "2024-03-01T10:00:00.5+01:00" {
+		t.Errorf("time: expected RFC 3339, got %q", v)
+	}
 }
 
 func TestInt(t *testing.T) {
@@ -220,3 +297,217 @@ func TestBool(t *testing.T) {
 		t.Errorf("%#v [%q]:  expected %v, got %v (%T)", testStruct, "subobj/subsubobj/bar", 1, v, v)
 	}
 }
+
+func TestTime(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	root := map[string]interface{}{
+		"zoned": "2024-03-01T12:00:00+01:00",
+		"naive": "2024-03-01T12:00:00",
+		"space": "2024-03-01 12:00:00.5",
+		"date":  "2024-03-01",
+		"bad":   "yesterday",
+	}
+	for _, tc := range []struct {
+		path   string
+		loc    *time.Location
+		expect time.Time
+	}{
+		{"zoned", time.UTC, time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)},
+		{"naive", time.UTC, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"space", time.UTC, time.Date(2024, 3, 1, 12, 0, 0, 5e8, time.UTC)},
+		{"date", time.UTC, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"zoned", ny, time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)},
+		{"naive", ny, time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC)},
+		{"date", ny, time.Date(2024, 3, 1, 5, 0, 0, 0, time.UTC)},
+		{"bad", ny, time.Time{}},
+	} {
+		v := TimeIn(root, tc.loc, tc.path)
+		if !v.Equal(tc.expect) {
+			t.Errorf("TimeIn(%q, %v): expected %v, got %v", tc.path, tc.loc, tc.expect, v)
+		}
+		if !v.IsZero() && v.Location() != tc.loc {
+			t.Errorf("TimeIn(%q, %v): got location %v", tc.path, tc.loc, v.Location())
+		}
+		if tc.loc == time.UTC && !Time(root, tc.path).Equal(tc.expect) {
+			t.Errorf("Time(%q): expected %v, got %v", tc.path, tc.expect, Time(root, tc.path))
+		}
+	}
+	if v := NewEngine(Location(ny)).Time(root, "naive"); !v.Equal(time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("Engine with Location: got %v", v)
+	}
+
+	custom := map[string]interface{}{"de": "01.03.2024 12:00", "us": "03/01/2024"}
+	e := NewEngine(TimeFormat("01/02/2006"))
+	if v := Time(custom, "us"); !v.IsZero() {
+		t.Errorf("unregistered format: got %v", v)
+	}
+	if v := e.Time(custom, "us"); !v.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Engine with TimeFormat: got %v", v)
+	}
+	timeFormats.RLock()
+	registered := timeFormats.layouts
+	timeFormats.RUnlock()
+	t.Cleanup(func() {
+		timeFormats.Lock()
+		timeFormats.layouts = registered
+		timeFormats.Unlock()
+	})
+	RegisterTimeFormat("02.01.2006 15:04")
+	RegisterTimeFormat("02.01.2006 15:04")
+	timeFormats.RLock()
+	if n := len(timeFormats.layouts) - len(registered); n != 1 {
+		t.Errorf("RegisterTimeFormat twice: added %d layouts", n)
+	}
+	timeFormats.RUnlock()
+	if v := Time(custom, "de"); !v.Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("registered format: got %v", v)
+	}
+	if v := e.New(custom).Path("de").Time(); !v.Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("registered format with Engine: got %v", v)
+	}
+	var d time.Time
+	if err := DecodePath(custom, "de", &d); err != nil || !d.Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("registered format with DecodePath: got %v, %v", d, err)
+	}
+	if v := TimeIn(custom, ny, "de"); !v.Equal(time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("registered format with TimeIn: got %v", v)
+	}
+}
+
+func TestTimeComponents(t *testing.T) {
+	created := time.Date(2024, 3, 1, 14, 5, 6, 7, time.UTC)
+	root := map[string]interface{}{"created": created}
+	for _, tc := range []struct {
+		path   string
+		expect interface{}
+	}{
+		{"created/year", 2024},
+		{"created/month", 3},
+		{"created/day", 1},
+		{"created/hour", 14},
+		{"created/minute", 5},
+		{"created/second", 6},
+		{"created/nanosecond", 7},
+		{"created/weekday", "Friday"},
+		{"created/yearday", 61},
+		{"created/unix", created.Unix()},
+		{"created/unixMilli", created.UnixMilli()},
+		{"created/zone", "UTC"},
+		{"created/date", "2024-03-01"},
+		{"created/century", nil},
+		{"created/year/x", ee},
+	} {
+		v := QQ(root, tc.path)
+		if tc.expect == ee {
+			if !isError(v) {
+				t.Errorf("%q: expected error, got %v", tc.path, v)
+			}
+			continue
+		}
+		if v != tc.expect {
+			t.Errorf("%q: expected %v (%T), got %v (%T)", tc.path, tc.expect, tc.expect, v, v)
+		}
+	}
+	if v := Int(root, "created", "year"); v != 2024 {
+		t.Errorf("Int: got %v", v)
+	}
+}
+
+type pointKey struct{ X, Y int }
+
+func (p pointKey) MarshalText() ([]byte, error) { return []byte(fmt.Sprintf("%d,%d", p.X, p.Y)), nil }
+
+type color int
+
+func (c color) String() string { return [...]string{"red", "green"}[c] }
+
+type userID string
+
+func (u userID) String() string { return "user-" + string(u) }
+
+func TestRenderedKeys(t *testing.T) {
+	grid := map[pointKey]interface{}{{1, 2}: "a", {3, 4}: map[string]interface{}{"b": 1}, {5, 6}: nil}
+	colors := map[color]int{0: 10, 1: 20}
+	users := map[userID]string{"7": "alice"}
+	for _, tc := range []struct {
+		root   interface{}
+		opts   []Option
+		path   []interface{}
+		expect interface{}
+	}{
+		{grid, nil, []interface{}{"1,2"}, "a"},
+		{grid, nil, []interface{}{"3,4", "b"}, 1},
+		{grid, nil, []interface{}{"9,9"}, nil},
+		{colors, nil, []interface{}{"green"}, 20},
+		{colors, nil, []interface{}{"0"}, 10},
+		{colors, nil, []interface{}{"GREEN"}, ee},
+		{colors, []Option{CaseInsensitive()}, []interface{}{"GREEN"}, 20},
+		{users, nil, []interface{}{"user-7"}, "alice"},
+		{users, nil, []interface{}{"7"}, "alice"},
+	} {
+		v := QWith(tc.root, tc.opts, tc.path...)
+		if tc.expect == ee {
+			if !isError(v) {
+				t.Errorf("%v: expected error, got %v", tc.path, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%v: expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+	if !Exists(grid, "5,6") || Exists(grid, "7,8") {
+		t.Errorf("Exists: wrong result")
+	}
+}
+
+// cborObj has the shape of a CBOR payload decoded into interface{}.
+var cborObj = map[interface{}]interface{}{
+	"name":    "sensor-1",
+	"raw":     []byte("abc"),
+	uint64(1): "one",
+	int64(-1): "minus one",
+	"readings": []interface{}{
+		map[interface{}]interface{}{"t": uint64(20), "h": int64(-3)},
+	},
+}
+
+func TestInterfaceKeys(t *testing.T) {
+	for _, tc := range []struct {
+		path   []interface{}
+		expect interface{}
+	}{
+		{[]interface{}{"name"}, "sensor-1"},
+		{[]interface{}{"1"}, "one"},
+		{[]interface{}{1}, "one"},
+		{[]interface{}{"-1"}, "minus one"},
+		{[]interface{}{int8(-1)}, "minus one"},
+		{[]interface{}{"2"}, nil},
+		{[]interface{}{"nosuchkey"}, nil},
+		{[]interface{}{1.5}, ee},
+		{[]interface{}{"readings", 0, "t"}, uint64(20)},
+		{[]interface{}{"readings", ALL, "h"}, []interface{}{int64(-3)}},
+		{[]interface{}{"readings", 0, ALL}, map[interface{}]interface{}{"t": uint64(20), "h": int64(-3)}},
+	} {
+		v := Q(cborObj, tc.path...)
+		if _, ok := tc.expect.(error); ok {
+			if _, ok := v.(error); !ok {
+				t.Errorf("%#v [%v]: expected error, got %v (%T) ", cborObj, tc.path, v, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%#v [%v]:  expected %v, got %v (%T)", cborObj, tc.path, tc.expect, v, v)
+		}
+	}
+
+	if v := String(cborObj, "raw"); v != "abc" {
+		t.Errorf("%#v [%q]:  expected %v, got %v (%T)", cborObj, "raw", "abc", v, v)
+	}
+	if v := Int(cborObj, "readings", 0, "h"); v != -3 {
+		t.Errorf("%#v [%q]:  expected %v, got %v (%T)", cborObj, "readings/0/h", -3, v, v)
+	}
+}
@@ -0,0 +1,181 @@
+/*
+Package jqgraphql reads GraphQL responses, which wrap the result of a query in an envelope
+of the form {"data": ..., "errors": [...]}:
+
+	resp, err := jqgraphql.Parse(body)
+	if err != nil {
+		return err
+	}
+	if err := resp.Err(); err != nil && resp.Data("") == nil {
+		return err
+	}
+	name := resp.String("viewer/login")
+
+Paths are relative to the data of the response. Since a GraphQL server can return partial
+data together with errors for the fields it could not resolve, ErrorsAt reports the errors
+for a part of the data.
+
+Numbers are decoded as json.Number, so Int and String read them without loss of precision.
+*/
+package jqgraphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+// Response is a decoded GraphQL response.
+type Response struct {
+	data       interface{}
+	errors     []Error
+	extensions map[string]interface{}
+}
+
+// Error is an entry of the errors of a response.
+type Error struct {
+	Message    string
+	Path       []interface{} // of the field that failed, as strings and numbers, or nil
+	Locations  []Location
+	Extensions map[string]interface{}
+}
+
+// Location is a position in the query document.
+type Location struct {
+	Line   int
+	Column int
+}
+
+// Error returns the message of e, preceded by its path if it has one.
+func (e Error) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+	return pathString(e.Path) + ": " + e.Message
+}
+
+// pathString formats path with slashes, like the paths accepted by jq.QQ.
+func pathString(path []interface{}) string {
+	ss := make([]string, len(path))
+	for i, p := range path {
+		ss[i] = fmt.Sprint(p)
+	}
+	return strings.Join(ss, "/")
+}
+
+// Parse decodes the response in b. It fails if b is not a JSON object.
+func Parse(b []byte) (*Response, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var root map[string]interface{}
+	if err := d.Decode(&root); err != nil {
+		return nil, fmt.Errorf("jqgraphql: %v", err)
+	}
+	if root == nil {
+		return nil, errors.New("jqgraphql: response is not a JSON object")
+	}
+	r := &Response{data: root["data"]}
+	r.extensions, _ = root["extensions"].(map[string]interface{})
+	ee, _ := root["errors"].([]interface{})
+	for _, e := range ee {
+		r.errors = append(r.errors, parseError(e))
+	}
+	return r, nil
+}
+
+// parseError converts an entry of the errors of a response.
+func parseError(e interface{}) Error {
+	err := Error{Message: jq.String(e, "message")}
+	err.Extensions, _ = jq.Q(e, "extensions").(map[string]interface{})
+	pp, _ := jq.Q(e, "path").([]interface{})
+	for _, p := range pp {
+		if n, ok := p.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				p = int(i)
+			}
+		}
+		err.Path = append(err.Path, p)
+	}
+	ll, _ := jq.Q(e, "locations").([]interface{})
+	for _, l := range ll {
+		err.Locations = append(err.Locations, Location{Line: jq.Int(l, "line"), Column: jq.Int(l, "column")})
+	}
+	return err
+}
+
+// Data returns the value at the slash separated path in the data of r, like jq.QQ.
+// The empty path returns all of the data, which is nil if the request failed entirely.
+func (r *Response) Data(path string) interface{} {
+	return jq.QQ(r.data, path)
+}
+
+// String returns the string at path in the data of r, like jq.String.
+func (r *Response) String(path string) string {
+	return jq.New(r.data).Path(path).String()
+}
+
+// Int returns the integer at path in the data of r, like jq.Int.
+func (r *Response) Int(path string) int {
+	return jq.New(r.data).Path(path).Int()
+}
+
+// Bool returns the truth value at path in the data of r, like jq.Bool.
+func (r *Response) Bool(path string) bool {
+	return jq.New(r.data).Path(path).Bool()
+}
+
+// Decode stores the value at path in the data of r in dst, like jq.DecodePath.
+func (r *Response) Decode(path string, dst interface{}) error {
+	return jq.DecodePath(r.data, path, dst)
+}
+
+// Errors returns the errors of r, in the order of the response.
+func (r *Response) Errors() []Error {
+	return r.errors
+}
+
+// ErrorsAt returns the errors of r whose path is at or under the slash separated path,
+// such as the errors for the fields of "viewer/repositories/3". Errors without a path,
+// such as those of requests that failed validation, are not included.
+func (r *Response) ErrorsAt(path string) []Error {
+	var prefix []string
+	if path != "" {
+		prefix = strings.Split(path, "/")
+	}
+	var ee []Error
+	for _, e := range r.errors {
+		if len(e.Path) < len(prefix) || len(e.Path) == 0 {
+			continue
+		}
+		match := true
+		for i, p := range prefix {
+			if fmt.Sprint(e.Path[i]) != p {
+				match = false
+				break
+			}
+		}
+		if match {
+			ee = append(ee, e)
+		}
+	}
+	return ee
+}
+
+// Err returns the errors of r joined by errors.Join, or nil if there are none.
+func (r *Response) Err() error {
+	ee := make([]error, len(r.errors))
+	for i, e := range r.errors {
+		ee[i] = e
+	}
+	return errors.Join(ee...)
+}
+
+// Extensions returns the value at path in the extensions of r, such as rate limit
+// or tracing information, like jq.QQ.
+func (r *Response) Extensions(path string) interface{} {
+	return jq.QQ(r.extensions, path)
+}
@@ -0,0 +1,96 @@
+package jqgraphql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const response = `{
+  "data": {
+    "viewer": {
+      "login": "octocat",
+      "id": 9007199254740993,
+      "repositories": [{"name": "a", "stars": 3}, {"name": "b", "stars": null}]
+    }
+  },
+  "errors": [
+    {"message": "stars unavailable", "path": ["viewer", "repositories", 1, "stars"], "locations": [{"line": 4, "column": 7}], "extensions": {"code": "TIMEOUT"}},
+    {"message": "deprecated"}
+  ],
+  "extensions": {"cost": {"remaining": 4990}}
+}`
+
+func TestResponse(t *testing.T) {
+	r, err := Parse([]byte(response))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := r.String("viewer/login"); v != "octocat" {
+		t.Errorf("String: got %q", v)
+	}
+	if v := r.String("viewer/id"); v != "9007199254740993" {
+		t.Errorf("String of large number: got %q", v)
+	}
+	if v := r.Int("viewer/repositories/0/stars"); v != 3 {
+		t.Errorf("Int: got %v", v)
+	}
+	if v := r.Data("viewer/repositories/*/name"); !reflect.DeepEqual(v, []interface{}{"a", "b"}) {
+		t.Errorf("Data: got %v", v)
+	}
+	var repos []struct{ Name string }
+	if err := r.Decode("viewer/repositories", &repos); err != nil || len(repos) != 2 || repos[1].Name != "b" {
+		t.Errorf("Decode: got %+v, %v", repos, err)
+	}
+	if v := r.Int("/cost/remaining"); v != 0 {
+		t.Errorf("Int outside of data: got %v", v)
+	}
+	if v := r.Extensions("cost/remaining"); v == nil {
+		t.Errorf("Extensions: got nil")
+	}
+
+	ee := r.Errors()
+	if len(ee) != 2 {
+		t.Fatalf("Errors: got %v", ee)
+	}
+	expect := Error{
+		Message:    "stars unavailable",
+		Path:       []interface{}{"viewer", "repositories", 1, "stars"},
+		Locations:  []Location{{Line: 4, Column: 7}},
+		Extensions: map[string]interface{}{"code": "TIMEOUT"},
+	}
+	if !reflect.DeepEqual(ee[0], expect) {
+		t.Errorf("Errors: got %+v", ee[0])
+	}
+	if s := ee[0].Error(); s != "viewer/repositories/1/stars: stars unavailable" {
+		t.Errorf("Error: got %q", s)
+	}
+	if s := ee[1].Error(); s != "deprecated" {
+		t.Errorf("Error without path: got %q", s)
+	}
+	for path, n := range map[string]int{"": 1, "viewer": 1, "viewer/repositories/1": 1, "viewer/repositories/0": 0, "viewer/repositories/1/stars/x": 0} {
+		if got := r.ErrorsAt(path); len(got) != n {
+			t.Errorf("ErrorsAt(%q): got %v", path, got)
+		}
+	}
+	var ge Error
+	if err := r.Err(); !errors.As(err, &ge) || ge.Message != "stars unavailable" {
+		t.Errorf("Err: got %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	r, err := Parse([]byte(`{"data": {"a": 1}}`))
+	if err != nil || r.Err() != nil || r.Errors() != nil {
+		t.Errorf("response without errors: got %v, %v", r, err)
+	}
+	r, err = Parse([]byte(`{"errors": [{"message": "syntax error"}]}`))
+	if err != nil || r.Data("") != nil || r.Err() == nil {
+		t.Errorf("failed request: got %v, %v", r, err)
+	}
+	for _, bad := range []string{``, `null`, `[1]`, `{"data":`} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+}
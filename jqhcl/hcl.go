@@ -0,0 +1,141 @@
+/*
+Package jqhcl converts HCL configuration, as used by Terraform, into the generic values
+understood by package jq, so that it can be navigated with the same paths as JSON.
+
+Blocks become nested maps keyed by their type and then by each of their labels, so that
+
+	resource "aws_instance" "web" {
+	  ami = "ami-123"
+	}
+
+is reachable as "resource/aws_instance/web/ami".  A block type or label that occurs more than once
+at the same level holds a slice of the blocks in source order; a block type that is also the name
+of an attribute, or that occurs both with and without labels, is an error.  Attributes become values:
+strings, json.Number, bool, []interface{} and map[string]interface{}.  Attribute expressions that
+cannot be evaluated without context, such as references to variables, are represented by their
+source text.
+*/
+package jqhcl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+// Parse parses the native HCL syntax in src and converts it with Decode.
+// The filename is only used in diagnostics.
+func Parse(src []byte, filename string) (map[string]interface{}, error) {
+	f, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return Decode(f.Body.(*hclsyntax.Body), src)
+}
+
+// Decode converts the body of a parsed HCL file.  Src is the source the body was parsed from,
+// used to represent expressions that cannot be evaluated; it may be nil.
+// It returns an error if a block would replace an attribute or blocks of the same type
+// with a different number of labels.
+func Decode(body *hclsyntax.Body, src []byte) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	for name, attr := range body.Attributes {
+		m[name] = expr(attr.Expr, src)
+	}
+	// the paths of the maps that hold the blocks of a type or label, by their labels
+	labelled := make(map[string]bool)
+	for _, b := range body.Blocks {
+		keys := append([]string{b.Type}, b.Labels...)
+		if _, ok := body.Attributes[b.Type]; ok {
+			return nil, fmt.Errorf("%s: block %s conflicts with the attribute of the same name", b.TypeRange, b.Type)
+		}
+		mm := m
+		for i, k := range keys[:len(keys)-1] {
+			path := strings.Join(keys[:i+1], "\x00")
+			if _, ok := mm[k]; !ok {
+				mm[k] = make(map[string]interface{})
+				labelled[path] = true
+			} else if !labelled[path] {
+				return nil, fmt.Errorf("%s: block %s has more labels than other blocks of its type", b.TypeRange, strings.Join(keys, " "))
+			}
+			mm = mm[k].(map[string]interface{})
+		}
+		if labelled[strings.Join(keys, "\x00")] {
+			return nil, fmt.Errorf("%s: block %s has fewer labels than other blocks of its type", b.TypeRange, strings.Join(keys, " "))
+		}
+		v, err := Decode(b.Body, src)
+		if err != nil {
+			return nil, err
+		}
+		add(mm, keys[len(keys)-1], v)
+	}
+	return m, nil
+}
+
+// QQ parses src and applies jq.QQ to the result.  A parse error is returned as the result.
+func QQ(src []byte, filename, index string) interface{} {
+	m, err := Parse(src, filename)
+	if err != nil {
+		return err
+	}
+	return jq.QQ(m, index)
+}
+
+// add stores v under k, turning repeated keys into a slice.
+func add(m map[string]interface{}, k string, v map[string]interface{}) {
+	switch prev := m[k].(type) {
+	case nil:
+		m[k] = v
+	case []interface{}:
+		m[k] = append(prev, v)
+	default:
+		m[k] = []interface{}{prev, v}
+	}
+}
+
+func expr(e hclsyntax.Expression, src []byte) interface{} {
+	v, diags := e.Value(nil)
+	if diags.HasErrors() {
+		if src == nil {
+			return nil
+		}
+		return string(e.Range().SliceBytes(src))
+	}
+	return value(v)
+}
+
+func value(v cty.Value) interface{} {
+	if v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		return v.AsString()
+	case t == cty.Number:
+		return json.Number(v.AsBigFloat().Text('f', -1))
+	case t == cty.Bool:
+		return v.True()
+	case t.IsListType() || t.IsTupleType() || t.IsSetType():
+		a := make([]interface{}, 0, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			_, vv := it.Element()
+			a = append(a, value(vv))
+		}
+		return a
+	case t.IsMapType() || t.IsObjectType():
+		m := make(map[string]interface{}, v.LengthInt())
+		for it := v.ElementIterator(); it.Next(); {
+			k, vv := it.Element()
+			m[k.AsString()] = value(vv)
+		}
+		return m
+	}
+	return nil
+}
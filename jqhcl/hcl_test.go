@@ -0,0 +1,99 @@
+package jqhcl
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+const testHCL = `
+region = "eu-west-1"
+count  = 3
+debug  = true
+tags   = { team = "infra" }
+zones  = ["a", "b"]
+ami    = var.ami
+
+resource "aws_instance" "web" {
+  instance_type = "t3.micro"
+}
+
+resource "aws_instance" "db" {
+  instance_type = "m5.large"
+}
+
+ingress {
+  port = 80
+}
+
+ingress {
+  port = 443
+}
+
+provider "aws" {
+  alias = "east"
+}
+`
+
+func TestQQ(t *testing.T) {
+	for _, tc := range []struct {
+		path   string
+		expect interface{}
+	}{
+		{"region", "eu-west-1"},
+		{"count", json.Number("3")},
+		{"debug", true},
+		{"tags/team", "infra"},
+		{"zones/1", "b"},
+		{"ami", "var.ami"},
+		{"resource/aws_instance/web/instance_type", "t3.micro"},
+		{"resource/aws_instance/*/instance_type", map[string]interface{}{"web": "t3.micro", "db": "m5.large"}},
+		{"ingress/1/port", json.Number("443")},
+		{"provider/aws/alias", "east"},
+		{"nosuchkey", nil},
+	} {
+		if v := QQ([]byte(testHCL), "test.hcl", tc.path); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("[%q]:  expected %v, got %v (%T)", tc.path, tc.expect, v, v)
+		}
+	}
+
+	m, err := Parse([]byte(testHCL), "test.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := jq.Int(m, "ingress", 0, "port"); v != 80 {
+		t.Errorf("[%q]:  expected %v, got %v", "ingress/0/port", 80, v)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	if _, err := Parse([]byte(`resource "x" {`), "bad.hcl"); err == nil {
+		t.Errorf("expected error")
+	}
+	if _, ok := QQ([]byte(`a = `), "bad.hcl", "a").(error); !ok {
+		t.Errorf("expected error result")
+	}
+}
+
+func TestConflict(t *testing.T) {
+	for _, src := range []string{
+		"tags = {}\ntags {\n}\n",
+		"a {\n}\na {\n}\na \"x\" {\n}\n",
+		"a \"x\" {\n}\na {\n}\n",
+		"a \"x\" {\n}\na \"x\" \"y\" {\n}\n",
+		"outer {\n  n = 1\n  n {\n  }\n}\n",
+	} {
+		if m, err := Parse([]byte(src), "conflict.hcl"); err == nil {
+			t.Errorf("%q: expected error, got %v", src, m)
+		}
+	}
+	m, err := Parse([]byte("a \"x\" {\n}\na \"y\" {\n}\na \"x\" {\n}\n"), "repeat.hcl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := jq.Q(m, "a", "x"); !reflect.DeepEqual(v, []interface{}{map[string]interface{}{}, map[string]interface{}{}}) {
+		t.Errorf("repeated label: got %v", v)
+	}
+}
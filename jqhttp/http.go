@@ -0,0 +1,200 @@
+/*
+Package jqhttp reads fields from JSON request bodies by path.
+
+Middleware decodes the body of each request once and stores it in the request context,
+where the getters find it:
+
+	mux.Handle("/search", jqhttp.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		size := jqhttp.Int(r, "page/size")
+		query := jqhttp.String(r, "query/text")
+		...
+	})))
+
+On the client side, QResponse and its variants read fields from the JSON body of a response:
+
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	login, err := jqhttp.ResponseValue[string](resp, "owner/login")
+
+Numbers are decoded as json.Number, so Int and String read them without loss of precision.
+*/
+package jqhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+// MaxBodyBytes limits the size of the bodies that are decoded.
+var MaxBodyBytes int64 = 10 << 20
+
+type rootKey struct{}
+
+// decoded holds the result of decoding a body.
+type decoded struct {
+	root interface{}
+	err  error
+}
+
+// Middleware decodes the JSON body of each request with a body and stores it in the request context
+// for the other functions of this package. It responds with 400 Bad Request if the body is not valid JSON
+// and with 413 Request Entity Too Large if it exceeds MaxBodyBytes, without calling next.
+// Requests with a Content-Type that is not JSON, such as form posts and uploads, are passed to next
+// untouched; a request without a Content-Type is decoded.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !hasJSONBody(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		root, err := decode(r)
+		if err != nil {
+			status := http.StatusBadRequest
+			var tooLarge tooLargeError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), root)))
+	})
+}
+
+// NewContext returns a copy of ctx holding root, as Middleware stores it.
+func NewContext(ctx context.Context, root interface{}) context.Context {
+	return context.WithValue(ctx, rootKey{}, decoded{root: root})
+}
+
+// tooLargeError is returned by readBody for a body that exceeds the limit, MaxBodyBytes at the time.
+type tooLargeError struct {
+	limit int64
+}
+
+func (e tooLargeError) Error() string {
+	return fmt.Sprintf("request body exceeds %d bytes", e.limit)
+}
+
+// hasJSONBody reports whether r has a body with a JSON Content-Type, or without one.
+func hasJSONBody(r *http.Request) bool {
+	if r.Body == nil || r.Body == http.NoBody {
+		return false
+	}
+	ct := r.Header.Get("Content-Type")
+	if ct == "" {
+		return true
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	return err == nil && isJSON(mt)
+}
+
+// decode reads and decodes the body of r.
+func decode(r *http.Request) (interface{}, error) {
+	b, err := readBody(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(b)) // leave the body readable for the handler
+	root, err := parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON body: %v", err)
+	}
+	return root, nil
+}
+
+// readBody reads and closes body, returning a tooLargeError if it exceeds MaxBodyBytes.
+func readBody(body io.ReadCloser) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(body, MaxBodyBytes+1))
+	body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > MaxBodyBytes {
+		return nil, tooLargeError{MaxBodyBytes}
+	}
+	return b, nil
+}
+
+// parse decodes the JSON document b with numbers as json.Number.
+func parse(b []byte) (interface{}, error) {
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var root interface{}
+	if err := d.Decode(&root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// Root returns the decoded body of r. If Middleware did not decode it, Root decodes it,
+// leaving r.Body readable again; since the result cannot be stored in r, it is decoded again
+// on every call then. A request without a body or with a Content-Type that is not JSON has none.
+func Root(r *http.Request) (interface{}, error) {
+	if d, ok := r.Context().Value(rootKey{}).(decoded); ok {
+		return d.root, d.err
+	}
+	if !hasJSONBody(r) {
+		return nil, nil
+	}
+	return decode(r)
+}
+
+// root returns the decoded body of r, or nil if it cannot be decoded.
+func root(r *http.Request) interface{} {
+	v, _ := Root(r)
+	return v
+}
+
+// Q returns the value at the slash separated path in the body of r, like jq.QQ.
+func Q(r *http.Request, path string) interface{} {
+	return jq.QQ(root(r), path)
+}
+
+// Exists reports whether the value at path is present in the body of r.
+func Exists(r *http.Request, path string) bool {
+	v, err := Root(r)
+	if err != nil {
+		return false
+	}
+	return jq.New(v).Path(path).Exists()
+}
+
+// String returns the string at path in the body of r, like jq.String.
+func String(r *http.Request, path string) string {
+	return jq.New(root(r)).Path(path).String()
+}
+
+// Int returns the integer at path in the body of r, like jq.Int.
+func Int(r *http.Request, path string) int {
+	return jq.New(root(r)).Path(path).Int()
+}
+
+// Bool returns the truth value at path in the body of r, like jq.Bool.
+func Bool(r *http.Request, path string) bool {
+	return jq.New(root(r)).Path(path).Bool()
+}
+
+// Time returns the time at path in the body of r, like jq.Time.
+func Time(r *http.Request, path string) time.Time {
+	return jq.New(root(r)).Path(path).Time()
+}
+
+// Decode stores the value at path in the body of r in dst, like jq.DecodePath.
+func Decode(r *http.Request, path string, dst interface{}) error {
+	v, err := Root(r)
+	if err != nil {
+		return err
+	}
+	return jq.DecodePath(v, path, dst)
+}
@@ -0,0 +1,117 @@
+package jqhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const body = `{"page": {"size": 25, "cursor": "abc"}, "query": {"text": "go", "exact": true}, "since": "2024-01-02T03:04:05Z", "id": 9007199254740993}`
+
+func TestMiddleware(t *testing.T) {
+	var called bool
+	h := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if v := Int(r, "page/size"); v != 25 {
+			t.Errorf("Int: expected 25, got %v", v)
+		}
+		if v := String(r, "query/text"); v != "go" {
+			t.Errorf("String: expected go, got %q", v)
+		}
+		if v := String(r, "id"); v != "9007199254740993" {
+			t.Errorf("String of large number: got %q", v)
+		}
+		if !Bool(r, "query/exact") || Bool(r, "query/missing") {
+			t.Errorf("Bool: wrong result")
+		}
+		if v := Time(r, "since"); v.Year() != 2024 {
+			t.Errorf("Time: got %v", v)
+		}
+		if !Exists(r, "page/cursor") || Exists(r, "page/nope") {
+			t.Errorf("Exists: wrong result")
+		}
+		var page struct {
+			Size   int    `json:"size"`
+			Cursor string `json:"cursor"`
+		}
+		if err := Decode(r, "page", &page); err != nil || page.Size != 25 || page.Cursor != "abc" {
+			t.Errorf("Decode: got %+v, %v", page, err)
+		}
+		if b, _ := io.ReadAll(r.Body); string(b) != body {
+			t.Errorf("body not readable by the handler: %q", b)
+		}
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	if !called || w.Code != http.StatusOK {
+		t.Errorf("handler called %v, status %d", called, w.Code)
+	}
+
+	called = false
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"page":`)))
+	if called || w.Code != http.StatusBadRequest {
+		t.Errorf("invalid body: handler called %v, status %d", called, w.Code)
+	}
+
+	old := MaxBodyBytes
+	MaxBodyBytes = 10
+	defer func() { MaxBodyBytes = old }()
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	if called || w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body: handler called %v, status %d", called, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "exceeds 10 bytes") {
+		t.Errorf("large body: message does not show the current limit: %q", w.Body.String())
+	}
+
+	// bodies that are not JSON reach the handler untouched, whatever their size
+	form := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("q") != "go" {
+			t.Errorf("form: got %v, %v", r.PostForm, err)
+		}
+		if v, err := Root(r); v != nil || err != nil {
+			t.Errorf("Root of a form: got %v, %v", v, err)
+		}
+	}))
+	for _, ct := range []string{"application/x-www-form-urlencoded", "application/x-www-form-urlencoded; charset=utf-8"} {
+		called = false
+		r := httptest.NewRequest("POST", "/", strings.NewReader("q=go&pad=0123456789"))
+		r.Header.Set("Content-Type", ct)
+		w = httptest.NewRecorder()
+		form.ServeHTTP(w, r)
+		if !called || w.Code != http.StatusOK {
+			t.Errorf("%s: handler called %v, status %d", ct, called, w.Code)
+		}
+	}
+	MaxBodyBytes = old
+	called = false
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json; charset=utf-8")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if !called || w.Code != http.StatusOK {
+		t.Errorf("application/json: handler called %v, status %d", called, w.Code)
+	}
+}
+
+func TestWithoutMiddleware(t *testing.T) {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	if v := Int(r, "page/size"); v != 25 {
+		t.Errorf("Int: expected 25, got %v", v)
+	}
+	if v := String(r, "page/cursor"); v != "abc" {
+		t.Errorf("second read: expected abc, got %q", v)
+	}
+	if v := String(httptest.NewRequest("GET", "/", nil), "page"); v != "" {
+		t.Errorf("no body: expected empty string, got %q", v)
+	}
+	r = r.WithContext(NewContext(r.Context(), map[string]interface{}{"a": "b"}))
+	if v := String(r, "a"); v != "b" {
+		t.Errorf("NewContext: got %q", v)
+	}
+}
@@ -0,0 +1,74 @@
+package jqhttp
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+// ReadResponse reads, closes and decodes the JSON body of resp. It fails if the body exceeds
+// MaxBodyBytes or if the response has a Content-Type that is not JSON, such as the HTML error
+// page of a proxy; a response without a Content-Type is decoded. The status code is not checked,
+// since many APIs describe their errors in JSON.
+func ReadResponse(resp *http.Response) (interface{}, error) {
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err != nil || !isJSON(mt) {
+			return nil, fmt.Errorf("response has content type %q, not JSON", ct)
+		}
+	}
+	b, err := readBody(resp.Body)
+	var tooLarge tooLargeError
+	if errors.As(err, &tooLarge) {
+		return nil, fmt.Errorf("response body exceeds %d bytes", tooLarge.limit)
+	}
+	if err != nil {
+		return nil, err
+	}
+	root, err := parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON response (status %d): %v", resp.StatusCode, err)
+	}
+	return root, nil
+}
+
+// isJSON reports whether the media type mt denotes JSON, such as application/json
+// or application/problem+json.
+func isJSON(mt string) bool {
+	return mt == "application/json" || mt == "text/json" || strings.HasSuffix(mt, "+json")
+}
+
+// QResponse reads the body of resp like ReadResponse and returns the value at path in it,
+// like jq.QQE. Since the body is consumed, use ReadResponse to read several fields.
+func QResponse(resp *http.Response, path string) (interface{}, error) {
+	root, err := ReadResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	return jq.QQE(root, path)
+}
+
+// DecodeResponse reads the body of resp like ReadResponse and stores the value at path
+// in dst, like jq.DecodePath.
+func DecodeResponse(resp *http.Response, path string, dst interface{}) error {
+	root, err := ReadResponse(resp)
+	if err != nil {
+		return err
+	}
+	return jq.DecodePath(root, path, dst)
+}
+
+// ResponseValue reads the body of resp like ReadResponse and returns the value at path
+// converted to T, like jq.DecodePath.
+func ResponseValue[T any](resp *http.Response, path string) (T, error) {
+	var v T
+	if err := DecodeResponse(resp, path, &v); err != nil {
+		var zero T
+		return zero, err
+	}
+	return v, nil
+}
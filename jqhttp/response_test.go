@@ -0,0 +1,57 @@
+package jqhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+func response(contentType, body string) *http.Response {
+	h := make(http.Header)
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: h, Body: io.NopCloser(strings.NewReader(body))}
+}
+
+func TestResponse(t *testing.T) {
+	if v, err := QResponse(response("application/json; charset=utf-8", body), "page/size"); err != nil || v != json.Number("25") {
+		t.Errorf("QResponse: got %v, %v", v, err)
+	}
+	if _, err := QResponse(response("application/json", body), "page/nope"); !errors.Is(err, jq.ErrNotFound) {
+		t.Errorf("QResponse of missing value: got %v", err)
+	}
+	if v, err := ResponseValue[int](response("", body), "page/size"); err != nil || v != 25 {
+		t.Errorf("ResponseValue without content type: got %v, %v", v, err)
+	}
+	if v, err := ResponseValue[string](response("application/problem+json", `{"title":"nope"}`), "title"); err != nil || v != "nope" {
+		t.Errorf("ResponseValue of problem: got %q, %v", v, err)
+	}
+	if v, err := ResponseValue[int](response("application/json", body), "query/text"); err == nil || v != 0 {
+		t.Errorf("ResponseValue of wrong type: got %v, %v", v, err)
+	}
+	var page struct {
+		Cursor string `json:"cursor"`
+	}
+	if err := DecodeResponse(response("application/json", body), "page", &page); err != nil || page.Cursor != "abc" {
+		t.Errorf("DecodeResponse: got %+v, %v", page, err)
+	}
+
+	if _, err := ReadResponse(response("text/html", "<html></html>")); err == nil {
+		t.Errorf("ReadResponse of HTML: expected error")
+	}
+	if _, err := ReadResponse(response("application/json", `{"page":`)); err == nil {
+		t.Errorf("ReadResponse of invalid JSON: expected error")
+	}
+	old := MaxBodyBytes
+	MaxBodyBytes = 10
+	defer func() { MaxBodyBytes = old }()
+	if _, err := ReadResponse(response("application/json", body)); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Errorf("ReadResponse of large body: got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Package jqini converts INI files into the generic values understood by package jq.
+
+Each section becomes a map of its keys, so the key host in section [database]
+is reachable as "database/host".  Keys that appear before the first section header
+are stored at the top level.  Values that parse as numbers are stored as json.Number
+and the values true and false as bool, so that jq.Int, jq.Bool and jq.String
+work as they do on decoded JSON; all other values are stored as strings.
+*/
+package jqini
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+// Parse reads an INI file from r.
+// Lines starting with ';' or '#' are comments, values may be surrounded by double quotes,
+// and repeated sections are merged.
+func Parse(r io.Reader) (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	sect := root
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "", line[0] == ';', line[0] == '#':
+			continue
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("jqini: line %d: unterminated section header", n)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			m, ok := root[name].(map[string]interface{})
+			if !ok {
+				if _, exists := root[name]; exists {
+					return nil, fmt.Errorf("jqini: line %d: section %s conflicts with key of the same name", n, name)
+				}
+				m = make(map[string]interface{})
+				root[name] = m
+			}
+			sect = m
+			continue
+		}
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			return nil, fmt.Errorf("jqini: line %d: expected key = value", n)
+		}
+		sect[strings.TrimSpace(line[:i])] = value(strings.TrimSpace(line[i+1:]))
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// QQ parses r and applies jq.QQ to the result.  A parse error is returned as the result.
+func QQ(r io.Reader, index string) interface{} {
+	m, err := Parse(r)
+	if err != nil {
+		return err
+	}
+	return jq.QQ(m, index)
+}
+
+func value(s string) interface{} {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if s != "" && (s[0] == '-' || '0' <= s[0] && s[0] <= '9') && json.Valid([]byte(s)) {
+		return json.Number(s)
+	}
+	return s
+}
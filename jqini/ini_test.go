@@ -0,0 +1,68 @@
+package jqini
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+const testINI = `
+; global settings
+name = legacy
+
+[database]
+host = db.example.com
+port = 5432
+ssl  = true
+password = "p=ss ; word"
+
+# repeated sections merge
+[database]
+timeout: 2.5
+engine = NaN
+`
+
+func TestQQ(t *testing.T) {
+	for _, tc := range []struct {
+		path   string
+		expect interface{}
+	}{
+		{"name", "legacy"},
+		{"database/host", "db.example.com"},
+		{"database/port", json.Number("5432")},
+		{"database/ssl", true},
+		{"database/password", "p=ss ; word"},
+		{"database/timeout", json.Number("2.5")},
+		{"database/nosuchkey", nil},
+		{"database/engine", "NaN"},
+	} {
+		if v := QQ(strings.NewReader(testINI), tc.path); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("[%q]:  expected %v, got %v (%T)", tc.path, tc.expect, v, v)
+		}
+	}
+
+	m, err := Parse(strings.NewReader(testINI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := jq.Int(m, "database", "port"); v != 5432 {
+		t.Errorf("[%q]:  expected %v, got %v", "database/port", 5432, v)
+	}
+	if v := jq.String(m, "database", "port"); v != "5432" {
+		t.Errorf("[%q]:  expected %q, got %q", "database/port", "5432", v)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, s := range []string{"[database", "novalue", "database = x\n[database]\nhost = y"} {
+		if _, err := Parse(strings.NewReader(s)); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+	if _, err := Parse(strings.NewReader("database = x\n[database]")); err == nil || !strings.Contains(err.Error(), "line 2: section database conflicts") {
+		t.Errorf("conflicting section: got %v", err)
+	}
+}
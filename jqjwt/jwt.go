@@ -0,0 +1,128 @@
+/*
+Package jqjwt reads the header and claims of JSON Web Tokens in compact serialization,
+for programs that need a field or two of a token they have received:
+
+	tok, err := jqjwt.Parse(bearer)
+	if err != nil {
+		return err
+	}
+	tenant := tok.String("tenant", "id")
+	expires := tok.Time("exp")
+
+The signature is NOT verified, and neither are the expiry or any other claim, so nothing
+read with this package may be trusted for authentication or authorization decisions.
+Use it on tokens that have been verified elsewhere, such as by an API gateway, or for
+diagnostics. Encrypted tokens (JWE) are not supported.
+
+Numbers are decoded as json.Number, so Int and String read them without loss of precision.
+*/
+package jqjwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+// Token holds the decoded header and claims of a token.
+type Token struct {
+	Header map[string]interface{}
+	Claims map[string]interface{}
+}
+
+// Parse splits the compact serialization token, which may be prefixed with "Bearer ",
+// and decodes its header and claims without verifying its signature.
+func Parse(token string) (*Token, error) {
+	token = strings.TrimSpace(token)
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("jqjwt: token has %d segments, expected 3", len(parts))
+	}
+	t := &Token{}
+	if err := decodeSegment(parts[0], &t.Header); err != nil {
+		return nil, fmt.Errorf("jqjwt: header: %v", err)
+	}
+	if err := decodeSegment(parts[1], &t.Claims); err != nil {
+		return nil, fmt.Errorf("jqjwt: claims: %v", err)
+	}
+	return t, nil
+}
+
+// decodeSegment decodes the base64url encoded JSON object s into dst.
+func decodeSegment(s string, dst *map[string]interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err := d.Decode(dst); err != nil {
+		return err
+	}
+	if *dst == nil {
+		return errors.New("not a JSON object")
+	}
+	return nil
+}
+
+// Q returns the claim at index like jq.Q.
+func (t *Token) Q(index ...interface{}) interface{} {
+	return jq.Q(t.Claims, index...)
+}
+
+// QQ returns the claim at the slash separated path like jq.QQ.
+func (t *Token) QQ(path string) interface{} {
+	return jq.QQ(t.Claims, path)
+}
+
+// String returns the claim at index like jq.String.
+func (t *Token) String(index ...interface{}) string {
+	return jq.String(t.Claims, index...)
+}
+
+// Int returns the claim at index like jq.Int.
+func (t *Token) Int(index ...interface{}) int {
+	return jq.Int(t.Claims, index...)
+}
+
+// Bool returns the claim at index like jq.Bool.
+func (t *Token) Bool(index ...interface{}) bool {
+	return jq.Bool(t.Claims, index...)
+}
+
+// Time returns the claim at index as a time. Numbers are taken as NumericDate values,
+// seconds since the Unix epoch as used by the registered claims such as "exp" and "iat",
+// and strings are converted like jq.Time. It returns the zero time if the claim is not
+// present or cannot be converted.
+func (t *Token) Time(index ...interface{}) time.Time {
+	tm, err := jq.Get[time.Time](nil, t.Claims, index...)
+	if err != nil {
+		return time.Time{}
+	}
+	return tm
+}
+
+// Expired reports whether the "exp" claim of t is present and not after now.
+// Like everything else in this package, it does not make the token trustworthy.
+func (t *Token) Expired(now time.Time) bool {
+	exp := t.Time("exp")
+	return !exp.IsZero() && !now.Before(exp)
+}
+
+// QQ parses token and applies jq.QQ to its claims. A parse error is returned as the result.
+func QQ(token, path string) interface{} {
+	t, err := Parse(token)
+	if err != nil {
+		return err
+	}
+	return t.QQ(path)
+}
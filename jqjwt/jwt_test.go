@@ -0,0 +1,66 @@
+package jqjwt
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+func token(header, claims string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(header)) + "." + enc.EncodeToString([]byte(claims)) + ".c2lnbmF0dXJl"
+}
+
+func TestParse(t *testing.T) {
+	raw := token(`{"alg":"RS256","kid":"k1"}`, `{"sub":"u42","exp":1700000000,"admin":true,"tenant":{"id":9007199254740993},"roles":["a","b"]}`)
+
+	tok, err := Parse("Bearer " + raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := tok.String("sub"); v != "u42" {
+		t.Errorf("String: got %q", v)
+	}
+	if v := tok.String("tenant", "id"); v != "9007199254740993" {
+		t.Errorf("String of large number: got %q", v)
+	}
+	if v := tok.Int("exp"); v != 1700000000 {
+		t.Errorf("Int: got %v", v)
+	}
+	if !tok.Bool("admin") || tok.Bool("missing") {
+		t.Errorf("Bool: wrong result")
+	}
+	if v := tok.QQ("roles/1"); v != "b" {
+		t.Errorf("QQ: got %v", v)
+	}
+	if v := tok.Time("exp"); !v.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Time: got %v", v)
+	}
+	if v := tok.Time("missing"); !v.IsZero() {
+		t.Errorf("Time of missing claim: got %v", v)
+	}
+	if tok.Header["kid"] != "k1" {
+		t.Errorf("Header: got %v", tok.Header)
+	}
+	if !tok.Expired(time.Unix(1700000000, 0)) || tok.Expired(time.Unix(1699999999, 0)) {
+		t.Errorf("Expired: wrong result")
+	}
+	if v := QQ(raw, "sub"); v != "u42" {
+		t.Errorf("QQ: got %v", v)
+	}
+
+	for _, bad := range []string{
+		"",
+		"a.b",
+		token(`{"alg":"none"}`, `not json`),
+		token(`{"alg":"none"}`, `null`),
+		"!!!.e30.c2ln",
+	} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("%q: expected error", bad)
+		}
+	}
+	if _, ok := QQ("a.b", "sub").(error); !ok {
+		t.Errorf("QQ of invalid token: expected error")
+	}
+}
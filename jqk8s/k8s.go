@@ -0,0 +1,218 @@
+/*
+Package jqk8s provides path based accessors for Kubernetes objects in their unstructured form,
+the map[string]interface{} held by unstructured.Unstructured.Object.
+
+The Nested functions mirror the signatures and semantics of the helpers in
+k8s.io/apimachinery/pkg/apis/meta/v1/unstructured, but take a single slash separated path
+instead of a list of fields:
+
+	image, found, err := jqk8s.NestedString(obj, "spec/template/spec/containers/name=nginx/image")
+
+A path segment of the form key=value selects the first element of a list
+whose field key is the string value, which is how most lists in Kubernetes objects are keyed.
+Since label and annotation keys usually contain slashes, use Label and Annotation to read them.
+
+The package does not depend on the Kubernetes libraries.
+*/
+package jqk8s
+
+import (
+	"fmt"
+	"strings"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+// Q resolves path in obj like jq.QQ, with support for key=value selector segments.
+func Q(obj map[string]interface{}, path string) interface{} {
+	v, _ := resolve(obj, path)
+	return v
+}
+
+// resolve returns the value at path in obj, or an error, and whether the last segment of path
+// is present, which tells a null value from a missing one.
+func resolve(obj map[string]interface{}, path string) (interface{}, bool) {
+	var cur interface{} = obj
+	if path == "" {
+		return cur, true
+	}
+	for _, seg := range strings.Split(path, "/") {
+		if cur == nil {
+			return nil, false
+		}
+		found := true
+		if i := strings.IndexByte(seg, '='); i > 0 {
+			cur = selectElem(cur, seg[:i], seg[i+1:])
+			found = cur != nil
+		} else {
+			found = jq.Exists(cur, seg)
+			cur = jq.QQ(cur, seg)
+		}
+		if _, ok := cur.(error); ok {
+			return cur, false
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+func selectElem(cur interface{}, key, value string) interface{} {
+	l, ok := cur.([]interface{})
+	if !ok {
+		return fmt.Errorf("cannot select %s=%s in %T, expected []interface{}", key, value, cur)
+	}
+	for _, e := range l {
+		if s, ok := jq.Q(e, key).(string); ok && s == value {
+			return e
+		}
+	}
+	return nil
+}
+
+func nested(obj map[string]interface{}, path string) (interface{}, bool, error) {
+	v, found := resolve(obj, path)
+	if err, ok := v.(error); ok {
+		return nil, false, err
+	}
+	return v, found, nil
+}
+
+// NestedFieldNoCopy returns the value at path without copying it.
+// A field that is present with a null value is found.
+func NestedFieldNoCopy(obj map[string]interface{}, path string) (interface{}, bool, error) {
+	return nested(obj, path)
+}
+
+// NestedString returns the string at path.
+// It returns an error if the value is present but not a string.
+func NestedString(obj map[string]interface{}, path string) (string, bool, error) {
+	v, found, err := nested(obj, path)
+	if !found || err != nil {
+		return "", found, err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", false, fmt.Errorf("%v accessor error: %v is of the type %T, expected string", path, v, v)
+	}
+	return s, true, nil
+}
+
+// NestedBool returns the bool at path.
+// It returns an error if the value is present but not a bool.
+func NestedBool(obj map[string]interface{}, path string) (bool, bool, error) {
+	v, found, err := nested(obj, path)
+	if !found || err != nil {
+		return false, found, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, false, fmt.Errorf("%v accessor error: %v is of the type %T, expected bool", path, v, v)
+	}
+	return b, true, nil
+}
+
+// NestedInt64 returns the int64 at path.
+// It returns an error if the value is present but not an int64.
+func NestedInt64(obj map[string]interface{}, path string) (int64, bool, error) {
+	v, found, err := nested(obj, path)
+	if !found || err != nil {
+		return 0, found, err
+	}
+	i, ok := v.(int64)
+	if !ok {
+		return 0, false, fmt.Errorf("%v accessor error: %v is of the type %T, expected int64", path, v, v)
+	}
+	return i, true, nil
+}
+
+// NestedFloat64 returns the float64 at path.
+// It returns an error if the value is present but not a float64.
+func NestedFloat64(obj map[string]interface{}, path string) (float64, bool, error) {
+	v, found, err := nested(obj, path)
+	if !found || err != nil {
+		return 0, found, err
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return 0, false, fmt.Errorf("%v accessor error: %v is of the type %T, expected float64", path, v, v)
+	}
+	return f, true, nil
+}
+
+// NestedSlice returns the []interface{} at path.
+// It returns an error if the value is present but not a []interface{}.
+func NestedSlice(obj map[string]interface{}, path string) ([]interface{}, bool, error) {
+	v, found, err := nested(obj, path)
+	if !found || err != nil {
+		return nil, found, err
+	}
+	s, ok := v.([]interface{})
+	if !ok {
+		return nil, false, fmt.Errorf("%v accessor error: %v is of the type %T, expected []interface{}", path, v, v)
+	}
+	return s, true, nil
+}
+
+// NestedStringSlice returns the strings in the []interface{} at path.
+// It returns an error if the value is present but not a slice of strings.
+func NestedStringSlice(obj map[string]interface{}, path string) ([]string, bool, error) {
+	s, found, err := NestedSlice(obj, path)
+	if !found || err != nil {
+		return nil, found, err
+	}
+	r := make([]string, 0, len(s))
+	for _, e := range s {
+		str, ok := e.(string)
+		if !ok {
+			return nil, false, fmt.Errorf("%v accessor error: contains non-string value %v of the type %T", path, e, e)
+		}
+		r = append(r, str)
+	}
+	return r, true, nil
+}
+
+// NestedMap returns the map[string]interface{} at path.
+// It returns an error if the value is present but not a map[string]interface{}.
+func NestedMap(obj map[string]interface{}, path string) (map[string]interface{}, bool, error) {
+	v, found, err := nested(obj, path)
+	if !found || err != nil {
+		return nil, found, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false, fmt.Errorf("%v accessor error: %v is of the type %T, expected map[string]interface{}", path, v, v)
+	}
+	return m, true, nil
+}
+
+// NestedStringMap returns the string values of the map[string]interface{} at path.
+// It returns an error if the value is present but not a map of strings.
+func NestedStringMap(obj map[string]interface{}, path string) (map[string]string, bool, error) {
+	m, found, err := NestedMap(obj, path)
+	if !found || err != nil {
+		return nil, found, err
+	}
+	r := make(map[string]string, len(m))
+	for k, e := range m {
+		str, ok := e.(string)
+		if !ok {
+			return nil, false, fmt.Errorf("%v accessor error: contains non-string value %v of the type %T for key %q", path, e, e, k)
+		}
+		r[k] = str
+	}
+	return r, true, nil
+}
+
+// Label returns the value of the label key in metadata/labels.
+func Label(obj map[string]interface{}, key string) (string, bool) {
+	s, ok := jq.Q(obj, "metadata", "labels", key).(string)
+	return s, ok
+}
+
+// Annotation returns the value of the annotation key in metadata/annotations.
+func Annotation(obj map[string]interface{}, key string) (string, bool) {
+	s, ok := jq.Q(obj, "metadata", "annotations", key).(string)
+	return s, ok
+}
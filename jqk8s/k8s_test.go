@@ -0,0 +1,94 @@
+package jqk8s
+
+import (
+	"reflect"
+	"testing"
+)
+
+var testPod = map[string]interface{}{
+	"apiVersion": "v1",
+	"kind":       "Pod",
+	"metadata": map[string]interface{}{
+		"name": "web",
+		"labels": map[string]interface{}{
+			"app.kubernetes.io/name": "nginx",
+		},
+		"annotations": map[string]interface{}{
+			"example.com/owner": "team-a",
+		},
+	},
+	"spec": map[string]interface{}{
+		"hostNetwork": true,
+		"containers": []interface{}{
+			map[string]interface{}{"name": "sidecar", "image": "envoy"},
+			map[string]interface{}{
+				"name":  "nginx",
+				"image": "nginx:1.25",
+				"args":  []interface{}{"-g", "daemon off;"},
+				"ports": []interface{}{map[string]interface{}{"containerPort": int64(80)}},
+			},
+		},
+	},
+}
+
+func TestNested(t *testing.T) {
+	if s, found, err := NestedString(testPod, "spec/containers/name=nginx/image"); s != "nginx:1.25" || !found || err != nil {
+		t.Errorf("NestedString: got %q, %v, %v", s, found, err)
+	}
+	if s, found, err := NestedString(testPod, "spec/containers/name=nosuch/image"); s != "" || found || err != nil {
+		t.Errorf("NestedString missing: got %q, %v, %v", s, found, err)
+	}
+	if _, found, err := NestedString(testPod, "spec/hostNetwork"); found || err == nil {
+		t.Errorf("NestedString wrong type: got %v, %v", found, err)
+	}
+	if _, found, err := NestedString(testPod, "kind=Pod"); found || err == nil {
+		t.Errorf("NestedString selector on map: got %v, %v", found, err)
+	}
+	if b, found, err := NestedBool(testPod, "spec/hostNetwork"); !b || !found || err != nil {
+		t.Errorf("NestedBool: got %v, %v, %v", b, found, err)
+	}
+	if i, found, err := NestedInt64(testPod, "spec/containers/1/ports/0/containerPort"); i != 80 || !found || err != nil {
+		t.Errorf("NestedInt64: got %v, %v, %v", i, found, err)
+	}
+	if _, found, err := NestedFloat64(testPod, "spec/containers/1/ports/0/containerPort"); found || err == nil {
+		t.Errorf("NestedFloat64 wrong type: got %v, %v", found, err)
+	}
+	if a, found, err := NestedStringSlice(testPod, "spec/containers/name=nginx/args"); !reflect.DeepEqual(a, []string{"-g", "daemon off;"}) || !found || err != nil {
+		t.Errorf("NestedStringSlice: got %v, %v, %v", a, found, err)
+	}
+	if m, found, err := NestedStringMap(testPod, "metadata/labels"); m["app.kubernetes.io/name"] != "nginx" || !found || err != nil {
+		t.Errorf("NestedStringMap: got %v, %v, %v", m, found, err)
+	}
+	if _, found, err := NestedMap(testPod, "spec/containers"); found || err == nil {
+		t.Errorf("NestedMap wrong type: got %v, %v", found, err)
+	}
+	if v, found, err := NestedFieldNoCopy(testPod, ""); !found || err != nil || !reflect.DeepEqual(v, testPod) {
+		t.Errorf("NestedFieldNoCopy: got %v, %v", found, err)
+	}
+
+	withNull := map[string]interface{}{"spec": map[string]interface{}{"x": nil}}
+	if v, found, err := NestedFieldNoCopy(withNull, "spec/x"); v != nil || !found || err != nil {
+		t.Errorf("NestedFieldNoCopy null: got %v, %v, %v", v, found, err)
+	}
+	if _, found, err := NestedFieldNoCopy(withNull, "spec/y"); found || err != nil {
+		t.Errorf("NestedFieldNoCopy missing: got %v, %v", found, err)
+	}
+	if _, found, err := NestedFieldNoCopy(withNull, "spec/x/y"); found || err != nil {
+		t.Errorf("NestedFieldNoCopy below null: got %v, %v", found, err)
+	}
+	if _, found, err := NestedString(withNull, "spec/x"); found || err == nil {
+		t.Errorf("NestedString null: got %v, %v", found, err)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	if s, ok := Label(testPod, "app.kubernetes.io/name"); s != "nginx" || !ok {
+		t.Errorf("Label: got %q, %v", s, ok)
+	}
+	if s, ok := Annotation(testPod, "example.com/owner"); s != "team-a" || !ok {
+		t.Errorf("Annotation: got %q, %v", s, ok)
+	}
+	if _, ok := Label(testPod, "nosuch"); ok {
+		t.Errorf("Label: expected not found")
+	}
+}
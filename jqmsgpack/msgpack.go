@@ -0,0 +1,283 @@
+/*
+Package jqmsgpack decodes MessagePack data into the generic values understood by package jq
+and provides shorthands to query encoded bytes directly.
+
+Maps whose keys are all strings decode to map[string]interface{}, other maps to
+map[interface{}]interface{}.  Signed integers decode to int64, unsigned integers to uint64,
+floats to float64, strings to string and binary data to []byte.  The timestamp extension
+decodes to time.Time, all other extension types to Ext.
+*/
+package jqmsgpack
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+// Ext holds the payload of an application-defined extension type.
+// Its fields can be queried with the paths "type" and "data".
+type Ext struct {
+	Type int8
+	Data []byte
+}
+
+var errShort = errors.New("jqmsgpack: unexpected end of data")
+
+// Unmarshal decodes the single MessagePack value in b.
+func Unmarshal(b []byte) (interface{}, error) {
+	d := decoder{b: b}
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(b) {
+		return nil, fmt.Errorf("jqmsgpack: %d trailing bytes after value", len(b)-d.off)
+	}
+	return v, nil
+}
+
+// Q decodes b and applies jq.Q to the result.  A decoding error is returned as the result.
+func Q(b []byte, index ...interface{}) interface{} {
+	v, err := Unmarshal(b)
+	if err != nil {
+		return err
+	}
+	return jq.Q(v, index...)
+}
+
+// QQ decodes b and applies jq.QQ to the result.  A decoding error is returned as the result.
+func QQ(b []byte, index string) interface{} {
+	v, err := Unmarshal(b)
+	if err != nil {
+		return err
+	}
+	return jq.QQ(v, index)
+}
+
+type decoder struct {
+	b   []byte
+	off int
+}
+
+func (d *decoder) next(n int) ([]byte, error) {
+	if n < 0 || len(d.b)-d.off < n {
+		return nil, errShort
+	}
+	p := d.b[d.off : d.off+n]
+	d.off += n
+	return p, nil
+}
+
+// uint reads a big-endian unsigned integer of n bytes.
+func (d *decoder) uint(n int) (uint64, error) {
+	p, err := d.next(n)
+	if err != nil {
+		return 0, err
+	}
+	var u uint64
+	for _, c := range p {
+		u = u<<8 | uint64(c)
+	}
+	return u, nil
+}
+
+func (d *decoder) value() (interface{}, error) {
+	p, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	c := p[0]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.mapping(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return d.array(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return d.bin(int(n))
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(int(n))
+	case 0xca:
+		u, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.uint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return d.uint(1 << (c - 0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		n := 1 << (c - 0xd0)
+		u, err := d.uint(n)
+		if err != nil {
+			return nil, err
+		}
+		shift := uint(64 - 8*n) // sign extend
+		return int64(u<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.str(int(n))
+	case 0xdc, 0xdd:
+		n, err := d.uint(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(int(n))
+	case 0xde, 0xdf:
+		n, err := d.uint(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.mapping(int(n))
+	}
+	return nil, fmt.Errorf("jqmsgpack: invalid code %#x at offset %d", c, d.off-1)
+}
+
+func (d *decoder) str(n int) (interface{}, error) {
+	p, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return string(p), nil
+}
+
+func (d *decoder) bin(n int) (interface{}, error) {
+	p, err := d.next(n)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), p...), nil
+}
+
+func (d *decoder) ext(n int) (interface{}, error) {
+	p, err := d.next(1)
+	if err != nil {
+		return nil, err
+	}
+	typ := int8(p[0])
+	if p, err = d.next(n); err != nil {
+		return nil, err
+	}
+	if typ == -1 {
+		return timestamp(p)
+	}
+	return Ext{Type: typ, Data: append([]byte(nil), p...)}, nil
+}
+
+func timestamp(p []byte) (interface{}, error) {
+	switch len(p) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(p)), 0).UTC(), nil
+	case 8:
+		u := binary.BigEndian.Uint64(p)
+		return time.Unix(int64(u&(1<<34-1)), int64(u>>34)).UTC(), nil
+	case 12:
+		ns := binary.BigEndian.Uint32(p)
+		return time.Unix(int64(binary.BigEndian.Uint64(p[4:])), int64(ns)).UTC(), nil
+	}
+	return nil, fmt.Errorf("jqmsgpack: invalid timestamp length %d", len(p))
+}
+
+func (d *decoder) array(n int) (interface{}, error) {
+	if n > len(d.b)-d.off { // every element takes at least one byte
+		return nil, errShort
+	}
+	a := make([]interface{}, n)
+	for i := range a {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		a[i] = v
+	}
+	return a, nil
+}
+
+func (d *decoder) mapping(n int) (interface{}, error) {
+	if 2*n > len(d.b)-d.off {
+		return nil, errShort
+	}
+	keys := make([]interface{}, n)
+	vals := make([]interface{}, n)
+	bin := make([]bool, n) // whether keys[i] was converted from []byte
+	strs := true
+	for i := 0; i < n; i++ {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		switch k.(type) {
+		case string:
+		case []byte:
+			k = string(k.([]byte)) // byte slices cannot be map keys
+			bin[i], strs = true, false
+		default:
+			strs = false
+		}
+		if keys[i] = k; !isHashable(k) {
+			return nil, fmt.Errorf("jqmsgpack: unsupported map key type %T", k)
+		}
+		if vals[i], err = d.value(); err != nil {
+			return nil, err
+		}
+	}
+	if strs {
+		m := make(map[string]interface{}, n)
+		for i, k := range keys {
+			m[k.(string)] = vals[i]
+		}
+		return m, nil
+	}
+	m := make(map[interface{}]interface{}, n)
+	binary := make(map[interface{}]bool)
+	for i, k := range keys {
+		if _, dup := m[k]; dup && (bin[i] || binary[k]) {
+			return nil, fmt.Errorf("jqmsgpack: binary map key %q duplicates another key", k)
+		}
+		m[k] = vals[i]
+		if bin[i] {
+			binary[k] = true
+		}
+	}
+	return m, nil
+}
+
+func isHashable(k interface{}) bool {
+	switch k.(type) {
+	case []interface{}, map[string]interface{}, map[interface{}]interface{}, Ext:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package jqmsgpack
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+// {"id": uint64 max, "n": -2, "tags": ["a", "b"], "bin": 0x01 0x02, "ext": ext 5 [7], "ts": 1970-01-01T00:00:10Z, 1: "one"}
+var testMsg = []byte{
+	0x87,
+	0xa2, 'i', 'd', 0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xa1, 'n', 0xfe,
+	0xa4, 't', 'a', 'g', 's', 0x92, 0xa1, 'a', 0xa1, 'b',
+	0xa3, 'b', 'i', 'n', 0xc4, 0x02, 0x01, 0x02,
+	0xa3, 'e', 'x', 't', 0xd4, 0x05, 0x07,
+	0xa2, 't', 's', 0xd6, 0xff, 0x00, 0x00, 0x00, 0x0a,
+	0x01, 0xa3, 'o', 'n', 'e',
+}
+
+func TestQQ(t *testing.T) {
+	for _, tc := range []struct {
+		path   string
+		expect interface{}
+	}{
+		{"id", uint64(1<<64 - 1)},
+		{"n", int64(-2)},
+		{"tags/1", "b"},
+		{"tags/*", []interface{}{"a", "b"}},
+		{"bin", []byte{1, 2}},
+		{"ext", Ext{Type: 5, Data: []byte{7}}},
+		{"ext/type", int8(5)},
+		{"ts", time.Unix(10, 0).UTC()},
+		{"1", "one"},
+		{"nosuchkey", nil},
+	} {
+		if v := QQ(testMsg, tc.path); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("[%q]:  expected %v, got %v (%T)", tc.path, tc.expect, v, v)
+		}
+	}
+}
+
+func TestGetters(t *testing.T) {
+	v, err := Unmarshal(testMsg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := jq.Int(v, "n"); n != -2 {
+		t.Errorf("[%q]:  expected %v, got %v", "n", -2, n)
+	}
+	if b := jq.Bool(v, "id"); !b {
+		t.Errorf("[%q]:  expected %v, got %v", "id", true, b)
+	}
+	if s := jq.String(v, "bin"); s != "\x01\x02" {
+		t.Errorf("[%q]:  expected %q, got %q", "bin", "\x01\x02", s)
+	}
+	if ts := jq.Time(v, "ts"); !ts.Equal(time.Unix(10, 0)) {
+		t.Errorf("[%q]:  expected %v, got %v", "ts", time.Unix(10, 0), ts)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, b := range [][]byte{
+		nil,
+		{0xc1},             // never used
+		{0x92, 0x01},       // short array
+		{0xa3, 'a'},        // short string
+		{0x01, 0x02},       // trailing data
+		{0x81, 0x90, 0x01}, // array as map key
+		{0x82, 0xa1, 'a', 0x01, 0xc4, 0x01, 'a', 0x02}, // {"a": 1, bin "a": 2}
+		{0x82, 0xc4, 0x01, 'a', 0x01, 0xa1, 'a', 0x02}, // {bin "a": 1, "a": 2}
+	} {
+		if _, err := Unmarshal(b); err == nil {
+			t.Errorf("%x: expected error", b)
+		}
+		if _, ok := QQ(b, "").(error); !ok {
+			t.Errorf("%x: expected error result", b)
+		}
+	}
+}
+
+func TestNilKey(t *testing.T) {
+	msg := []byte{0x82, 0xc0, 0x01, 0xa1, 'a', 0x02} // {nil: 1, "a": 2}
+	if v := Q(msg, jq.KEYS); !reflect.DeepEqual(v, []interface{}{nil, "a"}) {
+		t.Errorf("KEYS: got %v", v)
+	}
+	if v := Q(msg, nil); v != int64(1) {
+		t.Errorf("nil key: got %v (%T)", v, v)
+	}
+}
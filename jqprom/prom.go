@@ -0,0 +1,102 @@
+/*
+Package jqprom reports the queries of a jq.Engine to Prometheus.
+
+	m, err := jqprom.New(prometheus.DefaultRegisterer, "myapp")
+	if err != nil {
+		return err
+	}
+	e := jq.NewEngine(jq.WithMetrics(m))
+
+It registers the counter <namespace>_jq_queries_total, labeled by path and outcome,
+and the histogram <namespace>_jq_query_duration_seconds, labeled by path.
+
+Every distinct path creates its own series, so to bound their number only the first
+DefaultMaxPaths paths, or the number given with MaxPaths, are labeled with the path;
+queries of later paths are counted under the path label OtherPath.
+*/
+package jqprom
+
+import (
+	"sync"
+	"time"
+
+	jq "github.com/gtrevg/go-jq"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultMaxPaths is the number of distinct paths labeled by default.
+const DefaultMaxPaths = 100
+
+// OtherPath is the path label of the queries beyond the maximum number of paths.
+const OtherPath = "other"
+
+// Option configures Metrics.
+type Option func(*Metrics)
+
+// MaxPaths sets the number of distinct paths labeled with their path to n.
+func MaxPaths(n int) Option {
+	return func(m *Metrics) { m.maxPaths = n }
+}
+
+// Metrics implements jq.Metrics with Prometheus collectors.
+type Metrics struct {
+	queries  *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+
+	maxPaths int
+	mu       sync.Mutex
+	paths    map[string]bool
+}
+
+// New creates the collectors and registers them with reg.
+// If registering fails, the collectors registered so far are unregistered.
+func New(reg prometheus.Registerer, namespace string, opts ...Option) (*Metrics, error) {
+	m := &Metrics{
+		maxPaths: DefaultMaxPaths,
+		paths:    make(map[string]bool),
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "jq_queries_total",
+			Help:      "Number of queries by path and outcome (found, null, missing or error).",
+		}, []string{"path", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "jq_query_duration_seconds",
+			Help:      "Duration of queries by path.",
+			Buckets:   prometheus.ExponentialBuckets(1e-6, 4, 10),
+		}, []string{"path"}),
+	}
+	for _, o := range opts {
+		o(m)
+	}
+	collectors := []prometheus.Collector{m.queries, m.duration}
+	for i, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			for _, r := range collectors[:i] {
+				reg.Unregister(r)
+			}
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// label returns the path label for path.
+func (m *Metrics) label(path string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.paths[path] {
+		if len(m.paths) >= m.maxPaths {
+			return OtherPath
+		}
+		m.paths[path] = true
+	}
+	return path
+}
+
+// ObserveQuery implements jq.Metrics.
+func (m *Metrics) ObserveQuery(path string, outcome jq.Outcome, d time.Duration) {
+	path = m.label(path)
+	m.queries.WithLabelValues(path, outcome.String()).Inc()
+	m.duration.WithLabelValues(path).Observe(d.Seconds())
+}
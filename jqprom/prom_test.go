@@ -0,0 +1,88 @@
+package jqprom
+
+import (
+	"testing"
+
+	jq "github.com/gtrevg/go-jq"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := New(reg, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := map[string]interface{}{"user": map[string]interface{}{"name": "ann", "phone": nil}}
+	e := jq.NewEngine(jq.WithMetrics(m))
+	e.QQ(root, "user/name")
+	e.QQ(root, "user/name")
+	e.QQ(root, "user/email")
+	e.QQ(root, "user/phone")
+	e.QQ(root, "user/name/x")
+
+	for _, tc := range []struct {
+		path, outcome string
+		expect        float64
+	}{
+		{"user/name", "found", 2},
+		{"user/email", "missing", 1},
+		{"user/email", "null", 0},
+		{"user/phone", "null", 1},
+		{"user/name/x", "error", 1},
+		{"user/name", "error", 0},
+	} {
+		if v := testutil.ToFloat64(m.queries.WithLabelValues(tc.path, tc.outcome)); v != tc.expect {
+			t.Errorf("%s %s: expected %v, got %v", tc.path, tc.outcome, tc.expect, v)
+		}
+	}
+	if n := testutil.CollectAndCount(m.duration); n != 4 {
+		t.Errorf("duration: expected 4 series, got %d", n)
+	}
+
+	if _, err := New(reg, "test"); err == nil {
+		t.Errorf("New: expected error registering twice")
+	}
+}
+
+func TestMaxPaths(t *testing.T) {
+	m, err := New(prometheus.NewRegistry(), "test", MaxPaths(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := map[string]interface{}{"a": 1, "b": 2, "c": 3, "d": 4}
+	e := jq.NewEngine(jq.WithMetrics(m))
+	for _, path := range []string{"a", "b", "c", "d", "a"} {
+		e.QQ(root, path)
+	}
+	for _, tc := range []struct {
+		path   string
+		expect float64
+	}{
+		{"a", 2},
+		{"b", 1},
+		{"c", 0},
+		{OtherPath, 2},
+	} {
+		if v := testutil.ToFloat64(m.queries.WithLabelValues(tc.path, "found")); v != tc.expect {
+			t.Errorf("%s: expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+}
+
+func TestRegisterFailure(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	// a collector that only conflicts with the second one New registers
+	reg.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{Namespace: "test", Name: "jq_query_duration_seconds"}))
+	if _, err := New(reg, "test"); err == nil {
+		t.Fatal("New: expected error")
+	}
+	if err := reg.Register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "test",
+		Name:      "jq_queries_total",
+		Help:      "Number of queries by path and outcome (found, null, missing or error).",
+	}, []string{"path", "outcome"})); err != nil {
+		t.Errorf("New left the first collector registered: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Package jqtest provides test assertions on values at paths in documents:
+
+	func TestGetUser(t *testing.T) {
+		var body interface{}
+		json.Unmarshal(resp, &body)
+		jqtest.Equal(t, body, "user/name", "alice")
+		jqtest.Len(t, body, "user/roles", 2)
+		jqtest.MatchJSON(t, body, "user/address", `{"city": "Paris", "zip": "75001"}`)
+	}
+
+Paths are slash separated as accepted by jq.QQ. A failing assertion reports the path and
+the value found there, rendered by jq.Sdump, and lets the test continue. Each assertion
+returns whether it held, so tests can stop when later checks depend on it.
+*/
+package jqtest
+
+import (
+	"encoding/json"
+	"testing"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+// Equal asserts that the value at path in root equals want according to jq.Equal,
+// so numbers of different types are equal if they have the same value.
+func Equal(t testing.TB, root interface{}, path string, want interface{}) bool {
+	t.Helper()
+	v, err := jq.QQE(root, path)
+	if err != nil {
+		t.Errorf("%s: %v\nwant: %s", path, err, jq.Sdump(want))
+		return false
+	}
+	if !jq.Equal(v, want) {
+		t.Errorf("%s: not equal\ngot:  %swant: %s", path, jq.Sdump(v), jq.Sdump(want))
+		return false
+	}
+	return true
+}
+
+// Exists asserts that a value, possibly null, is present at path in root.
+func Exists(t testing.TB, root interface{}, path string) bool {
+	t.Helper()
+	if _, err := jq.QQE(root, path); err != nil {
+		t.Errorf("%s: %v\nin: %s", path, err, jq.Sdump(root))
+		return false
+	}
+	return true
+}
+
+// Missing asserts that no value is present at path in root.
+func Missing(t testing.TB, root interface{}, path string) bool {
+	t.Helper()
+	if v, err := jq.QQE(root, path); err == nil {
+		t.Errorf("%s: expected no value, got %s", path, jq.Sdump(v))
+		return false
+	}
+	return true
+}
+
+// Len asserts that the value at path in root is an array, object or string of length n.
+func Len(t testing.TB, root interface{}, path string, n int) bool {
+	t.Helper()
+	v, err := jq.QQE(root, path)
+	if err != nil {
+		t.Errorf("%s: %v", path, err)
+		return false
+	}
+	var l int
+	switch jq.KindOf(v) {
+	case jq.KindArray, jq.KindObject:
+		l = len(jq.Values(v))
+	case jq.KindString:
+		l = len(jq.String(v))
+	default:
+		t.Errorf("%s: expected a value with a length, got %s", path, jq.Sdump(v))
+		return false
+	}
+	if l != n {
+		t.Errorf("%s: expected length %d, got %d: %s", path, n, l, jq.Sdump(v))
+		return false
+	}
+	return true
+}
+
+// MatchJSON asserts that the value at path in root equals the JSON document want
+// according to jq.Equal, ignoring the order of object keys and formatting.
+func MatchJSON(t testing.TB, root interface{}, path string, want string) bool {
+	t.Helper()
+	var w interface{}
+	if err := json.Unmarshal([]byte(want), &w); err != nil {
+		t.Errorf("%s: invalid JSON %q: %v", path, want, err)
+		return false
+	}
+	v, err := jq.QQE(root, path)
+	if err != nil {
+		t.Errorf("%s: %v\nwant: %s", path, err, jq.Sdump(w))
+		return false
+	}
+	// compare in the JSON form, so that structs and times match their encoding
+	if b, err := json.Marshal(v); err == nil {
+		json.Unmarshal(b, &v)
+	}
+	if !jq.Equal(v, w) {
+		t.Errorf("%s: does not match JSON\ngot:  %swant: %s", path, jq.Sdump(v), jq.Sdump(w))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package jqtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// recorder is a testing.TB that records failures instead of reporting them.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+type address struct {
+	City string `json:"city"`
+	Zip  string `json:"zip"`
+}
+
+var doc = map[string]interface{}{
+	"user": map[string]interface{}{
+		"name":    "alice",
+		"age":     30.,
+		"roles":   []interface{}{"admin", "dev"},
+		"manager": nil,
+		"address": address{"Paris", "75001"},
+	},
+}
+
+func TestAssertions(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		check  func(t testing.TB) bool
+		failed string // part of the failure message, if it fails
+	}{
+		{"Equal", func(t testing.TB) bool { return Equal(t, doc, "user/name", "alice") }, ""},
+		{"Equal number", func(t testing.TB) bool { return Equal(t, doc, "user/age", 30) }, ""},
+		{"Equal mismatch", func(t testing.TB) bool { return Equal(t, doc, "user/name", "bob") }, `user/name: not equal`},
+		{"Equal missing", func(t testing.TB) bool { return Equal(t, doc, "user/nick", "al") }, "path not found"},
+		{"Exists", func(t testing.TB) bool { return Exists(t, doc, "user/manager") }, ""},
+		{"Exists missing", func(t testing.TB) bool { return Exists(t, doc, "user/nick") }, "user/nick"},
+		{"Missing", func(t testing.TB) bool { return Missing(t, doc, "user/nick") }, ""},
+		{"Missing present", func(t testing.TB) bool { return Missing(t, doc, "user/name") }, `"alice"`},
+		{"Len array", func(t testing.TB) bool { return Len(t, doc, "user/roles", 2) }, ""},
+		{"Len object", func(t testing.TB) bool { return Len(t, doc, "user", 5) }, ""},
+		{"Len string", func(t testing.TB) bool { return Len(t, doc, "user/name", 5) }, ""},
+		{"Len mismatch", func(t testing.TB) bool { return Len(t, doc, "user/roles", 3) }, "expected length 3, got 2"},
+		{"Len number", func(t testing.TB) bool { return Len(t, doc, "user/age", 1) }, "expected a value with a length"},
+		{"MatchJSON", func(t testing.TB) bool {
+			return MatchJSON(t, doc, "user/address", `{"zip": "75001", "city": "Paris"}`)
+		}, ""},
+		{"MatchJSON array", func(t testing.TB) bool { return MatchJSON(t, doc, "user/roles", `["admin", "dev"]`) }, ""},
+		{"MatchJSON mismatch", func(t testing.TB) bool { return MatchJSON(t, doc, "user/roles", `["dev"]`) }, "does not match JSON"},
+		{"MatchJSON invalid", func(t testing.TB) bool { return MatchJSON(t, doc, "user/roles", `[`) }, "invalid JSON"},
+	} {
+		r := &recorder{TB: t}
+		ok := tc.check(r)
+		if ok != (tc.failed == "") || ok != (len(r.errs) == 0) {
+			t.Errorf("%s: returned %v with failures %q", tc.name, ok, r.errs)
+			continue
+		}
+		if !ok && !strings.Contains(r.errs[0], tc.failed) {
+			t.Errorf("%s: expected failure containing %q, got %q", tc.name, tc.failed, r.errs[0])
+		}
+	}
+}
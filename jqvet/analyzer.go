@@ -0,0 +1,323 @@
+/*
+Package jqvet provides an analyzer that checks the constant paths passed to the functions
+of package jq against the static type of the root they are applied to.
+
+When the root of a query has a concrete struct type, Q resolves each path element against
+the fields of that type, so a path naming a field that does not exist can never find a value:
+
+	type Config struct{ Server struct{ Port int } }
+	jq.Int(cfg, "Server", "Prot") // jq path "Server/Prot": struct{Port int} has no field Prot
+
+The analyzer follows paths through structs, arrays, slices and maps, and reports
+path elements naming missing fields, non-numeric array indices and indexing into values
+that are not containers, including pointers, which Q does not dereference. It stops checking
+at interface types, strings (which may hold JSON), operators such as "@base64d" and types
+that Q treats specially, such as json.RawMessage, so it reports no false positives
+for dynamic documents.
+
+Run it with the jqvet command, or add Analyzer to a multichecker.
+*/
+package jqvet
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer reports jq paths that cannot resolve against the static type of their root.
+var Analyzer = &analysis.Analyzer{
+	Name:     "jqpath",
+	Doc:      "check constant jq paths against the static type of the root",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+const jqPath = "github.com/gtrevg/go-jq"
+
+// signature locates the root and the path in the arguments of a function of package jq.
+type signature struct {
+	root  int  // index of the root argument
+	path  int  // index of the path argument, or the first index argument
+	index bool // the path is given as variadic index elements rather than a slash separated string
+}
+
+var funcs = map[string]signature{
+	"Q":             {0, 1, true},
+	"String":        {0, 1, true},
+	"Bool":          {0, 1, true},
+	"Int":           {0, 1, true},
+	"Int64E":        {0, 1, true},
+	"UintE":         {0, 1, true},
+	"Uint64E":       {0, 1, true},
+	"Uint":          {0, 1, true},
+	"UintOK":        {0, 1, true},
+	"Time":          {0, 1, true},
+	"Exists":        {0, 1, true},
+	"Has":           {0, 1, true},
+	"Values":        {0, 1, true},
+	"QOpt":          {0, 1, true},
+	"QCtx":          {1, 2, true},
+	"QQ":            {0, 1, false},
+	"DecodePath":    {0, 1, false},
+	"UnmarshalPath": {0, 1, false},
+	"MarshalAt":     {0, 1, false},
+	"DeepCopyAt":    {0, 1, false},
+	"EqualValueAt":  {0, 1, false},
+	"Iter":          {0, 1, false},
+	"Each":          {0, 1, false},
+	"Filter":        {0, 1, false},
+	"CountWhere":    {0, 1, false},
+	"Reduce":        {0, 1, false},
+	"Contains":      {0, 1, false},
+	"From":          {0, 1, false},
+}
+
+// all stands for the ALL quantifier in a path.
+type all struct{}
+
+// operator stands for an operator, such as "@base64d", in a path.
+type operator struct{}
+
+// operators are the names of the operators of package jq.
+var operators = map[string]bool{"@base64d": true, "@hexd": true, "@decompress": true}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		fn, ok := callee(pass.TypesInfo, call).(*types.Func)
+		if !ok || fn.Pkg() == nil || fn.Pkg().Path() != jqPath {
+			return
+		}
+		if sig, ok := fn.Type().(*types.Signature); !ok || sig.Recv() != nil {
+			return
+		}
+		s, ok := funcs[fn.Name()]
+		if !ok || len(call.Args) <= s.path || call.Ellipsis.IsValid() {
+			return
+		}
+		var (
+			path []interface{}
+			text string
+		)
+		if s.index {
+			path, text = indexElements(pass, call.Args[s.path:])
+		} else {
+			tv := pass.TypesInfo.Types[call.Args[s.path]]
+			if tv.Value == nil || tv.Value.Kind() != constant.String {
+				return
+			}
+			text = constant.StringVal(tv.Value)
+			path = splitPath(text)
+		}
+		root := pass.TypesInfo.TypeOf(call.Args[s.root])
+		if root == nil {
+			return
+		}
+		if msg := check(root, path); msg != "" {
+			pass.Reportf(call.Args[s.path].Pos(), "jq path %q: %s", text, msg)
+		}
+	})
+	return nil, nil
+}
+
+// callee returns the object of the function called by call, if it is statically known.
+func callee(info *types.Info, call *ast.CallExpr) types.Object {
+	fun := ast.Unparen(call.Fun)
+	if ix, ok := fun.(*ast.IndexExpr); ok {
+		fun = ix.X
+	}
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return info.Uses[f]
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[f]; ok {
+			return sel.Obj()
+		}
+		return info.Uses[f.Sel]
+	}
+	return nil
+}
+
+// indexElements returns the leading constant elements of the index arguments args,
+// and their text for messages. Elements after the first one that is not constant, or that is
+// a quantifier other than ALL, such as KEYS, are ignored.
+func indexElements(pass *analysis.Pass, args []ast.Expr) ([]interface{}, string) {
+	var (
+		path []interface{}
+		text []string
+	)
+	for _, a := range args {
+		if q, ok := quantifier(pass, a); ok {
+			if q != "ALL" {
+				break
+			}
+			path, text = append(path, all{}), append(text, "*")
+			continue
+		}
+		tv := pass.TypesInfo.Types[a]
+		if tv.Value == nil {
+			break
+		}
+		switch tv.Value.Kind() {
+		case constant.String:
+			s := constant.StringVal(tv.Value)
+			path, text = append(path, s), append(text, s)
+		case constant.Int:
+			n, ok := constant.Int64Val(tv.Value)
+			if !ok {
+				return path, strings.Join(text, "/")
+			}
+			path, text = append(path, n), append(text, strconv.FormatInt(n, 10))
+		default:
+			return path, strings.Join(text, "/")
+		}
+	}
+	return path, strings.Join(text, "/")
+}
+
+// quantifier returns the name of the constant of type jq.quantifier e refers to, such as ALL or KEYS.
+func quantifier(pass *analysis.Pass, e ast.Expr) (string, bool) {
+	var id *ast.Ident
+	switch x := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		id = x
+	case *ast.SelectorExpr:
+		id = x.Sel
+	default:
+		return "", false
+	}
+	obj, ok := pass.TypesInfo.Uses[id].(*types.Const)
+	if !ok {
+		return "", false
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != jqPath || named.Obj().Name() != "quantifier" {
+		return "", false
+	}
+	return obj.Name(), true
+}
+
+// splitPath splits a slash separated path like QQ does.
+func splitPath(p string) []interface{} {
+	if p == "" {
+		return nil
+	}
+	var path []interface{}
+	for _, e := range strings.Split(p, "/") {
+		if e == "*" {
+			path = append(path, all{})
+		} else if operators[e] {
+			path = append(path, operator{})
+		} else {
+			path = append(path, e)
+		}
+	}
+	return path
+}
+
+// check resolves path against the type t and describes the first element that cannot resolve,
+// or returns "" if the path may resolve.
+func check(t types.Type, path []interface{}) string {
+	for _, e := range path {
+		if _, ok := e.(operator); ok {
+			return "" // the decoded value is dynamic
+		}
+		if special(t) {
+			return ""
+		}
+		_, isALL := e.(all)
+		switch u := t.Underlying().(type) {
+		case *types.Struct:
+			if isALL {
+				return "" // the fields vary in type
+			}
+			name, ok := e.(string)
+			if !ok {
+				return types.TypeString(t, nil) + " is a struct and cannot be indexed by a number"
+			}
+			f := field(t, name)
+			if f == nil {
+				return types.TypeString(t, nil) + " has no field " + strings.Title(name)
+			}
+			t = f.Type()
+		case *types.Map:
+			if s, ok := e.(string); ok && !isALL && isInteger(u.Key()) {
+				if _, err := strconv.ParseInt(s, 0, 64); err != nil {
+					return types.TypeString(t, nil) + " has integer keys, not " + strconv.Quote(s)
+				}
+			}
+			t = u.Elem()
+		case *types.Slice:
+			if msg := checkIndex(t, e); msg != "" {
+				return msg
+			}
+			t = u.Elem()
+		case *types.Array:
+			if msg := checkIndex(t, e); msg != "" {
+				return msg
+			}
+			t = u.Elem()
+		case *types.Pointer:
+			return types.TypeString(t, nil) + " is a pointer and cannot be indexed"
+		case *types.Basic:
+			if u.Info()&types.IsString != 0 || u.Kind() == types.UntypedNil {
+				return "" // may hold JSON, see DecodeStrings
+			}
+			return types.TypeString(t, nil) + " cannot be indexed"
+		default:
+			return "" // interfaces, type parameters and other dynamic values
+		}
+	}
+	return ""
+}
+
+// checkIndex describes why e cannot index the array or slice type t, if it cannot.
+func checkIndex(t types.Type, e interface{}) string {
+	if s, ok := e.(string); ok {
+		if _, err := strconv.ParseInt(s, 0, 64); err != nil {
+			return types.TypeString(t, nil) + " cannot be indexed by " + strconv.Quote(s)
+		}
+	}
+	return ""
+}
+
+// field returns the exported field of the struct type t that Q finds for name.
+func field(t types.Type, name string) *types.Var {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, strings.Title(name))
+	if v, ok := obj.(*types.Var); ok && v.IsField() && v.Exported() {
+		return v
+	}
+	return nil
+}
+
+func isInteger(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsInteger != 0
+}
+
+// special reports whether Q resolves paths in values of type t other than by their structure.
+func special(t types.Type) bool {
+	if m, _, _ := types.LookupFieldOrMethod(t, false, nil, "Keys"); m != nil {
+		if g, _, _ := types.LookupFieldOrMethod(t, false, nil, "Get"); g != nil {
+			return true // an OrderedMap, presumably
+		}
+	}
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	for _, t := range []types.Type{t, types.Unalias(t)} {
+		switch types.TypeString(t, nil) {
+		case "encoding/json.RawMessage", "net/url.Values", "net/http.Header", "sync.Map", "time.Time":
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package a
+
+import (
+	"encoding/json"
+
+	jq "github.com/gtrevg/go-jq"
+)
+
+type Config struct {
+	Server struct {
+		Port  int
+		Hosts []string
+	}
+	Limits  map[string]int
+	ByID    map[int]Item
+	Items   []Item
+	Raw     json.RawMessage
+	Extra   interface{}
+	Doc     string
+	Blob    []byte
+	Pointer *Item
+	private int
+	Embedded
+}
+
+type Embedded struct {
+	Promoted int
+}
+
+type Item struct {
+	Name string
+}
+
+func f(cfg Config, p *Config, e *jq.Engine, name string) {
+	jq.QQ(cfg, "server/port")
+	jq.QQ(cfg, "server/prot") // want `jq path "server/prot": struct\{Port int; Hosts \[\]string\} has no field Prot`
+	jq.Int(cfg, "Server", "Port")
+	jq.Int(cfg, "Server", "Port", "x") // want `jq path "Server/Port/x": int cannot be indexed`
+	jq.QQ(cfg, "server/hosts/0")
+	jq.QQ(cfg, "server/hosts/first") // want `jq path "server/hosts/first": \[\]string cannot be indexed by "first"`
+	jq.QQ(cfg, "limits/anything")
+	jq.QQ(cfg, "byID/7/name")
+	jq.QQ(cfg, "byID/x") // want `jq path "byID/x": map\[int\]a.Item has integer keys, not "x"`
+	jq.QQ(cfg, "items/*/name")
+	jq.Q(cfg, "Items", jq.ALL, "Nmae") // want `jq path "Items/\*/Nmae": a.Item has no field Nmae`
+	jq.Q(cfg, "Items", 0, "Name")
+	jq.Q(cfg, "Server", jq.KEYS)
+	jq.Q(cfg, "Limits", jq.KEYS, 0)
+	jq.Q(cfg, "Server", "Port", jq.KEYS)
+	jq.Q(cfg, "Server", jq.KEYS, "anything", "x")
+	jq.QQ(cfg, "raw/anything")
+	jq.QQ(cfg, "extra/anything")
+	jq.QQ(cfg, "doc/anything")
+	jq.QQ(cfg, "blob/@base64d/anything")
+	jq.QQ(cfg, "blob/anything") // want `jq path "blob/anything": \[\]byte cannot be indexed by "anything"`
+	jq.QQ(cfg, "pointer/name")  // want `jq path "pointer/name": \*a.Item is a pointer and cannot be indexed`
+	jq.QQ(cfg, "private")       // want `jq path "private": a.Config has no field Private`
+	jq.QQ(cfg, "promoted")
+	jq.QQ(cfg, "*/anything")
+	jq.QQ(cfg, "0")    // want `jq path "0": a.Config has no field 0`
+	jq.Q(cfg, 0)       // want `jq path "0": a.Config is a struct and cannot be indexed by a number`
+	jq.QQ(p, "server") // want `jq path "server": \*a.Config is a pointer and cannot be indexed`
+	jq.Q(cfg, name, "nosuchfield")
+	jq.Q(cfg, "Server", name)
+	var dst int
+	jq.DecodePath(cfg, "server/nope", &dst) // want `jq path "server/nope": struct\{Port int; Hosts \[\]string\} has no field Nope`
+	e.QQ(cfg, "nope")
+	var v interface{} = cfg
+	jq.QQ(v, "nope")
+}
@@ -0,0 +1,18 @@
+// Package jq is a stand-in for the real package with the signatures the analyzer checks.
+package jq
+
+type quantifier int
+
+const (
+	ALL quantifier = iota
+	KEYS
+)
+
+func Q(root interface{}, index ...interface{}) interface{}            { return nil }
+func QQ(root interface{}, index string) interface{}                   { return nil }
+func Int(root interface{}, index ...interface{}) int                  { return 0 }
+func DecodePath(root interface{}, path string, dst interface{}) error { return nil }
+
+type Engine struct{}
+
+func (e *Engine) QQ(root interface{}, path string) interface{} { return nil }
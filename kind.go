@@ -0,0 +1,126 @@
+package jq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Kind classifies values by their JSON type.
+type Kind int
+
+const (
+	KindMissing Kind = iota // no value present
+	KindNull
+	KindBool
+	KindNumber
+	KindString // strings, byte slices and times
+	KindArray
+	KindObject // maps and structs
+	KindOther  // values with no JSON equivalent, such as functions and channels
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindMissing:
+		return "missing"
+	case KindNull:
+		return "null"
+	case KindBool:
+		return "boolean"
+	case KindNumber:
+		return "number"
+	case KindString:
+		return "string"
+	case KindArray:
+		return "array"
+	case KindObject:
+		return "object"
+	case KindOther:
+		return "other"
+	}
+	return fmt.Sprintf("<kind %d>", int(k))
+}
+
+// KindOf returns the kind of the present value v.
+func KindOf(v interface{}) Kind {
+	switch vv := v.(type) {
+	case json.RawMessage:
+		return KindOf(unwrap(vv))
+	case []byte, time.Time:
+		return KindString
+	}
+	switch order(v) {
+	case orderNull:
+		return KindNull
+	case orderFalse, orderTrue:
+		return KindBool
+	case orderNumber:
+		return KindNumber
+	case orderString:
+		return KindString
+	case orderArray:
+		return KindArray
+	case orderObject:
+		return KindObject
+	}
+	return KindOther
+}
+
+// kindAt returns the kind of the value at path in root, or KindMissing if it is not present.
+func kindAt(root interface{}, index []interface{}) Kind {
+	v, ok := lookup(root, index)
+	if !ok {
+		return KindMissing
+	}
+	return KindOf(v)
+}
+
+// IsArray reports whether the value at path is an array or a slice, other than a byte slice.
+func IsArray(root interface{}, index ...interface{}) bool {
+	return kindAt(root, index) == KindArray
+}
+
+// IsObject reports whether the value at path is a map or a struct.
+func IsObject(root interface{}, index ...interface{}) bool {
+	return kindAt(root, index) == KindObject
+}
+
+// IsScalar reports whether the value at path is present and is null, a boolean, a number
+// or a string.
+func IsScalar(root interface{}, index ...interface{}) bool {
+	switch kindAt(root, index) {
+	case KindNull, KindBool, KindNumber, KindString:
+		return true
+	}
+	return false
+}
+
+// Expect checks that each slash separated path in kinds resolves to a value of the given kind
+// in root, as a lightweight contract for untrusted payloads:
+//
+//	err := jq.Expect(payload, map[string]jq.Kind{"user/id": jq.KindNumber, "items": jq.KindArray})
+//
+// A path that is expected to be KindMissing must not be present. Expect returns
+// the violations for all paths joined together in path order, or nil if there are none.
+func Expect(root interface{}, kinds map[string]Kind) error {
+	paths := make([]string, 0, len(kinds))
+	for p := range kinds {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var errs []error
+	for _, p := range paths {
+		index, err := parsePath(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if k := kindAt(root, index); k != kinds[p] {
+			errs = append(errs, fmt.Errorf("%s: expected %s, got %s", p, kinds[p], k))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,95 @@
+package jq
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestKindOf(t *testing.T) {
+	for _, tc := range []struct {
+		v      interface{}
+		expect Kind
+	}{
+		{nil, KindNull},
+		{true, KindBool},
+		{1.5, KindNumber},
+		{json.Number("3"), KindNumber},
+		{uint8(3), KindNumber},
+		{"s", KindString},
+		{[]byte("s"), KindString},
+		{time.Now(), KindString},
+		{[]interface{}{}, KindArray},
+		{[2]int{}, KindArray},
+		{map[string]interface{}{}, KindObject},
+		{struct{}{}, KindObject},
+		{json.RawMessage(`[1]`), KindArray},
+		{func() {}, KindOther},
+	} {
+		if k := KindOf(tc.v); k != tc.expect {
+			t.Errorf("%#v: expected %v, got %v", tc.v, tc.expect, k)
+		}
+	}
+}
+
+func TestIsArrayObjectScalar(t *testing.T) {
+	root := map[string]interface{}{"one": map[string]interface{}{"id": 1.}, "many": []interface{}{1.}, "null": nil, "raw": json.RawMessage(`{"a": 1}`)}
+	for _, tc := range []struct {
+		path                  string
+		array, object, scalar bool
+	}{
+		{"one", false, true, false},
+		{"many", true, false, false},
+		{"one/id", false, false, true},
+		{"many/0", false, false, true},
+		{"null", false, false, true},
+		{"raw", false, true, false},
+		{"nosuchkey", false, false, false},
+		{"many/x", false, false, false},
+	} {
+		index := split(tc.path)
+		if v := IsArray(root, index...); v != tc.array {
+			t.Errorf("IsArray(%q): expected %v", tc.path, tc.array)
+		}
+		if v := IsObject(root, index...); v != tc.object {
+			t.Errorf("IsObject(%q): expected %v", tc.path, tc.object)
+		}
+		if v := IsScalar(root, index...); v != tc.scalar {
+			t.Errorf("IsScalar(%q): expected %v", tc.path, tc.scalar)
+		}
+	}
+}
+
+func TestExpect(t *testing.T) {
+	if err := Expect(testObj, map[string]Kind{
+		"foo":                 KindNumber,
+		"test":                KindString,
+		"array":               KindArray,
+		"subobj":              KindObject,
+		"bool":                KindBool,
+		"subobj/subarray/0":   KindNumber,
+		"nosuchkey":           KindMissing,
+		"subobj/subsubobj/id": KindMissing,
+	}); err != nil {
+		t.Errorf("Expect: unexpected error %v", err)
+	}
+
+	err := Expect(testObj, map[string]Kind{
+		"test":      KindNumber,
+		"array":     KindArray,
+		"nosuchkey": KindString,
+		"foo":       KindMissing,
+	})
+	if err == nil {
+		t.Fatal("Expect: expected violations")
+	}
+	expect := []string{
+		"foo: expected missing, got number",
+		"nosuchkey: expected string, got missing",
+		"test: expected number, got string",
+	}
+	if lines := strings.Split(err.Error(), "\n"); strings.Join(lines, "|") != strings.Join(expect, "|") {
+		t.Errorf("Expect: expected %q, got %q", expect, lines)
+	}
+}
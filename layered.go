@@ -0,0 +1,57 @@
+package jq
+
+// Layered is a root made of several roots in order of precedence, such as
+// command line flags, environment overrides, a configuration file and defaults:
+//
+//	cfg := jq.Layered{overrides, file, defaults}
+//	port := jq.Int(cfg, "server", "port")
+//
+// A path is resolved against each layer in turn, and the first layer in which the value
+// is present provides the result, even if it is null. Containers are not merged, so
+// a path continues in the layer where its first element was found only if the whole path
+// resolves there; Q(cfg, "server", "port") finds the port of the defaults if the file has
+// a server object without a port. If no layer has the value, the result is that of the last layer.
+// Layers can be Layered themselves.
+type Layered []interface{}
+
+// qLayered resolves index against the layers of l.
+func (c *config) qLayered(l Layered, index []interface{}) interface{} {
+	if len(l) == 0 {
+		return c.missing(index[0])
+	}
+	var r interface{}
+	for _, root := range l {
+		if r = c.step(root, index); !c.absent(root, index, r) {
+			return r
+		}
+	}
+	return r
+}
+
+// absent reports whether r, the result of resolving index in root under c, does not stand
+// for a present value.
+func (c *config) absent(root interface{}, index []interface{}, r interface{}) bool {
+	if _, ok := r.(error); ok {
+		return true
+	}
+	if r != nil || c.strict {
+		return false
+	}
+	sc := *c
+	sc.strict, sc.hooks = true, nil
+	_, isErr := sc.step(root, index).(error)
+	return isErr
+}
+
+// pick returns the layer of l in which index resolves to a present value under c, or the last layer.
+func (l Layered) pick(c *config, index []interface{}) interface{} {
+	for _, root := range l {
+		if _, ok := c.lookup(root, index); ok {
+			return root
+		}
+	}
+	if len(l) == 0 {
+		return nil
+	}
+	return l[len(l)-1]
+}
@@ -0,0 +1,69 @@
+package jq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLayered(t *testing.T) {
+	defaults := map[string]interface{}{
+		"server": map[string]interface{}{"host": "localhost", "port": 8080},
+		"debug":  false,
+		"tags":   []interface{}{"a", "b"},
+	}
+	file := map[string]interface{}{
+		"server": map[string]interface{}{"host": "example.com"},
+		"tags":   []interface{}{"c"},
+		"name":   nil,
+	}
+	env := map[string]interface{}{"debug": true, "server": "invalid"}
+	cfg := Layered{env, file, defaults}
+
+	for _, tc := range []struct {
+		path   string
+		expect interface{}
+	}{
+		{"server/host", "example.com"},
+		{"server/port", 8080},
+		{"debug", true},
+		{"tags/0", "c"},
+		{"tags/1", "b"},
+		{"tags/*", []interface{}{"c"}},
+		{"name", nil},
+		{"nosuchkey", nil},
+		{"server", "invalid"},
+	} {
+		if v := QQ(cfg, tc.path); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+
+	if !Exists(cfg, "server", "port") || !Exists(cfg, "name") || Exists(cfg, "nosuchkey") {
+		t.Errorf("Exists: wrong result")
+	}
+	if v := Int(cfg, "server", "port"); v != 8080 {
+		t.Errorf("Int: got %v", v)
+	}
+	var got []interface{}
+	for _, v := range Iter(cfg, "server/port") {
+		got = append(got, v)
+	}
+	if !reflect.DeepEqual(got, []interface{}{8080}) {
+		t.Errorf("Iter: got %v", got)
+	}
+	if v, err := QQE(cfg, "nosuchkey"); v != nil || err == nil {
+		t.Errorf("QQE: got %v, %v", v, err)
+	}
+	if v := QWith(cfg, []Option{CaseInsensitive()}, "SERVER", "Port"); v != 8080 {
+		t.Errorf("QWith: got %v", v)
+	}
+	if v := QWith(cfg, []Option{StrictMissing()}, "nosuchkey"); !isError(v) {
+		t.Errorf("StrictMissing: expected an error, got %v", v)
+	}
+	if v := QQ(Layered{Layered{file}, defaults}, "server/port"); v != 8080 {
+		t.Errorf("nested: got %v", v)
+	}
+	if v := QQ(Layered{}, "a"); v != nil {
+		t.Errorf("empty: got %v", v)
+	}
+}
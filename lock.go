@@ -0,0 +1,235 @@
+package jq
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// rlocker is implemented by read-write locks such as *sync.RWMutex.
+type rlocker interface {
+	RLock()
+	RUnlock()
+}
+
+// Guard associates l with root, which must be a map, pointer or slice, so that queries of e
+// on root hold l while they traverse it: the read lock if l is a *sync.RWMutex or has RLock
+// and RUnlock methods, and the lock otherwise. Code that modifies root in place should do so
+// with Set, Delete or Update, or hold the lock itself, to make concurrent queries safe.
+// Roots are identified by the address they refer to, so a query on a sub-document is not guarded.
+// Guard returns false if root is of another kind.
+func (e *Engine) Guard(root interface{}, l sync.Locker) bool {
+	k, ok := rootKey(root)
+	if ok {
+		e.c.guards.Store(k, l)
+	}
+	return ok
+}
+
+// Unguard removes the lock associated with root by Guard.
+func (e *Engine) Unguard(root interface{}) {
+	if k, ok := rootKey(root); ok {
+		e.c.guards.Delete(k)
+	}
+}
+
+// Update calls fn while holding the lock associated with root, exclusively, and returns its error.
+// Fn is passed an Engine with the options of e that does not lock, to query root while it is
+// held: the queries of e itself would wait for the lock and never return. If root has no lock,
+// fn is called without locking.
+func (e *Engine) Update(root interface{}, fn func(u *Engine) error) error {
+	if l := e.c.guard(root); l != nil {
+		l.Lock()
+		defer l.Unlock()
+	}
+	u := &Engine{c: e.c, paths: make(map[string][]interface{})}
+	u.c.guards = nil
+	return fn(u)
+}
+
+// Set stores v at the slash separated path in root, modifying the map or slice that holds it
+// in place, while holding the lock associated with root exclusively. A map gains the key if it
+// is not present; a slice element must exist. It returns an error if the parent of the value is
+// not a map or slice, if v cannot be assigned to its element type, or if the path goes through
+// values built while querying, as for MapValuesInPlace.
+func (e *Engine) Set(root interface{}, path string, v interface{}) error {
+	index, err := e.parsePath(path)
+	if err != nil {
+		return err
+	}
+	if len(index) == 0 {
+		return errors.New("cannot set the root in place")
+	}
+	return e.Update(root, func(u *Engine) error {
+		parent := u.c.inPlace(root, index[:len(index)-1])
+		if err, ok := parent.(error); ok {
+			return err
+		}
+		return setIn(parent, index[len(index)-1], v)
+	})
+}
+
+// Delete removes the value at the slash separated path in root, while holding the lock
+// associated with root exclusively: the key of a map, in place, or the element of a slice,
+// by storing the shortened slice in its own parent. Deleting a value that is not present does nothing.
+func (e *Engine) Delete(root interface{}, path string) error {
+	index, err := e.parsePath(path)
+	if err != nil {
+		return err
+	}
+	if len(index) == 0 {
+		return errors.New("cannot delete the root")
+	}
+	return e.Update(root, func(u *Engine) error {
+		n := len(index)
+		parent := u.c.inPlace(root, index[:n-1])
+		if err, ok := parent.(error); ok {
+			return err
+		}
+		switch pv := reflect.ValueOf(parent); pv.Kind() {
+		case reflect.Map:
+			k, err := mapKey(pv.Type().Key(), index[n-1])
+			if err != nil {
+				return err
+			}
+			pv.SetMapIndex(k, reflect.Value{})
+			return nil
+		case reflect.Slice:
+			i, err := sliceIndex(index[n-1])
+			if err != nil || i >= pv.Len() {
+				return err
+			}
+			if n == 1 {
+				return errors.New("cannot delete an element of the root slice in place")
+			}
+			a := reflect.MakeSlice(pv.Type(), 0, pv.Len()-1)
+			a = reflect.AppendSlice(reflect.AppendSlice(a, pv.Slice(0, i)), pv.Slice(i+1, pv.Len()))
+			return setIn(u.c.inPlace(root, index[:n-2]), index[n-2], a.Interface())
+		case reflect.Invalid:
+			return nil
+		}
+		return fmt.Errorf("cannot delete %v from type %T", index[n-1], parent)
+	})
+}
+
+// setIn stores v under key in the map or slice c.
+func setIn(c, key, v interface{}) error {
+	switch cv := reflect.ValueOf(c); cv.Kind() {
+	case reflect.Map:
+		k, err := mapKey(cv.Type().Key(), key)
+		if err != nil {
+			return err
+		}
+		r, err := assign(v, cv.Type().Elem())
+		if err != nil {
+			return err
+		}
+		cv.SetMapIndex(k, r)
+		return nil
+	case reflect.Slice:
+		i, err := sliceIndex(key)
+		if err != nil {
+			return err
+		}
+		if i >= cv.Len() {
+			return fmt.Errorf("index %d out of range for slice of length %d", i, cv.Len())
+		}
+		r, err := assign(v, cv.Type().Elem())
+		if err != nil {
+			return err
+		}
+		cv.Index(i).Set(r)
+		return nil
+	}
+	return fmt.Errorf("cannot set %v in type %T", key, c)
+}
+
+// mapKey converts the index element key to a key of type t, parsing strings for integer keys.
+func mapKey(t reflect.Type, key interface{}) (reflect.Value, error) {
+	kv := reflect.ValueOf(key)
+	switch {
+	case !kv.IsValid():
+		if t.Kind() == reflect.Interface {
+			return reflect.Zero(t), nil
+		}
+	case kv.Type().AssignableTo(t):
+		return kv, nil
+	case kv.Kind() == reflect.String && t.Kind() == reflect.String:
+		return kv.Convert(t), nil
+	case kv.Kind() == reflect.String && isSigned(t.Kind()):
+		if n, err := strconv.ParseInt(kv.String(), 0, 64); err == nil && !reflect.Zero(t).OverflowInt(n) {
+			return reflect.ValueOf(n).Convert(t), nil
+		}
+	case kv.Kind() == reflect.String && isUnsigned(t.Kind()):
+		if n, err := strconv.ParseUint(kv.String(), 0, 64); err == nil && !reflect.Zero(t).OverflowUint(n) {
+			return reflect.ValueOf(n).Convert(t), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("cannot use %v (type %T) as map key of type %s", key, key, t)
+}
+
+// sliceIndex returns the index element key as a non-negative slice index.
+func sliceIndex(key interface{}) (int, error) {
+	switch kv := reflect.ValueOf(key); {
+	case kv.Kind() == reflect.String:
+		if i, err := strconv.Atoi(kv.String()); err == nil && i >= 0 {
+			return i, nil
+		}
+	case isSigned(kv.Kind()):
+		if kv.Int() >= 0 {
+			return int(kv.Int()), nil
+		}
+	case isUnsigned(kv.Kind()):
+		return int(kv.Uint()), nil
+	}
+	return 0, fmt.Errorf("invalid slice index %v", key)
+}
+
+// guardKey identifies a root by its kind and address.
+type guardKey struct {
+	kind reflect.Kind
+	ptr  uintptr
+}
+
+// rootKey returns the key identifying root, if it can be guarded.
+func rootKey(root interface{}) (guardKey, bool) {
+	switch v := reflect.ValueOf(root); v.Kind() {
+	case reflect.Map, reflect.Ptr, reflect.Slice:
+		if v.IsNil() {
+			return guardKey{}, false
+		}
+		return guardKey{v.Kind(), v.Pointer()}, true
+	}
+	return guardKey{}, false
+}
+
+// guard returns the lock associated with root, if any.
+func (c *config) guard(root interface{}) sync.Locker {
+	if c.guards == nil {
+		return nil
+	}
+	k, ok := rootKey(root)
+	if !ok {
+		return nil
+	}
+	if l, ok := c.guards.Load(k); ok {
+		return l.(sync.Locker)
+	}
+	return nil
+}
+
+// lockRoot acquires the lock associated with root for reading, and returns the function that releases it.
+func (c *config) lockRoot(root interface{}) func() {
+	switch l := c.guard(root).(type) {
+	case nil:
+		return func() {}
+	case rlocker:
+		l.RLock()
+		return l.RUnlock
+	default:
+		l.Lock()
+		return l.Unlock
+	}
+}
@@ -0,0 +1,149 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGuard(t *testing.T) {
+	doc := map[string]interface{}{"counter": 0, "items": map[string]interface{}{}}
+	e := NewEngine()
+	var mu sync.RWMutex
+	if !e.Guard(doc, &mu) {
+		t.Fatal("Guard: expected map to be guardable")
+	}
+	if e.Guard(42, &mu) || e.Guard(map[string]int(nil), &mu) {
+		t.Errorf("Guard: expected scalars and nil maps to be rejected")
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				e.Update(doc, func(u *Engine) error {
+					doc["counter"] = u.Int(doc, "counter") + 1
+					doc["items"].(map[string]interface{})[fmt.Sprint(g, i)] = i
+					return nil
+				})
+				e.Set(doc, fmt.Sprintf("items/%d-%d-set", g, i), i)
+				e.Delete(doc, fmt.Sprintf("items/%d-%d-set", g, i))
+			}
+		}(g)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				e.Q(doc, "items", ALL)
+				e.Int(doc, "counter")
+			}
+		}()
+	}
+	wg.Wait()
+	if v := e.Int(doc, "counter"); v != 400 {
+		t.Errorf("counter: expected 400, got %v", v)
+	}
+
+	// a plain Mutex is locked exclusively for reads too
+	var m sync.Mutex
+	other := map[string]interface{}{"a": 1}
+	e.Guard(other, &m)
+	m.Lock()
+	done := make(chan interface{})
+	go func() { done <- e.Q(other, "a") }()
+	select {
+	case v := <-done:
+		t.Errorf("query did not wait for the lock, got %v", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+	m.Unlock()
+	if v := <-done; v != 1 {
+		t.Errorf("expected 1, got %v", v)
+	}
+
+	e.Unguard(other)
+	m.Lock()
+	if v := e.Q(other, "a"); v != 1 {
+		t.Errorf("Unguard: expected 1, got %v", v)
+	}
+	m.Unlock()
+}
+
+func TestGuardSetDelete(t *testing.T) {
+	doc := map[string]interface{}{
+		"a":     map[string]interface{}{"b": 1},
+		"list":  []interface{}{"x", "y", "z"},
+		"ports": map[int]string{80: "http"},
+	}
+	e := NewEngine()
+	var mu sync.RWMutex
+	e.Guard(doc, &mu)
+
+	for _, tc := range []struct {
+		path string
+		v    interface{}
+		err  bool
+	}{
+		{"a/b", 2, false},
+		{"a/c", "new", false},
+		{"list/1", "Y", false},
+		{"ports/443", "https", false},
+		{"list/3", "w", true},
+		{"ports/x", "bad", true},
+		{"ports/80", 80, true},
+		{"a/b/c", 1, true},
+		{"", 1, true},
+	} {
+		if err := e.Set(doc, tc.path, tc.v); (err != nil) != tc.err {
+			t.Errorf("Set %q: unexpected error %v", tc.path, err)
+		}
+	}
+	for _, path := range []string{"a/c", "list/0", "ports/80", "a/nosuchkey", "nosuchkey/x", "list/9"} {
+		if err := e.Delete(doc, path); err != nil {
+			t.Errorf("Delete %q: %v", path, err)
+		}
+	}
+	copies := map[string]interface{}{"s": `{"x": 1}`, "raw": json.RawMessage(`{"x": 1}`), "b64": "eyJ4IjogMX0="}
+	decoding := NewEngine(DecodeStrings())
+	decoding.Guard(copies, &mu)
+	for _, path := range []string{"s/x", "raw/x", "b64/@base64d/x"} {
+		if err := decoding.Set(copies, path, 2); err == nil {
+			t.Errorf("Set %q: expected error", path)
+		}
+		if err := decoding.Delete(copies, path); err == nil {
+			t.Errorf("Delete %q: expected error", path)
+		}
+	}
+
+	expect := map[string]interface{}{
+		"a":     map[string]interface{}{"b": 2},
+		"list":  []interface{}{"Y", "z"},
+		"ports": map[int]string{443: "https"},
+	}
+	if !reflect.DeepEqual(doc, expect) {
+		t.Errorf("expected %v, got %v", expect, doc)
+	}
+
+	// reading through the Engine passed to Update does not wait for the held lock
+	done := make(chan error)
+	go func() {
+		done <- e.Update(doc, func(u *Engine) error {
+			if v := u.Int(doc, "a", "b"); v != 2 {
+				return fmt.Errorf("got %v", v)
+			}
+			return nil
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Update: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Update: reading inside fn deadlocked")
+	}
+}
@@ -0,0 +1,41 @@
+package jq
+
+import (
+	"log/slog"
+	"time"
+)
+
+// WithLogger makes every query log its path, outcome, duration and error, if any,
+// to l at debug level. The outcome is "found", "null", "missing" or "error".
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) { c.logger = l }
+}
+
+// observe runs the query and reports it to the logger and metrics of c.
+func (c *config) observe(root interface{}, index []interface{}) interface{} {
+	ctx := c.context()
+	logging := c.logger != nil && c.logger.Enabled(ctx, slog.LevelDebug)
+	if !logging && c.metrics == nil {
+		return c.run(root, index)
+	}
+	start := time.Now()
+	r := c.run(root, index)
+	d := time.Since(start)
+
+	path, outcome := pathString(index), c.outcomeOf(r, root, index)
+	if c.metrics != nil {
+		c.metrics.ObserveQuery(path, outcome, d)
+	}
+	if logging {
+		attrs := []slog.Attr{
+			slog.String("path", path),
+			slog.String("outcome", outcome.String()),
+			slog.Duration("duration", d),
+		}
+		if err, ok := r.(error); ok {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "jq query", attrs...)
+	}
+	return r
+}
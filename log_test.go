@@ -0,0 +1,49 @@
+package jq
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	e := NewEngine(WithLogger(l))
+
+	e.QQ(testObj, "subobj/foo")
+	e.QQ(testObj, "nosuchkey")
+	e.Q(testObj, "test", "x")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 log records, got %q", lines)
+	}
+	for i, expect := range []struct{ path, outcome string }{
+		{"subobj/foo", "found"},
+		{"nosuchkey", "missing"},
+		{"test/x", "error"},
+	} {
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i]), &rec); err != nil {
+			t.Fatal(err)
+		}
+		if rec["level"] != "DEBUG" || rec["msg"] != "jq query" || rec["path"] != expect.path || rec["outcome"] != expect.outcome {
+			t.Errorf("record %d: expected %v, got %v", i, expect, rec)
+		}
+		if _, ok := rec["duration"]; !ok {
+			t.Errorf("record %d: no duration", i)
+		}
+		if _, ok := rec["error"]; ok != (expect.outcome == "error") {
+			t.Errorf("record %d: wrong error attribute %v", i, rec["error"])
+		}
+	}
+
+	buf.Reset()
+	quiet := NewEngine(WithLogger(slog.New(slog.NewJSONHandler(&buf, nil))))
+	if v := quiet.QQ(testObj, "subobj/foo"); v != 1. || buf.Len() != 0 {
+		t.Errorf("info level logger: got %v, logged %q", v, buf.String())
+	}
+}
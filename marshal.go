@@ -0,0 +1,23 @@
+package jq
+
+import "encoding/json"
+
+// MarshalAt returns the JSON encoding of QQ(root, path).
+// If the query returns an error, MarshalAt returns it.
+// A value that is not present is encoded as null.
+func MarshalAt(root interface{}, path string) ([]byte, error) {
+	v := QQ(root, path)
+	if err, ok := v.(error); ok {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// MarshalIndentAt is like MarshalAt but applies json.MarshalIndent with prefix and indent.
+func MarshalIndentAt(root interface{}, path, prefix, indent string) ([]byte, error) {
+	v := QQ(root, path)
+	if err, ok := v.(error); ok {
+		return nil, err
+	}
+	return json.MarshalIndent(v, prefix, indent)
+}
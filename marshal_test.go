@@ -0,0 +1,33 @@
+package jq
+
+import "testing"
+
+func TestMarshalAt(t *testing.T) {
+	for _, tc := range []struct {
+		path   string
+		expect string
+	}{
+		{"subobj/subsubobj/array", `["hello","world"]`},
+		{"array/0", `{"foo":1}`},
+		{"nosuchkey", `null`},
+		{"array/*/foo", `[1,null,null]`},
+	} {
+		b, err := MarshalAt(testObj, tc.path)
+		if err != nil {
+			t.Errorf("[%q]: unexpected error %v", tc.path, err)
+			continue
+		}
+		if string(b) != tc.expect {
+			t.Errorf("[%q]:  expected %s, got %s", tc.path, tc.expect, b)
+		}
+	}
+
+	if _, err := MarshalAt(testObj, "foo/bar"); err == nil {
+		t.Errorf("[%q]: expected error", "foo/bar")
+	}
+
+	b, err := MarshalIndentAt(testObj, "array/0", "", "  ")
+	if err != nil || string(b) != "{\n  \"foo\": 1\n}" {
+		t.Errorf("[%q]: got %q, %v", "array/0", b, err)
+	}
+}
@@ -0,0 +1,51 @@
+package jq
+
+// Maybe is the result of QOpt. It tells a missing value from one that is present but null,
+// which Q reports alike as nil.
+type Maybe struct {
+	value   interface{}
+	present bool
+	err     error
+}
+
+// QOpt is like Q, but reports whether the value is present.
+func QOpt(root interface{}, index ...interface{}) Maybe {
+	if v, ok := lookup(root, index); ok {
+		return Maybe{value: v, present: true}
+	}
+	err, _ := Q(root, index...).(error)
+	return Maybe{err: err}
+}
+
+// IsMissing reports whether the value is not present, or the query failed.
+func (m Maybe) IsMissing() bool {
+	return !m.present
+}
+
+// IsNull reports whether the value is present and nil, like a JSON null.
+func (m Maybe) IsNull() bool {
+	return m.present && m.value == nil
+}
+
+// IsPresent reports whether the value is present and not nil.
+func (m Maybe) IsPresent() bool {
+	return m.present && m.value != nil
+}
+
+// Value returns the value, which is nil if it is missing or null.
+func (m Maybe) Value() interface{} {
+	return m.value
+}
+
+// ValueOr returns the value if it is present, including nil, and def if it is missing.
+func (m Maybe) ValueOr(def interface{}) interface{} {
+	if !m.present {
+		return def
+	}
+	return m.value
+}
+
+// Err returns the error if the query failed, rather than just not finding the value.
+func (m Maybe) Err() error {
+	return m.err
+}
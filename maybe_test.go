@@ -0,0 +1,34 @@
+package jq
+
+import "testing"
+
+func TestQOpt(t *testing.T) {
+	root := map[string]interface{}{
+		"name":  "ann",
+		"email": nil,
+		"tags":  []interface{}{nil},
+	}
+	for _, tc := range []struct {
+		path                      []interface{}
+		missing, null, present, e bool
+		value                     interface{}
+	}{
+		{[]interface{}{"name"}, false, false, true, false, "ann"},
+		{[]interface{}{"email"}, false, true, false, false, nil},
+		{[]interface{}{"phone"}, true, false, false, false, nil},
+		{[]interface{}{"tags", 0}, false, true, false, false, nil},
+		{[]interface{}{"tags", 1}, true, false, false, false, nil},
+		{[]interface{}{"name", "x"}, true, false, false, true, nil},
+	} {
+		m := QOpt(root, tc.path...)
+		if m.IsMissing() != tc.missing || m.IsNull() != tc.null || m.IsPresent() != tc.present || (m.Err() != nil) != tc.e || m.Value() != tc.value {
+			t.Errorf("%v: got missing %v null %v present %v err %v value %v", tc.path, m.IsMissing(), m.IsNull(), m.IsPresent(), m.Err(), m.Value())
+		}
+	}
+	if v := QOpt(root, "email").ValueOr("default"); v != nil {
+		t.Errorf("ValueOr null: expected nil, got %v", v)
+	}
+	if v := QOpt(root, "phone").ValueOr("default"); v != "default" {
+		t.Errorf("ValueOr missing: expected default, got %v", v)
+	}
+}
@@ -0,0 +1,75 @@
+package jq
+
+import "fmt"
+
+// A MergeFunc resolves a conflict found by MergeWith: left and right are the values at path
+// in the two documents, which are different and not both objects. It returns the value for
+// the merged document, or an error to abort the merge.
+type MergeFunc func(path []interface{}, left, right interface{}) (interface{}, error)
+
+// Merge merges right into left recursively, like the * operator of expressions: the result has the
+// entries of both objects, with those of right taking precedence, except that objects found
+// under the same key are merged in turn. Arrays are not merged. Objects, including structs
+// and maps of any type, are merged into map[string]interface{}; the values are not copied.
+// If left and right are not both objects, the result is right.
+func Merge(left, right interface{}) interface{} {
+	if KindOf(left) != KindObject || KindOf(right) != KindObject {
+		return right
+	}
+	return mergeObjects(left, right, true)
+}
+
+// MergeWith is like Merge, but calls resolve for the values that conflict, so that callers
+// can choose the winner, such as the larger of two numbers, or reject the conflict, per path:
+//
+//	merged, err := jq.MergeWith(base, patch, func(path []interface{}, l, r interface{}) (interface{}, error) {
+//		if len(path) > 0 && path[0] == "version" {
+//			return nil, fmt.Errorf("%v: conflicting versions %v and %v", path, l, r)
+//		}
+//		return r, nil
+//	})
+//
+// Values conflict if they are present in both objects, not equal according to Equal,
+// and not both objects. The path must not be modified or retained.
+func MergeWith(left, right interface{}, resolve MergeFunc) (interface{}, error) {
+	if KindOf(left) != KindObject || KindOf(right) != KindObject {
+		if Equal(left, right) {
+			return right, nil
+		}
+		return resolve(nil, left, right)
+	}
+	return merge(nil, left, right, true, resolve)
+}
+
+// merge returns the entries of l and r, found at path, in a new map, with those of r taking
+// precedence or, if resolve is not nil, decided by resolve. Objects found under the same key
+// are merged recursively if deep is set.
+func merge(path []interface{}, l, r interface{}, deep bool, resolve MergeFunc) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	kk, _ := keys(l).([]interface{})
+	for _, k := range kk {
+		m[fmt.Sprint(k)] = Q(l, k)
+	}
+	kk, _ = keys(r).([]interface{})
+	for _, k := range kk {
+		v := Q(r, k)
+		old, ok := m[fmt.Sprint(k)]
+		switch {
+		case !ok:
+		case deep && KindOf(old) == KindObject && KindOf(v) == KindObject:
+			mv, err := merge(append(path[:len(path):len(path)], k), old, v, true, resolve)
+			if err != nil {
+				return nil, err
+			}
+			v = mv
+		case resolve != nil && !Equal(old, v):
+			rv, err := resolve(append(path[:len(path):len(path)], k), old, v)
+			if err != nil {
+				return nil, err
+			}
+			v = rv
+		}
+		m[fmt.Sprint(k)] = v
+	}
+	return m, nil
+}
@@ -0,0 +1,73 @@
+package jq
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	left := map[string]interface{}{
+		"name":   "a",
+		"server": map[string]interface{}{"host": "x", "port": 80},
+		"tags":   []interface{}{"t1"},
+		"count":  3,
+	}
+	right := map[string]interface{}{
+		"server": map[string]interface{}{"port": 8080, "tls": true},
+		"tags":   []interface{}{"t2"},
+		"count":  5.0,
+		"new":    nil,
+	}
+
+	expect := map[string]interface{}{
+		"name":   "a",
+		"server": map[string]interface{}{"host": "x", "port": 8080, "tls": true},
+		"tags":   []interface{}{"t2"},
+		"count":  5.0,
+		"new":    nil,
+	}
+	if v := Merge(left, right); !reflect.DeepEqual(v, expect) {
+		t.Errorf("Merge: expected %v, got %v", expect, v)
+	}
+	if v := Merge(left, 1); v != 1 {
+		t.Errorf("Merge of scalar: got %v", v)
+	}
+
+	var conflicts []string
+	max := func(path []interface{}, l, r interface{}) (interface{}, error) {
+		conflicts = append(conflicts, fmt.Sprint(path))
+		if asFloat(l) > asFloat(r) {
+			return l, nil
+		}
+		return r, nil
+	}
+	v, err := MergeWith(left, map[string]interface{}{"count": 1.0, "server": map[string]interface{}{"port": 443, "host": "x"}}, max)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if QQ(v, "count") != 3 || QQ(v, "server/port") != 443 {
+		t.Errorf("MergeWith: got %v", v)
+	}
+	if expect := []string{"[count]", "[server port]"}; !reflect.DeepEqual(conflicts, expect) {
+		t.Errorf("MergeWith: expected conflicts %v, got %v", expect, conflicts)
+	}
+
+	errConflict := errors.New("conflict")
+	reject := func(path []interface{}, l, r interface{}) (interface{}, error) { return nil, errConflict }
+	if _, err := MergeWith(left, right, reject); err != errConflict {
+		t.Errorf("MergeWith rejecting: got %v", err)
+	}
+	if v, err := MergeWith(left, map[string]interface{}{"count": 3.0, "extra": 1}, reject); err != nil || QQ(v, "extra") != 1 {
+		t.Errorf("MergeWith without conflicts: got %v, %v", v, err)
+	}
+	if _, err := MergeWith(1, 2, reject); err != errConflict {
+		t.Errorf("MergeWith of scalars: got %v", err)
+	}
+}
+
+func asFloat(v interface{}) float64 {
+	f, _ := number(v)
+	return f
+}
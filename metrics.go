@@ -0,0 +1,103 @@
+package jq
+
+import (
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+)
+
+// Outcome classifies the result of a query.
+type Outcome int
+
+const (
+	OutcomeFound   Outcome = iota // the query produced a value
+	OutcomeNull                   // the value is present and null
+	OutcomeMissing                // the value is not present
+	OutcomeErrored                // the query failed
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeFound:
+		return "found"
+	case OutcomeNull:
+		return "null"
+	case OutcomeMissing:
+		return "missing"
+	case OutcomeErrored:
+		return "error"
+	}
+	return "unknown"
+}
+
+// outcomeOf classifies the result r of the query for index in root under c. Strict queries
+// report missing values as errors wrapping ErrNotFound, so their nil results are nulls;
+// for the others, presence is looked up only when the result is nil.
+func (c *config) outcomeOf(r, root interface{}, index []interface{}) Outcome {
+	switch rr := r.(type) {
+	case nil:
+		if c.strict {
+			return OutcomeNull
+		}
+		lc := *c
+		lc.hooks = nil
+		if _, ok := lc.lookup(root, index); ok {
+			return OutcomeNull
+		}
+		return OutcomeMissing
+	case error:
+		if c.strict && errors.Is(rr, ErrNotFound) {
+			return OutcomeMissing
+		}
+		return OutcomeErrored
+	}
+	return OutcomeFound
+}
+
+// Metrics receives a measurement for every query of an Engine created with WithMetrics.
+// Path is the index of the query formatted with slashes, so implementations can keep
+// counts and latencies per path. Implementations must be safe for concurrent use.
+// The jqprom package provides one for Prometheus.
+type Metrics interface {
+	ObserveQuery(path string, outcome Outcome, d time.Duration)
+}
+
+// WithMetrics reports every query to m.
+func WithMetrics(m Metrics) Option {
+	return func(c *config) { c.metrics = m }
+}
+
+// ExpvarMetrics implements Metrics with an expvar.Map holding, for each path,
+// a map with the number of queries by outcome and their total duration in nanoseconds:
+//
+//	{"subobj/foo": {"found": 10, "null": 1, "missing": 2, "error": 0, "nanoseconds": 5230}}
+//
+// Since every distinct path adds an entry, it is meant for applications with a fixed set of paths.
+type ExpvarMetrics struct {
+	mu sync.Mutex
+	m  *expvar.Map
+}
+
+// NewExpvarMetrics returns an ExpvarMetrics published as the expvar variable name.
+// Like expvar.Publish, it panics if the name is already in use.
+func NewExpvarMetrics(name string) *ExpvarMetrics {
+	return &ExpvarMetrics{m: expvar.NewMap(name)}
+}
+
+// ObserveQuery implements Metrics.
+func (e *ExpvarMetrics) ObserveQuery(path string, outcome Outcome, d time.Duration) {
+	e.mu.Lock()
+	pm, _ := e.m.Get(path).(*expvar.Map)
+	if pm == nil {
+		pm = new(expvar.Map)
+		for _, o := range []Outcome{OutcomeFound, OutcomeNull, OutcomeMissing, OutcomeErrored} {
+			pm.Add(o.String(), 0)
+		}
+		pm.Add("nanoseconds", 0)
+		e.m.Set(path, pm)
+	}
+	e.mu.Unlock()
+	pm.Add(outcome.String(), 1)
+	pm.Add("nanoseconds", int64(d))
+}
@@ -0,0 +1,77 @@
+package jq
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedQuery struct {
+	path    string
+	outcome Outcome
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	queries []recordedQuery
+}
+
+func (r *recorder) ObserveQuery(path string, outcome Outcome, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries = append(r.queries, recordedQuery{path, outcome})
+}
+
+func TestMetrics(t *testing.T) {
+	rec := new(recorder)
+	e := NewEngine(WithMetrics(rec))
+	e.QQ(testObj, "subobj/foo")
+	e.QQ(testObj, "subobj/*")
+	e.Q(testObj, "nosuchkey")
+	e.Q(testObj, "test", 0)
+	e.Q(map[string]interface{}{"null": nil}, "null")
+	Get[int](e, testObj, "nosuchkey")
+	Get[*int](e, map[string]interface{}{"null": nil}, "null")
+	expect := []recordedQuery{
+		{"subobj/foo", OutcomeFound},
+		{"subobj/ALL", OutcomeFound},
+		{"nosuchkey", OutcomeMissing},
+		{"test/0", OutcomeErrored},
+		{"null", OutcomeNull},
+		{"nosuchkey", OutcomeMissing},
+		{"null", OutcomeNull},
+	}
+	if len(rec.queries) != len(expect) {
+		t.Fatalf("expected %v, got %v", expect, rec.queries)
+	}
+	for i := range expect {
+		if rec.queries[i] != expect[i] {
+			t.Errorf("query %d: expected %v, got %v", i, expect[i], rec.queries[i])
+		}
+	}
+
+	em := NewExpvarMetrics("jq_test_queries")
+	e = NewEngine(WithMetrics(em))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e.QQ(testObj, "subobj/foo")
+			e.QQ(testObj, "nosuchkey")
+		}()
+	}
+	wg.Wait()
+	var published map[string]map[string]int64
+	if err := json.Unmarshal([]byte(expvar.Get("jq_test_queries").String()), &published); err != nil {
+		t.Fatal(err)
+	}
+	if p := published["subobj/foo"]; p["found"] != 10 || p["null"] != 0 || p["missing"] != 0 || p["error"] != 0 || p["nanoseconds"] <= 0 {
+		t.Errorf("expvar subobj/foo: got %v", p)
+	}
+	if p := published["nosuchkey"]; p["missing"] != 10 || p["null"] != 0 {
+		t.Errorf("expvar nosuchkey: got %v", p)
+	}
+}
@@ -0,0 +1,116 @@
+package jq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// An Operator is a path element that transforms the value it is applied to instead of
+// selecting a part of it, so that a path can continue into a payload nested in encoded form,
+// as found in message envelopes:
+//
+//	jq.QQ(envelope, "message/data/@base64d/user/id")
+//
+// QQ and the other functions taking slash separated paths map the names of the operators,
+// such as "@base64d", to their values; with Q the values are given as index elements.
+// The decoded bytes are decoded as JSON if they hold a JSON value, and returned as a []byte
+// otherwise. Operators apply to strings and byte slices; other values yield an error.
+type Operator struct {
+	name   string
+	decode func([]byte) ([]byte, error)
+}
+
+// String returns the name of o, as used in paths.
+func (o *Operator) String() string {
+	return o.name
+}
+
+var (
+	// Base64Decode decodes standard or URL-safe base64, with or without padding.
+	Base64Decode = &Operator{"@base64d", decodeBase64}
+	// HexDecode decodes hexadecimal.
+	HexDecode = &Operator{"@hexd", decodeHex}
+	// Decompress inflates gzip or zlib compressed data, telling them apart by their header,
+	// as found in event formats that compress their payload.
+	Decompress = &Operator{"@decompress", decompress}
+)
+
+// pathOperators maps the names of the operators to their values, for split.
+var pathOperators = map[string]*Operator{
+	Base64Decode.name: Base64Decode,
+	HexDecode.name:    HexDecode,
+	Decompress.name:   Decompress,
+}
+
+// apply returns the result of o on v.
+func (o *Operator) apply(v interface{}) (interface{}, error) {
+	var b []byte
+	switch vv := v.(type) {
+	case string:
+		b = []byte(vv)
+	case []byte:
+		b = vv
+	default:
+		return nil, fmt.Errorf("%s cannot decode type %T", o.name, v)
+	}
+	d, err := o.decode(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", o.name, err)
+	}
+	if !json.Valid(d) {
+		return d, nil
+	}
+	var r interface{}
+	if err := json.Unmarshal(d, &r); err != nil {
+		return nil, fmt.Errorf("%s: %v", o.name, err)
+	}
+	return r, nil
+}
+
+func decodeBase64(b []byte) ([]byte, error) {
+	b = bytes.TrimSpace(b)
+	enc := base64.StdEncoding
+	if bytes.ContainsAny(b, "-_") {
+		enc = base64.URLEncoding
+	}
+	if len(b)%4 != 0 {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+	d := make([]byte, enc.DecodedLen(len(b)))
+	n, err := enc.Decode(d, b)
+	return d[:n], err
+}
+
+func decodeHex(b []byte) ([]byte, error) {
+	b = bytes.TrimSpace(b)
+	d := make([]byte, hex.DecodedLen(len(b)))
+	n, err := hex.Decode(d, b)
+	return d[:n], err
+}
+
+func decompress(b []byte) ([]byte, error) {
+	var (
+		r   io.ReadCloser
+		err error
+	)
+	switch {
+	case len(b) >= 2 && b[0] == 0x1f && b[1] == 0x8b:
+		r, err = gzip.NewReader(bytes.NewReader(b))
+	case len(b) >= 2 && b[0]&0x0f == 8 && (uint(b[0])<<8|uint(b[1]))%31 == 0:
+		r, err = zlib.NewReader(bytes.NewReader(b))
+	default:
+		return nil, errors.New("not gzip or zlib compressed data")
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,104 @@
+package jq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/hex"
+	"reflect"
+	"testing"
+)
+
+func TestOperators(t *testing.T) {
+	payload := `{"user":{"id":7,"tags":["a","b"]}}`
+	root := map[string]interface{}{
+		"std":    base64.StdEncoding.EncodeToString([]byte(payload)),
+		"url":    base64.RawURLEncoding.EncodeToString([]byte(`{"k":"~~~???"}`)),
+		"hex":    hex.EncodeToString([]byte(payload)),
+		"bytes":  []byte(base64.StdEncoding.EncodeToString([]byte(`[1,2]`))),
+		"text":   base64.StdEncoding.EncodeToString([]byte("hello")),
+		"nested": base64.StdEncoding.EncodeToString([]byte(`{"inner":"` + hex.EncodeToString([]byte(`{"x":true}`)) + `"}`)),
+		"bad":    "not base64!",
+		"number": 42,
+	}
+
+	for _, tc := range []struct {
+		path   string
+		expect interface{}
+	}{
+		{"std/@base64d/user/id", 7.0},
+		{"std/@base64d/user/tags/*", []interface{}{"a", "b"}},
+		{"url/@base64d/k", "~~~???"},
+		{"hex/@hexd/user/tags/1", "b"},
+		{"bytes/@base64d/0", 1.0},
+		{"text/@base64d", []byte("hello")},
+		{"nested/@base64d/inner/@hexd/x", true},
+		{"missing/@base64d/x", nil},
+		{"bad/@base64d", ee},
+		{"bad/@hexd", ee},
+		{"number/@base64d", ee},
+		{"text/@base64d/x", ee},
+	} {
+		v := QQ(root, tc.path)
+		if tc.expect == ee {
+			if !isError(v) {
+				t.Errorf("%q: expected error, got %v", tc.path, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+
+	if v := Q(root, "std", Base64Decode, "user", "id"); v != 7.0 {
+		t.Errorf("Q: got %v", v)
+	}
+	if !Exists(root, "std", Base64Decode, "user") || Exists(root, "missing", Base64Decode) {
+		t.Errorf("Exists: wrong result")
+	}
+	if s := Base64Decode.String(); s != "@base64d" {
+		t.Errorf("String: got %q", s)
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	var gz, zz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(`{"event":{"type":"click","n":3}}`))
+	w.Close()
+	zw := zlib.NewWriter(&zz)
+	zw.Write([]byte(`["x","y"]`))
+	zw.Close()
+	root := map[string]interface{}{
+		"gzip":    gz.Bytes(),
+		"zlib":    zz.Bytes(),
+		"encoded": base64.StdEncoding.EncodeToString(gz.Bytes()),
+		"plain":   []byte(`{"a":1}`),
+		"short":   gz.Bytes()[:10],
+	}
+
+	for _, tc := range []struct {
+		path   string
+		expect interface{}
+	}{
+		{"gzip/@decompress/event/type", "click"},
+		{"zlib/@decompress/1", "y"},
+		{"encoded/@base64d/@decompress/event/n", 3.0},
+		{"missing/@decompress", nil},
+		{"plain/@decompress", ee},
+		{"short/@decompress", ee},
+	} {
+		v := QQ(root, tc.path)
+		if tc.expect == ee {
+			if !isError(v) {
+				t.Errorf("%q: expected error, got %v", tc.path, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+package jq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// An Option changes how QWith and QQWith resolve a path.
+type Option func(*config)
+
+// config holds the behavior selected by options. The zero value behaves like Q.
+type config struct {
+	fold     bool   // match keys and field names ignoring case
+	tag      string // struct tag naming fields, if not empty
+	strict   bool   // report missing values as errors
+	sep      string // separator for QQWith, "/" if empty
+	maxDepth int    // maximum number of index elements, unlimited if zero
+
+	offset, limit int // window on the elements selected by the first ALL, unlimited if zero
+
+	indexPolicy IndexPolicy
+
+	decodeStrings bool // decode strings containing JSON
+
+	loc         *time.Location // for times without a zone, UTC if nil
+	timeFormats []string       // layouts tried by the Time getters in addition to the default ones
+
+	decimal, thousands rune // separators of numbers in strings, if decimal is not zero
+
+	expand func(string) string // for variables in strings returned by the getters, if not nil
+
+	ctx context.Context // checked for cancellation at every step, if not nil
+
+	converters map[reflect.Type]func(context.Context, interface{}) (interface{}, error) // by target type
+
+	hooks []Hook
+	path  []interface{} // to the value being resolved, tracked only if there are hooks
+	hs    *hookState    // per query, if there are hooks
+
+	logger  *slog.Logger
+	metrics Metrics
+
+	fields *sync.Map // *fieldIndex by struct type, shared by the queries of an Engine
+	guards *sync.Map // sync.Locker by guardKey, shared by the queries of an Engine
+}
+
+// plain is the configuration used by Q and the functions built on it.
+var plain config
+
+// CaseInsensitive makes keys of maps and names of struct fields match regardless of case
+// if there is no exact match.
+func CaseInsensitive() Option {
+	return func(c *config) { c.fold = true }
+}
+
+// TagAware makes struct fields match the name given by the struct tag key, as in
+// TagAware("json"), in preference to their Go names, and makes ALL and KEYS report them
+// under that name. Fields without the tag keep their Go names.
+func TagAware(key string) Option {
+	return func(c *config) { c.tag = key }
+}
+
+// StrictMissing makes a missing value an error wrapping ErrNotFound instead of nil.
+func StrictMissing() Option {
+	return func(c *config) { c.strict = true }
+}
+
+// Separator sets the string QQWith splits paths on. The default is "/".
+func Separator(sep string) Option {
+	return func(c *config) { c.sep = sep }
+}
+
+// MaxDepth rejects paths of more than n elements with an error.
+func MaxDepth(n int) Option {
+	return func(c *config) { c.maxDepth = n }
+}
+
+// IndexPolicy selects how array and slice indices outside of the bounds are resolved.
+type IndexPolicy int
+
+const (
+	IndexMissing IndexPolicy = iota // out of range indices select no value, the default
+	IndexClamp                      // out of range indices select the first or the last element
+	IndexWrap                       // negative indices count from the end, as in Python: -1 selects the last element
+	IndexError                      // out of range indices yield an error
+)
+
+// OutOfRange sets how array and slice indices outside of the bounds are resolved.
+// With IndexWrap, indices that are still out of range after wrapping select no value.
+func OutOfRange(p IndexPolicy) Option {
+	return func(c *config) { c.indexPolicy = p }
+}
+
+// position returns the position in an array or slice of length n that idx selects under c.
+func (c *config) position(idx int64, n int) (int, bool) {
+	switch c.indexPolicy {
+	case IndexClamp:
+		if n == 0 {
+			return 0, false
+		}
+		idx = max(0, min(idx, int64(n)-1))
+	case IndexWrap:
+		if idx < 0 {
+			idx += int64(n)
+		}
+	}
+	if 0 <= idx && idx < int64(n) {
+		return int(idx), true
+	}
+	return 0, false
+}
+
+// Offset makes the first ALL of a path skip the first n elements of an array or slice,
+// as if they were not there. The ALL quantifiers after it select all elements.
+// Offset has no effect if the first ALL applies to a map or a struct.
+func Offset(n int) Option {
+	return func(c *config) { c.offset = n }
+}
+
+// Limit makes the first ALL of a path select at most n elements of an array or slice,
+// after those skipped by Offset, and stops the traversal there, so asking for the first ten hits
+// in a large array only resolves the rest of the path for ten elements.
+// The ALL quantifiers after it select all elements. Limit has no effect if n is not positive
+// or if the first ALL applies to a map or a struct.
+func Limit(n int) Option {
+	return func(c *config) { c.limit = n }
+}
+
+// DecodeStrings makes strings that contain a JSON object or array indexable when a path has
+// to descend into them, as is common with double-encoded payloads. The decoded value is not
+// cached, so every query decodes the string again.
+func DecodeStrings() Option {
+	return func(c *config) { c.decodeStrings = true }
+}
+
+// Location makes the Time getters of an Engine and of its Results parse times without a zone in loc
+// instead of UTC.
+func Location(loc *time.Location) Option {
+	return func(c *config) { c.loc = loc }
+}
+
+// TimeFormat makes the Time getters of an Engine and of its Results try layout, as understood
+// by time.Parse, after the layouts they accept by default and before those added with RegisterTimeFormat.
+func TimeFormat(layout string) Option {
+	return func(c *config) { c.timeFormats = append(c.timeFormats, layout) }
+}
+
+// NumberFormat makes the Int getters of an Engine and of its Results, and Get for numeric types,
+// accept numbers in strings written with the given decimal and thousands separators, such as
+// "1.234,56" with NumberFormat(',', '.'). Thousands separators are ignored wherever they appear,
+// and the decimal separator is read as a decimal point. Int still only accepts integral values.
+func NumberFormat(decimal, thousands rune) Option {
+	return func(c *config) { c.decimal, c.thousands = decimal, thousands }
+}
+
+// number returns v as a json.Number if it is a string holding a number in the format
+// set by NumberFormat, and v itself otherwise.
+func (c *config) number(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok || c.decimal == 0 {
+		return v
+	}
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case c.thousands:
+			return -1
+		case c.decimal:
+			return '.'
+		case '.':
+			return 'x' // a decimal point that is not the decimal separator is invalid
+		}
+		return r
+	}, strings.TrimSpace(s))
+	if _, err := strconv.ParseFloat(s, 64); err != nil {
+		return v
+	}
+	return json.Number(s)
+}
+
+// ExpandEnv makes the typed getters of an Engine and of its Results, and Get, replace ${var}
+// and $var in the strings they convert with the values mapping returns for them, as os.Expand does,
+// so a configuration can hold "${HOME}/data" or "$PORT". If mapping is nil, os.Getenv is used.
+// Q and Value return strings unchanged.
+func ExpandEnv(mapping func(string) string) Option {
+	if mapping == nil {
+		mapping = os.Getenv
+	}
+	return func(c *config) { c.expand = mapping }
+}
+
+// expanded returns v with variables expanded as set by ExpandEnv, if it is a string.
+func (c *config) expanded(v interface{}) interface{} {
+	if s, ok := v.(string); ok && c.expand != nil {
+		return os.Expand(s, c.expand)
+	}
+	return v
+}
+
+// location returns the location for times without a zone under c.
+func (c *config) location() *time.Location {
+	if c.loc == nil {
+		return time.UTC
+	}
+	return c.loc
+}
+
+// WithConverter registers fn to convert values to T for the typed getters of an Engine
+// and Get. It is consulted for values whose type is not T already, before the built-in
+// conversions, which apply if it returns an error. This lets domain types, such as
+// money amounts or enumerations, be read with Int or String, and lets Get produce them.
+func WithConverter[T any](fn func(interface{}) (T, error)) Option {
+	return WithContextConverter(func(_ context.Context, v interface{}) (T, error) { return fn(v) })
+}
+
+// WithContextConverter is like WithConverter, for converters that need the context of the query,
+// such as one that looks up exchange rates. Getters of a Result bound with Context pass its
+// context, and all others pass context.Background.
+func WithContextConverter[T any](fn func(context.Context, interface{}) (T, error)) Option {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(c *config) {
+		if c.converters == nil {
+			c.converters = make(map[reflect.Type]func(context.Context, interface{}) (interface{}, error))
+		}
+		c.converters[t] = func(ctx context.Context, v interface{}) (interface{}, error) { return fn(ctx, v) }
+	}
+}
+
+// convert applies the converter for t in c to v, if there is one and v is not of type t.
+func (c *config) convert(v interface{}, t reflect.Type) (interface{}, bool) {
+	fn, ok := c.converters[t]
+	if !ok || v == nil || reflect.TypeOf(v) == t {
+		return nil, false
+	}
+	if _, isErr := v.(error); isErr {
+		return nil, false
+	}
+	r, err := fn(c.context(), v)
+	return r, err == nil
+}
+
+// newConfig applies opts to a zero config.
+func newConfig(opts []Option) *config {
+	c := new(config)
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// QWith is like Q, but with its behavior changed by opts.
+func QWith(root interface{}, opts []Option, index ...interface{}) interface{} {
+	return newConfig(opts).query(root, index)
+}
+
+// QQWith is like QQ, but with its behavior changed by opts.
+func QQWith(root interface{}, opts []Option, path string) interface{} {
+	c := newConfig(opts)
+	index, err := c.parsePath(path)
+	if err != nil {
+		return err
+	}
+	return c.query(root, index)
+}
+
+// query checks the limits in c before resolving index, and logs and measures the query
+// if c has a logger or metrics.
+func (c *config) query(root interface{}, index []interface{}) interface{} {
+	if c.guards != nil {
+		defer c.lockRoot(root)()
+	}
+	if c.logger != nil || c.metrics != nil {
+		return c.observe(root, index)
+	}
+	return c.run(root, index)
+}
+
+// run implements query.
+func (c *config) run(root interface{}, index []interface{}) interface{} {
+	if c.maxDepth > 0 && len(index) > c.maxDepth {
+		return fmt.Errorf("path of length %d exceeds maximum depth %d", len(index), c.maxDepth)
+	}
+	if len(c.hooks) > 0 {
+		cc := *c
+		cc.path, cc.hs, cc.hooks = nil, new(hookState), c.boundHooks()
+		c = &cc
+		for _, h := range c.hooks {
+			root = h.After(nil, root)
+		}
+	}
+	r := c.q(root, index)
+	if c.ctx != nil {
+		// ALL omits the errors of the values it collects, so check for cancellation once more.
+		if err := c.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return r
+}
+
+// window returns the range of the n elements of an array or slice selected by the first ALL
+// under c, and the config for the rest of the path.
+func (c *config) window(n int) (lo, hi int, rest *config) {
+	rest = c.unbounded()
+	lo, hi = min(max(c.offset, 0), n), n
+	if c.limit > 0 && c.limit < hi-lo {
+		hi = lo + c.limit
+	}
+	return lo, hi, rest
+}
+
+// unbounded returns c without the window set by Offset and Limit.
+func (c *config) unbounded() *config {
+	if c.offset == 0 && c.limit == 0 {
+		return c
+	}
+	cc := *c
+	cc.offset, cc.limit = 0, 0
+	return &cc
+}
+
+// split is like split, using the separator in c.
+func (c *config) split(path string) []interface{} {
+	if c.sep == "" {
+		return split(path)
+	}
+	return splitSep(path, c.sep)
+}
+
+// missing returns the result for a value that is not present at index element i.
+func (c *config) missing(i interface{}) interface{} {
+	if c.strict {
+		return fmt.Errorf("%v: %w", i, ErrNotFound)
+	}
+	return nil
+}
+
+// fieldName returns the name that f is known by under c.
+func (c *config) fieldName(f reflect.StructField) string {
+	if c.tag != "" {
+		if n := strings.Split(f.Tag.Get(c.tag), ",")[0]; n != "" && n != "-" {
+			return n
+		}
+	}
+	return f.Name
+}
+
+// field returns the exported field of the struct v named name under c, if any.
+func (c *config) field(v reflect.Value, name string) reflect.Value {
+	if c.tag == "" && !c.fold {
+		return v.FieldByName(strings.Title(name)) // get the corresponding exported field only
+	}
+	fi := c.fieldIndex(v.Type())
+	if i, ok := fi.exact[name]; ok {
+		return v.Field(i)
+	}
+	if r := v.FieldByName(strings.Title(name)); r.IsValid() {
+		return r
+	}
+	if i, ok := fi.folded[strings.ToLower(name)]; ok && c.fold {
+		return v.Field(i)
+	}
+	return reflect.Value{}
+}
+
+// fieldIndex maps the names of the exported fields of a struct type under a config to their indices.
+type fieldIndex struct {
+	exact  map[string]int
+	folded map[string]int // by lower case name, the first field
+}
+
+// fieldIndex returns the fieldIndex for the struct type t, from the cache of c if it has one.
+func (c *config) fieldIndex(t reflect.Type) *fieldIndex {
+	if c.fields != nil {
+		if fi, ok := c.fields.Load(t); ok {
+			return fi.(*fieldIndex)
+		}
+	}
+	fi := &fieldIndex{exact: make(map[string]int), folded: make(map[string]int)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		n := c.fieldName(f)
+		if _, ok := fi.exact[n]; !ok {
+			fi.exact[n] = i
+		}
+		if _, ok := fi.folded[strings.ToLower(n)]; !ok {
+			fi.folded[strings.ToLower(n)] = i
+		}
+	}
+	if c.fields != nil {
+		c.fields.Store(t, fi)
+	}
+	return fi
+}
@@ -0,0 +1,95 @@
+package jq
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestQWith(t *testing.T) {
+	type item struct {
+		ID    int    `json:"id"`
+		Label string `json:"label,omitempty"`
+		Plain bool
+	}
+	root := map[string]interface{}{
+		"Items": []item{{ID: 1, Label: "one"}, {ID: 2, Plain: true}},
+		"Meta":  map[string]interface{}{"Count": 2},
+	}
+
+	for _, tc := range []struct {
+		opts   []Option
+		path   []interface{}
+		expect interface{}
+	}{
+		{nil, []interface{}{"items"}, nil},
+		{[]Option{CaseInsensitive()}, []interface{}{"items", 0, "id"}, 1},
+		{[]Option{CaseInsensitive()}, []interface{}{"META", "count"}, 2},
+		{[]Option{TagAware("json")}, []interface{}{"Items", 1, "id"}, 2},
+		{[]Option{TagAware("json")}, []interface{}{"Items", 0, "label"}, "one"},
+		{[]Option{TagAware("json")}, []interface{}{"Items", 0, "Plain"}, false},
+		{[]Option{TagAware("json")}, []interface{}{"Items", 0, "ID"}, 1},
+		{[]Option{TagAware("json")}, []interface{}{"Items", 0, ALL}, map[string]interface{}{"id": 1, "label": "one", "Plain": false}},
+		{[]Option{TagAware("json")}, []interface{}{"Items", 0, KEYS}, []interface{}{"id", "label", "Plain"}},
+		{[]Option{TagAware("json"), CaseInsensitive()}, []interface{}{"items", 1, "PLAIN"}, true},
+		{[]Option{StrictMissing()}, []interface{}{"Meta", "Count"}, 2},
+		{[]Option{StrictMissing()}, []interface{}{"Meta", "nosuchkey"}, ee},
+		{[]Option{StrictMissing()}, []interface{}{"Items", 5}, ee},
+		{[]Option{MaxDepth(2)}, []interface{}{"Meta", "Count"}, 2},
+		{[]Option{MaxDepth(2)}, []interface{}{"Items", 0, "ID"}, ee},
+		{[]Option{Limit(1)}, []interface{}{"Items", ALL, "ID"}, []interface{}{1}},
+		{[]Option{Offset(1)}, []interface{}{"Items", ALL, "ID"}, []interface{}{2}},
+		{[]Option{Offset(1), Limit(5)}, []interface{}{"Items", ALL, "ID"}, []interface{}{2}},
+		{[]Option{Offset(2)}, []interface{}{"Items", ALL, "ID"}, []interface{}(nil)},
+		{[]Option{Limit(0)}, []interface{}{"Items", ALL, "ID"}, []interface{}{1, 2}},
+		{[]Option{Limit(1)}, []interface{}{"Meta", ALL}, map[string]interface{}{"Count": 2}},
+		{[]Option{OutOfRange(IndexMissing)}, []interface{}{"Items", 2}, nil},
+		{[]Option{OutOfRange(IndexClamp)}, []interface{}{"Items", 5, "ID"}, 2},
+		{[]Option{OutOfRange(IndexClamp)}, []interface{}{"Items", "-3", "ID"}, 1},
+		{[]Option{OutOfRange(IndexWrap)}, []interface{}{"Items", -1, "ID"}, 2},
+		{[]Option{OutOfRange(IndexWrap)}, []interface{}{"Items", "-2", "ID"}, 1},
+		{[]Option{OutOfRange(IndexWrap)}, []interface{}{"Items", -3}, nil},
+		{[]Option{OutOfRange(IndexWrap)}, []interface{}{"Items", 2}, nil},
+		{[]Option{OutOfRange(IndexError)}, []interface{}{"Items", 2}, ee},
+		{[]Option{OutOfRange(IndexError)}, []interface{}{"Items", uint(1), "ID"}, 2},
+	} {
+		r := QWith(root, tc.opts, tc.path...)
+		if tc.expect == ee {
+			if _, ok := r.(error); !ok {
+				t.Errorf("%v:  expected error, got %v (%T)", tc.path, r, r)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(r, tc.expect) {
+			t.Errorf("%v:  expected %v, got %v (%T)", tc.path, tc.expect, r, r)
+		}
+	}
+
+	if err, _ := QWith(root, []Option{StrictMissing()}, "nosuchkey").(error); !errors.Is(err, ErrNotFound) {
+		t.Errorf("StrictMissing: expected ErrNotFound, got %v", err)
+	}
+	if r := QQWith(root, []Option{Separator("."), CaseInsensitive()}, "meta.count"); r != 2 {
+		t.Errorf("Separator: expected 2, got %v", r)
+	}
+	if r := QQWith(testObj, []Option{Separator(".")}, "subobj.subsubobj.array.*"); !reflect.DeepEqual(r, []interface{}{"hello", "world"}) {
+		t.Errorf("Separator: got %v", r)
+	}
+	nested := [][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}}
+	if r := QWith(nested, []Option{Offset(1), Limit(1)}, ALL, ALL); !reflect.DeepEqual(r, []interface{}{[]interface{}{4, 5, 6}}) {
+		t.Errorf("Offset and Limit on nested ALL: got %v", r)
+	}
+	if r := QQWith(testObj, nil, "subobj/foo"); r != 1. {
+		t.Errorf("QQWith: got %v", r)
+	}
+}
+
+func TestOutOfRangeEmpty(t *testing.T) {
+	for _, p := range []IndexPolicy{IndexMissing, IndexClamp, IndexWrap} {
+		if v := QWith([]int{}, []Option{OutOfRange(p)}, -1); v != nil {
+			t.Errorf("policy %d: expected nil, got %v", p, v)
+		}
+	}
+	if v := QWith([]int{}, []Option{OutOfRange(IndexError)}, 0); !isError(v) {
+		t.Errorf("IndexError: expected an error, got %v", v)
+	}
+}
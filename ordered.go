@@ -0,0 +1,207 @@
+package jq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// OrderedMap is implemented by maps that remember the order of their keys,
+// such as those produced by order-preserving JSON decoders. Other ordered map
+// implementations can be queried by wrapping them in a type that implements it.
+//
+// Q looks up string keys with Get, and ALL and KEYS produce results in the order
+// returned by Keys.
+type OrderedMap interface {
+	Keys() []string
+	Get(key string) (interface{}, bool)
+}
+
+// Ordered is the result of ALL applied to an OrderedMap.
+// It implements OrderedMap itself and marshals to a JSON object with the keys in order.
+type Ordered struct {
+	keys []string
+	vals map[string]interface{}
+}
+
+// Keys returns the keys in the order they were selected.
+func (o *Ordered) Keys() []string { return o.keys }
+
+// Get returns the value stored under key.
+func (o *Ordered) Get(key string) (interface{}, bool) {
+	v, ok := o.vals[key]
+	return v, ok
+}
+
+// Len returns the number of entries.
+func (o *Ordered) Len() int { return len(o.keys) }
+
+// MarshalJSON encodes o as a JSON object, preserving the key order.
+func (o *Ordered) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		kk, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		vv, err := json.Marshal(o.vals[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(kk)
+		b.WriteByte(':')
+		b.Write(vv)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+func (c *config) qOrdered(m OrderedMap, index []interface{}) interface{} {
+	if i, ok := index[0].(quantifier); ok && i == ALL {
+		c := c.unbounded()
+		r := &Ordered{vals: make(map[string]interface{})}
+		for _, k := range m.Keys() {
+			v, _ := m.Get(k)
+			rr := c.next(v, k, index[1:])
+			if rr == nil {
+				continue
+			}
+			if _, ok := rr.(error); ok {
+				continue
+			}
+			r.keys = append(r.keys, k)
+			r.vals[k] = rr
+		}
+		return r
+	}
+
+	k, ok := index[0].(string)
+	if !ok {
+		return fmt.Errorf("cannot use %v (type %T) as map key of type string", index[0], index[0])
+	}
+	if v, ok := m.Get(k); ok {
+		return c.next(v, k, index[1:])
+	}
+	if c.fold {
+		for _, kk := range m.Keys() {
+			if strings.EqualFold(kk, k) {
+				v, _ := m.Get(kk)
+				return c.next(v, kk, index[1:])
+			}
+		}
+	}
+	return c.missing(index[0])
+}
+
+// keys returns the keys of root as a slice:
+// the keys of an OrderedMap in order, the keys of a map or *sync.Map sorted,
+// the exported field names of a struct in declaration order
+// and the indices of an array or slice.
+func keys(root interface{}) interface{} {
+	return plain.keys(root)
+}
+
+// keys implements keys, naming struct fields according to c.
+func (c *config) keys(root interface{}) interface{} {
+	switch r := root.(type) {
+	case OrderedMap:
+		var a []interface{}
+		for _, k := range r.Keys() {
+			a = append(a, k)
+		}
+		return a
+	case *sync.Map:
+		var kk []reflect.Value
+		r.Range(func(k, _ interface{}) bool {
+			kk = append(kk, reflect.ValueOf(k))
+			return true
+		})
+		return keySlice(sortKeys(kk))
+	}
+
+	switch v := reflect.ValueOf(root); v.Kind() {
+	case reflect.Map:
+		return keySlice(sortKeys(v.MapKeys()))
+	case reflect.Struct:
+		var a []interface{}
+		for ii := 0; ii < v.NumField(); ii++ {
+			if f := v.Type().Field(ii); f.PkgPath == "" {
+				a = append(a, c.fieldName(f))
+			}
+		}
+		return a
+	case reflect.Array, reflect.Slice:
+		a := make([]interface{}, v.Len())
+		for ii := range a {
+			a[ii] = ii
+		}
+		return a
+	}
+	return fmt.Errorf("type %T does not support retrieving KEYS", root)
+}
+
+func keySlice(kk []reflect.Value) []interface{} {
+	a := make([]interface{}, len(kk))
+	for i, k := range kk {
+		if k.IsValid() {
+			a[i] = k.Interface()
+		}
+	}
+	return a
+}
+
+// sortKeys sorts map keys: nil first, then numbers numerically, strings lexically,
+// and anything else by its formatted value. Keys of interface type are sorted by their
+// dynamic value, and left unchanged, so they can still be used with MapIndex.
+func sortKeys(kk []reflect.Value) []reflect.Value {
+	rank := func(k reflect.Value) int {
+		switch k.Kind() {
+		case reflect.Invalid:
+			return -1
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return 0
+		case reflect.String:
+			return 1
+		}
+		return 2
+	}
+	num := func(k reflect.Value) float64 {
+		switch k.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return float64(k.Int())
+		case reflect.Uint, reflect.Uintptr, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return float64(k.Uint())
+		}
+		return k.Float()
+	}
+	elem := func(k reflect.Value) reflect.Value {
+		if k.Kind() == reflect.Interface {
+			return k.Elem() // invalid for a nil key
+		}
+		return k
+	}
+	sort.SliceStable(kk, func(i, j int) bool {
+		a, b := elem(kk[i]), elem(kk[j])
+		ra, rb := rank(a), rank(b)
+		switch {
+		case ra != rb:
+			return ra < rb
+		case ra == 0:
+			return num(a) < num(b)
+		case ra == 1:
+			return a.String() < b.String()
+		}
+		return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+	})
+	return kk
+}
@@ -0,0 +1,113 @@
+package jq
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testOrderedMap struct {
+	keys []string
+	vals map[string]interface{}
+}
+
+func (m testOrderedMap) Keys() []string { return m.keys }
+
+func (m testOrderedMap) Get(k string) (interface{}, bool) {
+	v, ok := m.vals[k]
+	return v, ok
+}
+
+func TestOrdered(t *testing.T) {
+	om := testOrderedMap{
+		keys: []string{"zeta", "alpha", "mid"},
+		vals: map[string]interface{}{
+			"zeta":  map[string]interface{}{"n": 1},
+			"alpha": map[string]interface{}{"n": 2},
+			"mid":   "x",
+		},
+	}
+
+	if v := QQ(om, "alpha/n"); v != 2 {
+		t.Errorf("[%q]:  expected %v, got %v (%T)", "alpha/n", 2, v, v)
+	}
+	if v := QQ(om, "nosuchkey"); v != nil {
+		t.Errorf("[%q]:  expected %v, got %v (%T)", "nosuchkey", nil, v, v)
+	}
+	if v := Q(om, 0); v == nil {
+		t.Errorf("[0]:  expected error, got nil")
+	}
+	if v := Q(om, KEYS); !reflect.DeepEqual(v, []interface{}{"zeta", "alpha", "mid"}) {
+		t.Errorf("[KEYS]:  expected document order, got %v", v)
+	}
+
+	all, ok := QQ(om, "*/n").(*Ordered)
+	if !ok {
+		t.Fatalf("[%q]: expected *Ordered, got %T", "*/n", QQ(om, "*/n"))
+	}
+	if !reflect.DeepEqual(all.Keys(), []string{"zeta", "alpha"}) {
+		t.Errorf("[%q]:  expected keys %v, got %v", "*/n", []string{"zeta", "alpha"}, all.Keys())
+	}
+	if b, err := json.Marshal(all); err != nil || string(b) != `{"zeta":1,"alpha":2}` {
+		t.Errorf("[%q]: marshaled to %s, %v", "*/n", b, err)
+	}
+	if v := Q(all, KEYS, 1); v != "alpha" {
+		t.Errorf("[KEYS 1]:  expected %v, got %v", "alpha", v)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	for _, tc := range []struct {
+		root   interface{}
+		expect interface{}
+	}{
+		{map[string]int{"b": 1, "a": 2}, []interface{}{"a", "b"}},
+		{map[int]int{10: 1, 9: 2}, []interface{}{9, 10}},
+		{map[interface{}]int{"x": 1, 2: 2, int64(1): 3}, []interface{}{int64(1), 2, "x"}},
+		{map[interface{}]int{"a": 2, nil: 1}, []interface{}{nil, "a"}},
+		{[]string{"a", "b"}, []interface{}{0, 1}},
+		{struct {
+			B, A   int
+			hidden int
+		}{}, []interface{}{"B", "A"}},
+		{0, ee},
+	} {
+		v := Q(tc.root, KEYS)
+		if _, ok := tc.expect.(error); ok {
+			if _, ok := v.(error); !ok {
+				t.Errorf("%#v [KEYS]: expected error, got %v (%T) ", tc.root, v, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%#v [KEYS]:  expected %v, got %v (%T)", tc.root, tc.expect, v, v)
+		}
+	}
+}
+
+func TestNilKey(t *testing.T) {
+	m := map[interface{}]interface{}{nil: 1, "a": 2}
+	if v := Q(m, nil); v != 1 {
+		t.Errorf("Q nil key: got %v", v)
+	}
+	if !Exists(m, nil) {
+		t.Errorf("Exists nil key: expected true")
+	}
+	if v := Values(m); !reflect.DeepEqual(v, []interface{}{1, 2}) {
+		t.Errorf("Values: got %v", v)
+	}
+	var paths []interface{}
+	Walk(m, func(path []interface{}, _ interface{}) error {
+		if len(path) == 1 {
+			paths = append(paths, path[0])
+		}
+		return nil
+	})
+	if !reflect.DeepEqual(paths, []interface{}{nil, "a"}) {
+		t.Errorf("Walk: got paths %v", paths)
+	}
+	if s := Sdump(m); !strings.Contains(s, "<nil>: 1") {
+		t.Errorf("Sdump: got %s", s)
+	}
+}
@@ -0,0 +1,69 @@
+package jq
+
+// A Stage transforms an input into any number of outputs, like a filter of jq.
+// *Expr is a Stage, and At, StageFunc and Pipe make others.
+type Stage interface {
+	Run(in interface{}) ([]interface{}, error)
+}
+
+// StageFunc adapts a function to a Stage.
+type StageFunc func(in interface{}) ([]interface{}, error)
+
+// Run calls f(in).
+func (f StageFunc) Run(in interface{}) ([]interface{}, error) {
+	return f(in)
+}
+
+// At returns a Stage that resolves the slash separated path in its input like QQ.
+// If the path contains "*", each value it selects is a separate output, so the next stage
+// of a Pipe runs once for each, like with .[] in jq; nil values, including those that are not present,
+// and values whose query fails are skipped.
+// Otherwise the stage has a single output, nil if the value is not present, or fails
+// if the query does.
+func At(path string) Stage {
+	index, err := parsePath(path)
+	return StageFunc(func(in interface{}) ([]interface{}, error) {
+		if err != nil {
+			return nil, err
+		}
+		if !hasQuantifier(index, ALL) {
+			r := Q(in, index...)
+			if err, ok := r.(error); ok {
+				return nil, err
+			}
+			return []interface{}{r}, nil
+		}
+		var out []interface{}
+		stream(in, index, nil, func(_ []interface{}, v interface{}) bool {
+			if _, ok := v.(error); !ok && v != nil {
+				out = append(out, v)
+			}
+			return true
+		})
+		return out, nil
+	})
+}
+
+// Pipe returns a Stage that feeds every output of each stage to the next, like | in jq:
+//
+//	names := jq.Pipe(jq.At("users/*"), jq.MustCompile(`select(.active)`), jq.At("name"))
+//
+// A Pipe with no stages outputs its input. It stops at the first error and returns
+// the outputs of the last stage produced so far along with it.
+func Pipe(stages ...Stage) Stage {
+	return StageFunc(func(in interface{}) ([]interface{}, error) {
+		cur := []interface{}{in}
+		for _, s := range stages {
+			var next []interface{}
+			for _, v := range cur {
+				out, err := s.Run(v)
+				next = append(next, out...)
+				if err != nil {
+					return next, err
+				}
+			}
+			cur = next
+		}
+		return cur, nil
+	})
+}
@@ -0,0 +1,58 @@
+package jq
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPipe(t *testing.T) {
+	root := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "ann", "active": true, "roles": []interface{}{"admin", "dev"}},
+			map[string]interface{}{"name": "bob", "active": false, "roles": []interface{}{"dev"}},
+			map[string]interface{}{"name": "cid", "active": true},
+		},
+	}
+	upper := StageFunc(func(in interface{}) ([]interface{}, error) {
+		return []interface{}{strings.ToUpper(String(in))}, nil
+	})
+
+	for _, tc := range []struct {
+		stage  Stage
+		expect []interface{}
+	}{
+		{Pipe(), []interface{}{root}},
+		{Pipe(At("users/*"), MustCompile(`select(.active)`), At("name")), []interface{}{"ann", "cid"}},
+		{Pipe(At("users/*/roles/*"), upper), []interface{}{"ADMIN", "DEV", "DEV"}},
+		{Pipe(At("users"), MustCompile(`.[]`), At("roles")), []interface{}{[]interface{}{"admin", "dev"}, []interface{}{"dev"}, nil}},
+		{Pipe(At("users/0"), Pipe(At("roles/*"), upper)), []interface{}{"ADMIN", "DEV"}},
+		{Pipe(At("users/*/name"), MustCompile(`select(. != "bob")`)), []interface{}{"ann", "cid"}},
+		{Pipe(MustCompile(`.users | length`)), []interface{}{3}},
+	} {
+		out, err := tc.stage.Run(root)
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(out, tc.expect) {
+			t.Errorf("expected %v, got %v", tc.expect, out)
+		}
+	}
+
+	boom := errors.New("boom")
+	failing := StageFunc(func(in interface{}) ([]interface{}, error) {
+		if in == "bob" {
+			return nil, boom
+		}
+		return []interface{}{in}, nil
+	})
+	out, err := Pipe(At("users/*/name"), failing, upper).Run(root)
+	if err != boom || !reflect.DeepEqual(out, []interface{}{"ann"}) {
+		t.Errorf("error: got %v, %v", out, err)
+	}
+	if _, err := Pipe(At("users/name")).Run(root); err == nil {
+		t.Errorf("At: expected query error")
+	}
+}
@@ -0,0 +1,180 @@
+package jq
+
+// A Pipeline is a query followed by transformations of the values it selects, which runs
+// only when one of the getters at the end of the chain is called:
+//
+//	names := jq.From(root, "users/*").
+//		Filter(func(u interface{}) bool { return Bool(u, "active") }).
+//		Path("name").
+//		Limit(10).
+//		Strings()
+//
+// The stages are fused: each value selected by the query passes through all of them before
+// the next one is selected, so no intermediate slices are built, and the query stops as soon
+// as a Limit is reached. Like a Result, a Pipeline is cheap to build and extending one leaves
+// it unmodified, so a common prefix can be shared.
+type Pipeline struct {
+	root   interface{}
+	index  []interface{}
+	err    error                  // of a malformed path passed to From
+	stages []func() pipelineStage // create the state of each stage for a run
+}
+
+// pipelineStage processes a value: it returns the value to pass on, whether to pass it on,
+// and whether to stop after it.
+type pipelineStage func(v interface{}) (out interface{}, keep, stop bool)
+
+// From starts a pipeline over the values selected by the slash separated path in root.
+// If the path contains "*", each value it selects enters the pipeline separately, as with
+// Iter; otherwise the value at path is the only one. Errors and nil values, including
+// values that are not present, are skipped, and so a malformed path yields no values.
+func From(root interface{}, path string) Pipeline {
+	index, err := parsePath(path)
+	return Pipeline{root: root, index: index, err: err}
+}
+
+// with returns a copy of p with the stage created by s appended.
+func (p Pipeline) with(s func() pipelineStage) Pipeline {
+	ss := make([]func() pipelineStage, 0, len(p.stages)+1)
+	return Pipeline{root: p.root, index: p.index, err: p.err, stages: append(append(ss, p.stages...), s)}
+}
+
+// Filter passes on the values for which pred returns true.
+func (p Pipeline) Filter(pred func(interface{}) bool) Pipeline {
+	return p.with(func() pipelineStage {
+		return func(v interface{}) (interface{}, bool, bool) { return v, pred(v), false }
+	})
+}
+
+// Map passes on the results of fn for the values.
+func (p Pipeline) Map(fn func(interface{}) interface{}) Pipeline {
+	return p.with(func() pipelineStage {
+		return func(v interface{}) (interface{}, bool, bool) { return fn(v), true, false }
+	})
+}
+
+// Path passes on the values at the slash separated path in the values, like QQ, skipping
+// errors and nil values like From.
+func (p Pipeline) Path(path string) Pipeline {
+	index, err := parsePath(path)
+	return p.with(func() pipelineStage {
+		return func(v interface{}) (interface{}, bool, bool) {
+			if err != nil {
+				return nil, false, false
+			}
+			r := Q(v, index...)
+			if _, ok := r.(error); ok || r == nil {
+				return nil, false, false
+			}
+			return r, true, false
+		}
+	})
+}
+
+// Skip drops the first n values.
+func (p Pipeline) Skip(n int) Pipeline {
+	return p.with(func() pipelineStage {
+		seen := 0
+		return func(v interface{}) (interface{}, bool, bool) {
+			seen++
+			return v, seen > n, false
+		}
+	})
+}
+
+// Limit passes on at most n values, and ends the pipeline after the last of them.
+func (p Pipeline) Limit(n int) Pipeline {
+	return p.with(func() pipelineStage {
+		passed := 0
+		return func(v interface{}) (interface{}, bool, bool) {
+			if passed >= n {
+				return nil, false, true
+			}
+			passed++
+			return v, true, passed >= n
+		}
+	})
+}
+
+// Each runs the pipeline and calls fn for each value that comes out of it,
+// until fn returns false.
+func (p Pipeline) Each(fn func(interface{}) bool) {
+	if p.err != nil {
+		return
+	}
+	stages := make([]pipelineStage, len(p.stages))
+	for i, s := range p.stages {
+		stages[i] = s()
+	}
+	stream(p.root, p.index, nil, func(_ []interface{}, v interface{}) bool {
+		if _, ok := v.(error); ok || v == nil {
+			return true
+		}
+		more := true
+		for _, s := range stages {
+			var keep, stop bool
+			v, keep, stop = s(v)
+			if stop {
+				more = false
+			}
+			if !keep {
+				return more
+			}
+		}
+		return fn(v) && more
+	})
+}
+
+// Values runs the pipeline and returns the values that come out of it.
+func (p Pipeline) Values() []interface{} {
+	var a []interface{}
+	p.Each(func(v interface{}) bool {
+		a = append(a, v)
+		return true
+	})
+	return a
+}
+
+// First runs the pipeline until the first value comes out of it, and returns that value,
+// or nil and false if there is none.
+func (p Pipeline) First() (interface{}, bool) {
+	var (
+		r     interface{}
+		found bool
+	)
+	p.Each(func(v interface{}) bool {
+		r, found = v, true
+		return false
+	})
+	return r, found
+}
+
+// Count runs the pipeline and returns the number of values that come out of it.
+func (p Pipeline) Count() int {
+	n := 0
+	p.Each(func(interface{}) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Strings runs the pipeline and returns the values that come out of it converted like String.
+func (p Pipeline) Strings() []string {
+	var ss []string
+	p.Each(func(v interface{}) bool {
+		ss = append(ss, asString(v))
+		return true
+	})
+	return ss
+}
+
+// Ints runs the pipeline and returns the values that come out of it converted like Int.
+func (p Pipeline) Ints() []int {
+	var nn []int
+	p.Each(func(v interface{}) bool {
+		nn = append(nn, asInt(v))
+		return true
+	})
+	return nn
+}
@@ -0,0 +1,79 @@
+package jq
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	var root interface{}
+	d := json.NewDecoder(strings.NewReader(`{"users":[
+		{"name":"ann","age":31,"active":true},
+		{"name":"bob","age":17,"active":false},
+		{"name":"cid","age":45,"active":true},
+		{"age":52,"active":true},
+		{"name":"eve","age":28,"active":true}
+	],"one":{"name":"solo"}}`))
+	d.UseNumber()
+	if err := d.Decode(&root); err != nil {
+		t.Fatal(err)
+	}
+	active := func(v interface{}) bool { return Bool(v, "active") }
+
+	for _, tc := range []struct {
+		name   string
+		p      Pipeline
+		expect []interface{}
+	}{
+		{"all", From(root, "users/*/name"), []interface{}{"ann", "bob", "cid", "eve"}},
+		{"single", From(root, "one/name"), []interface{}{"solo"}},
+		{"missing", From(root, "none/*"), nil},
+		{"filter path", From(root, "users/*").Filter(active).Path("name"), []interface{}{"ann", "cid", "eve"}},
+		{"limit", From(root, "users/*").Filter(active).Path("name").Limit(2), []interface{}{"ann", "cid"}},
+		{"limit before path", From(root, "users/*").Filter(active).Limit(4).Path("name"), []interface{}{"ann", "cid", "eve"}},
+		{"skip", From(root, "users/*/name").Skip(1).Limit(2), []interface{}{"bob", "cid"}},
+		{"limit zero", From(root, "users/*").Limit(0), nil},
+		{"map", From(root, "users/*/age").Map(func(v interface{}) interface{} { return asInt(v) / 10 }), []interface{}{3, 1, 4, 5, 2}},
+	} {
+		if v := tc.p.Values(); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.expect, v)
+		}
+		if n := tc.p.Count(); n != len(tc.expect) {
+			t.Errorf("%s: Count: expected %d, got %d", tc.name, len(tc.expect), n)
+		}
+	}
+
+	// Stages run lazily and stop the query at the limit.
+	visited := 0
+	p := From(root, "users/*").Filter(func(v interface{}) bool { visited++; return active(v) }).Limit(2)
+	if visited != 0 {
+		t.Errorf("pipeline ran before a getter was called")
+	}
+	if ss := p.Path("name").Strings(); !reflect.DeepEqual(ss, []string{"ann", "cid"}) {
+		t.Errorf("Strings: got %v", ss)
+	}
+	if visited != 3 {
+		t.Errorf("expected 3 values visited, got %d", visited)
+	}
+
+	// Each run starts with fresh state, and extending a pipeline leaves it unmodified.
+	base := From(root, "users/*/age").Limit(3)
+	if nn := base.Ints(); !reflect.DeepEqual(nn, []int{31, 17, 45}) {
+		t.Errorf("Ints: got %v", nn)
+	}
+	if nn := base.Skip(1).Ints(); !reflect.DeepEqual(nn, []int{17, 45}) {
+		t.Errorf("Ints after Skip: got %v", nn)
+	}
+	if nn := base.Ints(); len(nn) != 3 {
+		t.Errorf("Ints rerun: got %v", nn)
+	}
+
+	if v, ok := From(root, "users/*").Filter(func(v interface{}) bool { return Int(v, "age") > 40 }).Path("name").First(); !ok || v != "cid" {
+		t.Errorf("First: got %v, %v", v, ok)
+	}
+	if v, ok := From(root, "users/*").Filter(func(interface{}) bool { return false }).First(); ok || v != nil {
+		t.Errorf("First of empty: got %v, %v", v, ok)
+	}
+}
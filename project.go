@@ -0,0 +1,144 @@
+package jq
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Project returns a new document holding only the values at the slash separated paths,
+// at the same paths as in root, as needed to serve a "fields=" parameter from a full object:
+//
+//	Project(user, "id", "address/city", "orders/*/total")
+//
+// yields an object with the id of the user, an address object with only the city and an
+// array of objects with only the total of each order. Objects, including structs and maps of
+// any type, are returned as map[string]interface{} and arrays as []interface{}; an array
+// selected by index, as in "orders/0/total", holds the selected elements only, in order,
+// and elements in which none of the paths resolve are left out of arrays.
+// Paths that do not resolve are left out, and Project returns nil if none resolves.
+// The selected values are not copied, so they are shared with root.
+func Project(root interface{}, paths ...string) interface{} {
+	t := &projection{}
+	for _, p := range paths {
+		if index, err := parsePath(p); err == nil {
+			t.insert(index)
+		}
+	}
+	v, ok := t.project(root)
+	if !ok {
+		return nil
+	}
+	return v
+}
+
+// projection is a tree of the paths selected by Project.
+type projection struct {
+	leaf     bool // the whole value is selected
+	children map[string]*projection
+}
+
+func (t *projection) insert(path []interface{}) {
+	for _, e := range path {
+		k := "*"
+		if e != ALL {
+			k = fmt.Sprint(e)
+		}
+		if t.children == nil {
+			t.children = make(map[string]*projection)
+		}
+		c, ok := t.children[k]
+		if !ok {
+			c = &projection{}
+			t.children[k] = c
+		}
+		t = c
+	}
+	t.leaf = true
+}
+
+// child returns the projection of the element k of a container, combining the one
+// for k with the one for all elements.
+func (t *projection) child(k string) *projection {
+	c, all := t.children[k], t.children["*"]
+	switch {
+	case c == nil:
+		return all
+	case all == nil:
+		return c
+	}
+	return mergeProjections(c, all)
+}
+
+// mergeProjections returns a projection selecting the values selected by a or b.
+func mergeProjections(a, b *projection) *projection {
+	m := &projection{leaf: a.leaf || b.leaf, children: make(map[string]*projection)}
+	for _, t := range []*projection{a, b} {
+		for k, c := range t.children {
+			if mc, ok := m.children[k]; ok {
+				c = mergeProjections(mc, c)
+			}
+			m.children[k] = c
+		}
+	}
+	return m
+}
+
+// project returns the part of v selected by t, and whether there is one.
+func (t *projection) project(v interface{}) (interface{}, bool) {
+	if t.leaf {
+		return v, true
+	}
+	v = unwrap(v)
+	switch order(v) {
+	case orderObject:
+		var kk []string
+		if _, ok := t.children["*"]; ok {
+			all, _ := keys(v).([]interface{})
+			for _, k := range all {
+				kk = append(kk, fmt.Sprint(k))
+			}
+		} else {
+			for k := range t.children {
+				kk = append(kk, k)
+			}
+		}
+		m := make(map[string]interface{})
+		for _, k := range kk {
+			e, ok := lookup(v, []interface{}{k})
+			if !ok {
+				continue
+			}
+			if r, ok := t.child(k).project(e); ok {
+				m[k] = r
+			}
+		}
+		return m, len(m) > 0
+	case orderArray:
+		var ii []int
+		if _, ok := t.children["*"]; ok {
+			for i := range elements(v) {
+				ii = append(ii, i)
+			}
+		} else {
+			for k := range t.children {
+				if i, err := strconv.Atoi(k); err == nil {
+					ii = append(ii, i)
+				}
+			}
+			sort.Ints(ii)
+		}
+		var a []interface{}
+		for _, i := range ii {
+			e, ok := lookup(v, []interface{}{i})
+			if !ok {
+				continue
+			}
+			if r, ok := t.child(strconv.Itoa(i)).project(e); ok {
+				a = append(a, r)
+			}
+		}
+		return a, len(a) > 0
+	}
+	return nil, false
+}
@@ -0,0 +1,53 @@
+package jq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	type address struct {
+		City, Street string
+	}
+	user := map[string]interface{}{
+		"id":      7,
+		"name":    "ann",
+		"address": address{City: "Oslo", Street: "Main"},
+		"orders": []interface{}{
+			map[string]interface{}{"id": 1, "total": 10.5, "items": []interface{}{"a"}},
+			map[string]interface{}{"id": 2, "total": 3.0},
+			map[string]interface{}{"id": 3},
+		},
+		"byID": map[int]string{1: "x", 2: "y"},
+	}
+
+	for _, tc := range []struct {
+		paths  []string
+		expect interface{}
+	}{
+		{[]string{"id"}, map[string]interface{}{"id": 7}},
+		{[]string{"id", "address/City"}, map[string]interface{}{"id": 7, "address": map[string]interface{}{"City": "Oslo"}}},
+		{[]string{"orders/*/total"}, map[string]interface{}{"orders": []interface{}{
+			map[string]interface{}{"total": 10.5},
+			map[string]interface{}{"total": 3.0},
+		}}},
+		{[]string{"orders/2/id", "orders/0/total"}, map[string]interface{}{"orders": []interface{}{
+			map[string]interface{}{"total": 10.5},
+			map[string]interface{}{"id": 3},
+		}}},
+		{[]string{"orders/*/total", "orders/0/items"}, map[string]interface{}{"orders": []interface{}{
+			map[string]interface{}{"total": 10.5, "items": []interface{}{"a"}},
+			map[string]interface{}{"total": 3.0},
+		}}},
+		{[]string{"orders/1", "orders/1/id"}, map[string]interface{}{"orders": []interface{}{user["orders"].([]interface{})[1]}}},
+		{[]string{"byID/2"}, map[string]interface{}{"byID": map[string]interface{}{"2": "y"}}},
+		{[]string{"*/City"}, map[string]interface{}{"address": map[string]interface{}{"City": "Oslo"}}},
+		{[]string{"nosuchkey", "id/x", "orders/9"}, nil},
+		{nil, nil},
+		{[]string{""}, user},
+	} {
+		if v := Project(user, tc.paths...); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.paths, tc.expect, v)
+		}
+	}
+}
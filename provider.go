@@ -0,0 +1,73 @@
+package jq
+
+import (
+	"context"
+	"sync"
+)
+
+// A Provider is a root that is loaded when a query needs it, such as a configuration file
+// that is read and decoded on first use or a document fetched on demand. Q calls Load when
+// it descends into the provider, and applies the rest of the index to the result, or returns
+// the error. Load is called by every query, so implementations should cache the document,
+// as the providers returned by Lazy do.
+//
+// Q also accepts a func() interface{} as a root, which it calls in the same way.
+type Provider interface {
+	Load() (interface{}, error)
+}
+
+// A ContextProvider is a Provider that can be loaded with the context of a query, so that
+// a slow fetch stops when the caller gives up. Queries run with QCtx, QChan or a Result
+// bound with Context call LoadContext instead of Load.
+type ContextProvider interface {
+	Provider
+	LoadContext(ctx context.Context) (interface{}, error)
+}
+
+// Lazy returns a Provider that calls load on first use and caches its result. If load fails,
+// the error is returned, and load is called again by the next query. The provider is safe for
+// concurrent use, and load is not called concurrently.
+//
+//	cfg := jq.Lazy(func() (interface{}, error) { return readConfig("app.json") })
+//	port := jq.Int(cfg, "server", "port")
+func Lazy(load func() (interface{}, error)) Provider {
+	return &lazy{load: load}
+}
+
+type lazy struct {
+	mu     sync.Mutex
+	load   func() (interface{}, error)
+	loaded bool
+	v      interface{}
+}
+
+func (l *lazy) Load() (interface{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.loaded {
+		return l.v, nil
+	}
+	v, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+	l.v, l.loaded = v, true
+	return v, nil
+}
+
+// provided returns the document of v if it is a Provider or a func() interface{},
+// and v otherwise, loading context providers with the context of c.
+func (c *config) provided(v interface{}) (interface{}, error) {
+	switch p := v.(type) {
+	case ContextProvider:
+		if c.ctx != nil {
+			return p.LoadContext(c.ctx)
+		}
+		return p.Load()
+	case Provider:
+		return p.Load()
+	case func() interface{}:
+		return p(), nil
+	}
+	return v, nil
+}
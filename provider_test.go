@@ -0,0 +1,64 @@
+package jq
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProvider(t *testing.T) {
+	var loads int
+	fail := true
+	cfg := Lazy(func() (interface{}, error) {
+		loads++
+		if fail {
+			fail = false
+			return nil, errors.New("not yet")
+		}
+		return map[string]interface{}{"server": map[string]interface{}{"port": 8080, "tls": nil}, "tags": []interface{}{"a", "b"}}, nil
+	})
+
+	if v := Q(cfg, "server", "port"); !isError(v) {
+		t.Errorf("failing load: expected error, got %v", v)
+	}
+	for _, tc := range []struct {
+		path   string
+		expect interface{}
+	}{
+		{"server/port", 8080},
+		{"tags/*", []interface{}{"a", "b"}},
+		{"nosuchkey", nil},
+	} {
+		if v := QQ(cfg, tc.path); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("expected 2 loads, got %d", loads)
+	}
+
+	if !Exists(cfg, "server", "tls") || Exists(cfg, "server", "nosuchkey") {
+		t.Errorf("Exists: wrong result")
+	}
+	if v, err := QQE(cfg, ""); err != nil || QQ(v, "server/port") != 8080 {
+		t.Errorf("QQE of root: got %v, %v", v, err)
+	}
+	if v := Values(cfg, "tags"); !reflect.DeepEqual(v, []interface{}{"a", "b"}) {
+		t.Errorf("Values: got %v", v)
+	}
+
+	calls := 0
+	fn := func() interface{} {
+		calls++
+		return map[string]interface{}{"n": calls}
+	}
+	if v := Q(fn, "n"); v != 1 {
+		t.Errorf("func root: got %v", v)
+	}
+	if v := Q(map[string]interface{}{"nested": Lazy(func() (interface{}, error) { return []interface{}{1, 2}, nil })}, "nested", 1); v != 2 {
+		t.Errorf("nested provider: got %v", v)
+	}
+	if v := Q(Layered{Lazy(func() (interface{}, error) { return map[string]interface{}{"a": nil}, nil }), map[string]interface{}{"a": 1}}, "a"); v != nil {
+		t.Errorf("provider layer: got %v", v)
+	}
+}
@@ -0,0 +1,28 @@
+package jq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// decodeJSONString decodes s if it looks like a JSON object or array.
+func decodeJSONString(s string) (interface{}, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" || (s[0] != '{' && s[0] != '[') {
+		return nil, false
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+func decodeRawMessage(r json.RawMessage) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(r, &v); err != nil {
+		return nil, fmt.Errorf("cannot decode json.RawMessage: %v", err)
+	}
+	return v, nil
+}
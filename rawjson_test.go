@@ -0,0 +1,106 @@
+package jq
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeStrings(t *testing.T) {
+	root := map[string]interface{}{
+		"payload": `{"user": {"name": "alice"}, "tags": ["a", "b"]}`,
+		"text":    "not json",
+		"broken":  `{"user":`,
+	}
+
+	for _, tc := range []struct {
+		decode bool
+		path   string
+		expect interface{}
+	}{
+		{false, "payload/user/name", ee},
+		{true, "payload/user/name", "alice"},
+		{true, "payload/tags/*", []interface{}{"a", "b"}},
+		{true, "payload/nosuchkey", nil},
+		{true, "payload", root["payload"]}, // not descended into, so not decoded
+		{true, "text/x", ee},
+		{true, "broken/user", ee},
+	} {
+		var opts []Option
+		if tc.decode {
+			opts = append(opts, DecodeStrings())
+		}
+		v := QQWith(root, opts, tc.path)
+
+		if _, ok := tc.expect.(error); ok {
+			if _, ok := v.(error); !ok {
+				t.Errorf("%v [%q]: expected error, got %v (%T) ", tc.decode, tc.path, v, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%v [%q]:  expected %v, got %v (%T)", tc.decode, tc.path, tc.expect, v, v)
+		}
+	}
+}
+
+func TestDecodeStringsHelpers(t *testing.T) {
+	root := map[string]interface{}{"payload": `{"user": {"name": null}, "tags": ["a", "b"]}`}
+	c := newConfig([]Option{DecodeStrings()})
+
+	if _, ok := c.lookup(root, split("payload/user/name")); !ok {
+		t.Errorf("lookup with DecodeStrings: expected present null")
+	}
+	if Exists(root, "payload", "user") {
+		t.Errorf("Exists: expected the string not to be decoded")
+	}
+	var tags []interface{}
+	c.stream(root, split("payload/tags/*"), nil, func(_ []interface{}, v interface{}) bool {
+		tags = append(tags, v)
+		return true
+	})
+	if !reflect.DeepEqual(tags, QQWith(root, []Option{DecodeStrings()}, "payload/tags/*")) {
+		t.Errorf("stream with DecodeStrings: got %v", tags)
+	}
+	n := 0
+	for range Iter(root, "payload/tags/*") {
+		n++
+	}
+	if n != 1 {
+		t.Errorf("Iter: expected the undecoded string only, got %d values", n)
+	}
+}
+
+func TestRawMessage(t *testing.T) {
+	m := map[string]json.RawMessage{
+		"user":   json.RawMessage(`{"name": "alice", "roles": ["admin"]}`),
+		"broken": json.RawMessage(`{`),
+	}
+	a := []json.RawMessage{json.RawMessage(`{"id": 1}`), json.RawMessage(`{"id": 2}`)}
+
+	for _, tc := range []struct {
+		root   interface{}
+		path   string
+		expect interface{}
+	}{
+		{m, "user/name", "alice"},
+		{m, "user/roles/0", "admin"},
+		{m, "user/nosuchkey", nil},
+		{m, "user", m["user"]},
+		{m, "broken/x", ee},
+		{m, "*/name", map[string]interface{}{"user": "alice"}},
+		{a, "1/id", 2.},
+		{a, "*/id", []interface{}{1., 2.}},
+	} {
+		v := QQ(tc.root, tc.path)
+		if _, ok := tc.expect.(error); ok {
+			if _, ok := v.(error); !ok {
+				t.Errorf("%#v [%q]: expected error, got %v (%T) ", tc.root, tc.path, v, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%#v [%q]:  expected %v, got %v (%T)", tc.root, tc.path, tc.expect, v, v)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package jq
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Redact returns a copy of root in which the values at the slash separated paths are replaced
+// by "***", to mask secrets before documents are logged or forwarded:
+//
+//	log.Print(jq.Sdump(jq.Redact(req, "password", "**/token", "headers/Authorization")))
+//
+// Each element of a path is a pattern as accepted by path.Match, so "*" matches any key or
+// index and "*_secret" any key with that suffix, and the element "**" matches any number of
+// elements, including none, so "**/password" masks passwords at any depth. Values of any type,
+// including objects and arrays, are replaced as a whole. Paths that do not resolve are ignored.
+//
+// Root is not modified. The objects and arrays containing redacted values are copied, as
+// map[string]interface{} and []interface{}, and the others are shared with root.
+func Redact(root interface{}, paths ...string) interface{} {
+	return RedactWith(root, "***", paths...)
+}
+
+// RedactWith is like Redact, but replaces the values with replacement, or, if replacement
+// is a func(interface{}) interface{}, with its result for each value, such as the last
+// digits of a card number.
+func RedactWith(root interface{}, replacement interface{}, paths ...string) interface{} {
+	r := redactor{replace: func(interface{}) interface{} { return replacement }}
+	if fn, ok := replacement.(func(interface{}) interface{}); ok {
+		r.replace = fn
+	}
+	var states []patternState
+	for id, p := range paths {
+		var pat []string
+		if p != "" {
+			pat = strings.Split(p, "/")
+		}
+		states = closure(states, patternState{id, pat, 0})
+	}
+	v, _ := r.redact(root, states)
+	return v
+}
+
+type redactor struct {
+	replace func(interface{}) interface{}
+}
+
+// patternState is a position in a path pattern: the elements before i have been matched.
+type patternState struct {
+	id      int // of the pattern, to tell states apart
+	pattern []string
+	i       int
+}
+
+func (s patternState) done() bool {
+	return s.i == len(s.pattern)
+}
+
+// closure adds s to states, and the states reachable from it by matching "**" to no element.
+func closure(states []patternState, s patternState) []patternState {
+	for _, ss := range states {
+		if ss.id == s.id && ss.i == s.i {
+			return states
+		}
+	}
+	states = append(states, s)
+	if !s.done() && s.pattern[s.i] == "**" {
+		states = closure(states, patternState{s.id, s.pattern, s.i + 1})
+	}
+	return states
+}
+
+// match returns the states reached from states by the element k.
+func match(states []patternState, k string) []patternState {
+	var next []patternState
+	for _, s := range states {
+		if s.done() {
+			continue
+		}
+		p := s.pattern[s.i]
+		if p == "**" {
+			next = closure(next, s)
+			continue
+		}
+		if ok, _ := path.Match(p, k); ok {
+			next = closure(next, patternState{s.id, s.pattern, s.i + 1})
+		}
+	}
+	return next
+}
+
+// redact returns v with the values matched by states replaced, and whether anything was replaced.
+func (r redactor) redact(v interface{}, states []patternState) (interface{}, bool) {
+	if len(states) == 0 {
+		return v, false
+	}
+	for _, s := range states {
+		if s.done() {
+			return r.replace(v), true
+		}
+	}
+	u := unwrap(v)
+	switch order(u) {
+	case orderObject:
+		kk, _ := keys(u).([]interface{})
+		changed := make(map[string]interface{})
+		for _, k := range kk {
+			ks := fmt.Sprint(k)
+			if e, ok := r.redact(Q(u, k), match(states, ks)); ok {
+				changed[ks] = e
+			}
+		}
+		if len(changed) == 0 {
+			return v, false
+		}
+		m := make(map[string]interface{}, len(kk))
+		for _, k := range kk {
+			m[fmt.Sprint(k)] = Q(u, k)
+		}
+		for k, e := range changed {
+			m[k] = e
+		}
+		return m, true
+	case orderArray:
+		ee := elements(u)
+		var a []interface{}
+		for i, e := range ee {
+			e, changed := r.redact(e, match(states, strconv.Itoa(i)))
+			if !changed {
+				continue
+			}
+			if a == nil {
+				a = append([]interface{}(nil), ee...)
+			}
+			a[i] = e
+		}
+		if a == nil {
+			return v, false
+		}
+		return a, true
+	}
+	return v, false
+}
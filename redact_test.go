@@ -0,0 +1,66 @@
+package jq
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	doc := map[string]interface{}{
+		"user":     map[string]interface{}{"name": "ann", "password": "hunter2"},
+		"password": "root",
+		"headers":  map[string]interface{}{"Authorization": "Bearer x", "Accept": "*/*"},
+		"keys":     []interface{}{map[string]interface{}{"api_secret": "s1", "id": 1}, map[string]interface{}{"api_secret": "s2", "id": 2}},
+		"deep":     map[string]interface{}{"a": map[string]interface{}{"b": map[string]interface{}{"token": "t"}}},
+		"creds":    map[string]interface{}{"user": "u", "pass": "p"},
+	}
+
+	for _, tc := range []struct {
+		paths  []string
+		path   string
+		expect interface{}
+	}{
+		{[]string{"user/password"}, "user", map[string]interface{}{"name": "ann", "password": "***"}},
+		{[]string{"user/password"}, "password", "root"},
+		{[]string{"**/password"}, "password", "***"},
+		{[]string{"**/password"}, "user/password", "***"},
+		{[]string{"headers/Authorization"}, "headers/Accept", "*/*"},
+		{[]string{"headers/Authorization"}, "headers/Authorization", "***"},
+		{[]string{"keys/*/*_secret"}, "keys/*/api_secret", []interface{}{"***", "***"}},
+		{[]string{"keys/*/*_secret"}, "keys/*/id", []interface{}{1, 2}},
+		{[]string{"keys/1"}, "keys", []interface{}{doc["keys"].([]interface{})[0], "***"}},
+		{[]string{"**/token"}, "deep/a/b/token", "***"},
+		{[]string{"deep/**"}, "deep", "***"},
+		{[]string{"creds"}, "creds", "***"},
+		{[]string{"nosuchkey", "user/nosuchkey"}, "user", doc["user"]},
+		{[]string{""}, "", "***"},
+	} {
+		if v := QQ(Redact(doc, tc.paths...), tc.path); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%q at %q: expected %v, got %v", tc.paths, tc.path, tc.expect, v)
+		}
+	}
+
+	if QQ(doc, "user/password") != "hunter2" || QQ(doc, "keys/0/api_secret") != "s1" {
+		t.Errorf("Redact modified root")
+	}
+	r := Redact(doc, "user/password")
+	if reflect.ValueOf(QQ(r, "headers")).Pointer() != reflect.ValueOf(doc["headers"]).Pointer() {
+		t.Errorf("unredacted object was copied")
+	}
+
+	last4 := func(v interface{}) interface{} {
+		s := String(v)
+		return strings.Repeat("*", len(s)-1) + s[len(s)-1:]
+	}
+	if v := QQ(RedactWith(doc, last4, "user/password"), "user/password"); v != "******2" {
+		t.Errorf("RedactWith func: got %v", v)
+	}
+	if v := QQ(RedactWith(doc, nil, "password"), "password"); v != nil {
+		t.Errorf("RedactWith nil: got %v", v)
+	}
+	type login struct{ User, Password string }
+	if v := QQ(Redact(login{"ann", "pw"}, "Password"), "Password"); v != "***" {
+		t.Errorf("Redact of struct: got %v", v)
+	}
+}
@@ -0,0 +1,185 @@
+package jq
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Result is a query under construction, built by chaining calls that extend its path:
+//
+//	names := jq.New(root).Path("users/*").Path("name").Strings()
+//
+// The query runs when one of the getters at the end of the chain is called, so a Result
+// is cheap to build and extending one leaves it unmodified. Errors, such as indexing a
+// string or a missing key in a struct, are carried to the end of the chain and reported
+// by Err and Value; the typed getters return zero values for them like their package-level
+// counterparts do.
+type Result struct {
+	c     *config // nil for the behavior of Q
+	root  interface{}
+	index []interface{}
+	err   error // of a malformed path passed to Path
+}
+
+// New starts a query on root.
+func New(root interface{}) Result {
+	return Result{root: root}
+}
+
+// with returns a copy of r with index appended to its path.
+func (r Result) with(index ...interface{}) Result {
+	ii := make([]interface{}, 0, len(r.index)+len(index))
+	return Result{c: r.c, root: r.root, index: append(append(ii, r.index...), index...), err: r.err}
+}
+
+// config returns the options r was created with.
+func (r Result) config() *config {
+	if r.c == nil {
+		return &plain
+	}
+	return r.c
+}
+
+// Path extends the query with a slash separated path as accepted by QQ.
+// A malformed path makes the query fail with an error wrapping ErrBadPath.
+func (r Result) Path(path string) Result {
+	index, err := r.config().parsePath(path)
+	if err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.with(index...)
+}
+
+// Index extends the query with index elements as accepted by Q.
+func (r Result) Index(index ...interface{}) Result {
+	return r.with(index...)
+}
+
+// All extends the query with the ALL quantifier, so the rest of the chain applies to every element.
+func (r Result) All() Result {
+	return r.with(ALL)
+}
+
+// Keys extends the query with the KEYS quantifier.
+func (r Result) Keys() Result {
+	return r.with(KEYS)
+}
+
+// Value runs the query and returns its result, which is an error value if it failed.
+func (r Result) Value() interface{} {
+	if r.err != nil {
+		return r.err
+	}
+	return r.config().query(r.root, r.index)
+}
+
+// Err runs the query and returns its error, or nil if it succeeded.
+func (r Result) Err() error {
+	err, _ := r.Value().(error)
+	return err
+}
+
+// Interface runs the query and returns its result, or nil if it failed.
+func (r Result) Interface() interface{} {
+	v := r.Value()
+	if _, ok := v.(error); ok {
+		return nil
+	}
+	return v
+}
+
+// Raw runs the query and returns the JSON encoding of its result, or the error if it failed.
+// A value that is not present is encoded as null, like MarshalAt does.
+func (r Result) Raw() (json.RawMessage, error) {
+	v := r.Value()
+	if err, ok := v.(error); ok {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// Kind runs the query and returns the kind of its result, or KindMissing if it failed
+// or the value is not present.
+func (r Result) Kind() Kind {
+	if r.err != nil {
+		return KindMissing
+	}
+	sc := *r.config()
+	sc.strict = true // a missing value is an error, a present null is not
+	v := sc.query(r.root, r.index)
+	if _, ok := v.(error); ok {
+		return KindMissing
+	}
+	return KindOf(v)
+}
+
+// Exists reports whether the query resolves to a present value.
+func (r Result) Exists() bool {
+	if r.err != nil {
+		return false
+	}
+	c := *r.config()
+	c.hooks = nil
+	if c.guards != nil {
+		defer c.lockRoot(r.root)()
+	}
+	_, ok := c.lookup(r.root, r.index)
+	return ok
+}
+
+// String runs the query and converts its result like String, with the converters and
+// options of the Engine that created r, if any.
+func (r Result) String() string {
+	return r.config().asString(r.Value())
+}
+
+// Bool runs the query and converts its result like Bool.
+func (r Result) Bool() bool {
+	return r.config().asBool(r.Value())
+}
+
+// Int runs the query and converts its result like Int.
+func (r Result) Int() int {
+	return r.config().asInt(r.Value())
+}
+
+// Uint runs the query and converts its result like Uint.
+func (r Result) Uint() uint {
+	return r.config().asUint(r.Value())
+}
+
+// Time runs the query and converts its result like Time.
+func (r Result) Time() time.Time {
+	c := r.config()
+	return c.asTime(r.Value(), c.location())
+}
+
+// Strings runs the query and converts each element of its result like String.
+// It returns nil if the result is not an array.
+func (r Result) Strings() []string {
+	vv, ok := r.Value().([]interface{})
+	if !ok {
+		return nil
+	}
+	c := r.config()
+	ss := make([]string, len(vv))
+	for i, v := range vv {
+		ss[i] = c.asString(v)
+	}
+	return ss
+}
+
+// Ints runs the query and converts each element of its result like Int.
+// It returns nil if the result is not an array.
+func (r Result) Ints() []int {
+	vv, ok := r.Value().([]interface{})
+	if !ok {
+		return nil
+	}
+	c := r.config()
+	nn := make([]int, len(vv))
+	for i, v := range vv {
+		nn[i] = c.asInt(v)
+	}
+	return nn
+}
@@ -0,0 +1,81 @@
+package jq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResult(t *testing.T) {
+	r := New(testObj).Path("subobj")
+	if v := New(testStruct).Path("subobj").Path("foo").Int(); v != 1 {
+		t.Errorf("Int: expected 1, got %v", v)
+	}
+	if v := r.Path("subsubobj/array").Index(0).String(); v != "hello" {
+		t.Errorf("String: expected hello, got %q", v)
+	}
+	if v := r.Path("subsubobj/array").All().Strings(); !reflect.DeepEqual(v, []string{"hello", "world"}) {
+		t.Errorf("Strings: got %v", v)
+	}
+	if v := r.Path("subarray").All().Ints(); !reflect.DeepEqual(v, []int{0, 0, 0}) {
+		t.Errorf("Ints: got %v", v) // float64 values are not converted, like Int
+	}
+	if v := New(testStruct).Path("subobj/subarray").All().Ints(); !reflect.DeepEqual(v, []int{1, 2, 3}) {
+		t.Errorf("Ints: got %v", v)
+	}
+	if v := r.Path("subsubobj").Keys().Strings(); !reflect.DeepEqual(v, []string{"array", "bar", "baz"}) {
+		t.Errorf("Keys: got %v", v)
+	}
+
+	// extending a Result leaves it unmodified
+	a, b := r.Path("foo"), r.Path("subarray")
+	if a.Value() != 1. || b.Err() != nil || r.Value() == nil {
+		t.Errorf("branching: got %v %v %v", a.Value(), b.Value(), r.Value())
+	}
+
+	bad := New(testObj).Path("test/x").Path("y")
+	if bad.Err() == nil || bad.String() != "" || bad.Int() != 0 || bad.Strings() != nil || bad.Exists() {
+		t.Errorf("error: expected error to be carried, got %v", bad.Value())
+	}
+	if !New(testObj).Path("subobj/foo").Exists() || New(testObj).Path("nosuchkey").Exists() {
+		t.Errorf("Exists: wrong result")
+	}
+}
+
+func TestResultKind(t *testing.T) {
+	root := map[string]interface{}{"a": []interface{}{1., "x"}, "n": nil}
+	for _, tc := range []struct {
+		path   string
+		expect Kind
+	}{
+		{"a", KindArray},
+		{"a/0", KindNumber},
+		{"a/1", KindString},
+		{"n", KindNull},
+		{"nosuchkey", KindMissing},
+		{"a/1/x", KindMissing},
+		{"", KindObject},
+	} {
+		if k := New(root).Path(tc.path).Kind(); k != tc.expect {
+			t.Errorf("%q: expected %v, got %v", tc.path, tc.expect, k)
+		}
+	}
+	if k := NewEngine(StrictMissing()).New(root).Path("n").Kind(); k != KindNull {
+		t.Errorf("strict: expected null, got %v", k)
+	}
+
+	if v := New(root).Path("a/1").Interface(); v != "x" {
+		t.Errorf("Interface: got %v", v)
+	}
+	if v := New(root).Path("a/1/x").Interface(); v != nil {
+		t.Errorf("Interface of error: got %v", v)
+	}
+	if b, err := New(root).Path("a").Raw(); err != nil || string(b) != `[1,"x"]` {
+		t.Errorf("Raw: got %s, %v", b, err)
+	}
+	if b, err := New(root).Path("nosuchkey").Raw(); err != nil || string(b) != "null" {
+		t.Errorf("Raw of missing value: got %s, %v", b, err)
+	}
+	if _, err := New(root).Path("a/1/x").Raw(); err == nil {
+		t.Errorf("Raw of error: expected error")
+	}
+}
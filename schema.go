@@ -0,0 +1,157 @@
+package jq
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"unicode/utf8"
+)
+
+// ValidateSchema checks root against schema, a JSON Schema as decoded by json.Unmarshal
+// or any other value this package can query. It supports the keywords
+// type (a name or a list of names, including "integer"), enum, const, required, properties,
+// additionalProperties (as a boolean or schema), items, minimum, maximum, exclusiveMinimum,
+// exclusiveMaximum, minLength, maxLength, minItems and maxItems. Other keywords are ignored.
+// Values are compared with Equal, so 1 matches 1.0.
+//
+// ValidateSchema returns all violations joined together, each prefixed with the slash separated path
+// of the offending value, or "(root)", or nil if root is valid.
+func ValidateSchema(root, schema interface{}) error {
+	var errs []error
+	validate(root, schema, "", &errs)
+	return errors.Join(errs...)
+}
+
+// validate appends the violations of v, found at path, against schema to errs.
+func validate(v, schema interface{}, path string, errs *[]error) {
+	v = unwrap(v)
+	fail := func(format string, args ...interface{}) {
+		p := path
+		if p == "" {
+			p = "(root)"
+		}
+		*errs = append(*errs, fmt.Errorf("%s: %s", p, fmt.Sprintf(format, args...)))
+	}
+	at := func(k interface{}) string {
+		if path == "" {
+			return fmt.Sprint(k)
+		}
+		return fmt.Sprintf("%s/%v", path, k)
+	}
+	if b, ok := schema.(bool); ok {
+		if !b {
+			fail("no value allowed")
+		}
+		return
+	}
+
+	if t := Q(schema, "type"); t != nil {
+		names := []interface{}{t}
+		if order(t) == orderArray {
+			names = elements(t)
+		}
+		ok := false
+		for _, n := range names {
+			ok = ok || hasType(v, fmt.Sprint(n))
+		}
+		if !ok {
+			fail("expected %s, got %s", joinNames(names), KindOf(v))
+			return
+		}
+	}
+	if e, present := lookup(schema, []interface{}{"enum"}); present {
+		found := false
+		for _, ev := range elements(e) {
+			found = found || Equal(v, ev)
+		}
+		if !found {
+			fail("%s is not one of %s", canonical(v), canonical(e))
+		}
+	}
+	if c, present := lookup(schema, []interface{}{"const"}); present && !Equal(v, c) {
+		fail("%s is not %s", canonical(v), canonical(c))
+	}
+
+	if f, ok := number(v); ok {
+		if m, ok := number(Q(schema, "minimum")); ok && f < m {
+			fail("%v is less than minimum %v", f, m)
+		}
+		if m, ok := number(Q(schema, "maximum")); ok && f > m {
+			fail("%v is greater than maximum %v", f, m)
+		}
+		if m, ok := number(Q(schema, "exclusiveMinimum")); ok && f <= m {
+			fail("%v is not greater than %v", f, m)
+		}
+		if m, ok := number(Q(schema, "exclusiveMaximum")); ok && f >= m {
+			fail("%v is not less than %v", f, m)
+		}
+	}
+
+	switch KindOf(v) {
+	case KindString:
+		n := utf8.RuneCountInString(asString(v))
+		if m, ok := number(Q(schema, "minLength")); ok && float64(n) < m {
+			fail("length %d is less than %v", n, m)
+		}
+		if m, ok := number(Q(schema, "maxLength")); ok && float64(n) > m {
+			fail("length %d is greater than %v", n, m)
+		}
+
+	case KindArray:
+		ee := elements(v)
+		if m, ok := number(Q(schema, "minItems")); ok && float64(len(ee)) < m {
+			fail("%d items are fewer than %v", len(ee), m)
+		}
+		if m, ok := number(Q(schema, "maxItems")); ok && float64(len(ee)) > m {
+			fail("%d items are more than %v", len(ee), m)
+		}
+		if items, present := lookup(schema, []interface{}{"items"}); present {
+			for i, e := range ee {
+				validate(e, items, at(i), errs)
+			}
+		}
+
+	case KindObject:
+		for _, r := range elements(Q(schema, "required")) {
+			if !Exists(v, r) {
+				fail("missing required property %v", r)
+			}
+		}
+		props := Q(schema, "properties")
+		additional, restricted := lookup(schema, []interface{}{"additionalProperties"})
+		kk, _ := keys(v).([]interface{})
+		for _, k := range kk {
+			if ps, present := lookup(props, []interface{}{k}); present && props != nil {
+				validate(Q(v, k), ps, at(k), errs)
+			} else if restricted {
+				validate(Q(v, k), additional, at(k), errs)
+			}
+		}
+	}
+}
+
+// hasType reports whether v is of the JSON Schema type named t.
+func hasType(v interface{}, t string) bool {
+	switch t {
+	case "integer":
+		f, ok := number(v)
+		return ok && f == math.Trunc(f) && !math.IsInf(f, 0)
+	case "boolean":
+		return KindOf(v) == KindBool
+	}
+	return KindOf(v).String() == t
+}
+
+// joinNames formats type names for an error message.
+func joinNames(names []interface{}) string {
+	ss := make([]string, len(names))
+	for i, n := range names {
+		ss[i] = fmt.Sprint(n)
+	}
+	sort.Strings(ss)
+	if len(ss) == 1 {
+		return ss[0]
+	}
+	return fmt.Sprint(ss)
+}
@@ -0,0 +1,77 @@
+package jq
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+    "type": "object",
+    "required": ["id", "name", "tags"],
+    "properties": {
+        "id": {"type": "integer", "minimum": 1},
+        "name": {"type": "string", "minLength": 2, "maxLength": 10},
+        "role": {"enum": ["admin", "user"]},
+        "score": {"type": ["number", "null"], "exclusiveMaximum": 100},
+        "tags": {"type": "array", "maxItems": 3, "items": {"type": "string"}},
+        "address": {
+            "type": "object",
+            "properties": {"zip": {"type": "string"}},
+            "additionalProperties": false
+        }
+    }
+}`
+
+func TestValidateSchema(t *testing.T) {
+	var schema interface{}
+	if err := json.Unmarshal([]byte(testSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := map[string]interface{}{
+		"id":      3,
+		"name":    "ann",
+		"role":    "admin",
+		"score":   nil,
+		"tags":    []string{"a", "b"},
+		"address": map[string]interface{}{"zip": "12345"},
+		"extra":   true,
+	}
+	if err := ValidateSchema(valid, schema); err != nil {
+		t.Errorf("valid document: unexpected error %v", err)
+	}
+	invalid := map[string]interface{}{
+		"id":      1.5,
+		"name":    "x",
+		"role":    "root",
+		"score":   100,
+		"tags":    []interface{}{"a", 2, "c", "d"},
+		"address": map[string]interface{}{"zip": 12345, "city": "x"},
+	}
+	err := ValidateSchema(invalid, schema)
+	if err == nil {
+		t.Fatal("invalid document: expected errors")
+	}
+	expect := []string{
+		"address/city: no value allowed",
+		"address/zip: expected string, got number",
+		"id: expected integer, got number",
+		`name: length 1 is less than 2`,
+		`role: "root" is not one of ["admin","user"]`,
+		"score: 100 is not less than 100",
+		"tags: 4 items are more than 3",
+		"tags/1: expected string, got number",
+	}
+	got := strings.Split(err.Error(), "\n")
+	if strings.Join(got, "|") != strings.Join(expect, "|") {
+		t.Errorf("expected\n%s\ngot\n%s", strings.Join(expect, "\n"), strings.Join(got, "\n"))
+	}
+
+	if err := ValidateSchema("x", schema); err == nil || err.Error() != "(root): expected object, got string" {
+		t.Errorf("root: got %v", err)
+	}
+	if err := ValidateSchema(map[string]interface{}{}, schema); err == nil || strings.Count(err.Error(), "missing required") != 3 {
+		t.Errorf("required: got %v", err)
+	}
+}
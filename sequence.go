@@ -0,0 +1,121 @@
+package jq
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// SortBy returns the elements of the container selected by QQ(root, path), in the order of Values,
+// sorted by the value that QQ(element, keyPath) returns for each element.
+// The sort is stable and orders keys like jq does: missing values first,
+// then booleans, numbers of any type by value, strings, arrays, objects and times.
+// With an empty keyPath the elements are sorted by their own value.
+// The original container is not modified.
+func SortBy(root interface{}, path, keyPath string) []interface{} {
+	a := elements(QQ(root, path))
+	kk := make([]interface{}, len(a))
+	for i, e := range a {
+		kk[i] = QQ(e, keyPath)
+		if _, ok := kk[i].(error); ok {
+			kk[i] = nil
+		}
+	}
+	idx := make([]int, len(a))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return compare(kk[idx[i]], kk[idx[j]]) < 0 })
+	r := make([]interface{}, len(a))
+	for i, j := range idx {
+		r[i] = a[j]
+	}
+	return r
+}
+
+// Unique returns the elements of the container selected by QQ(root, path), in the order of Values,
+// keeping only the first of each set of equal elements. Elements are compared deeply,
+// and numbers of different types are equal if they have the same value.
+func Unique(root interface{}, path string) []interface{} {
+	return UniqueBy(root, path, "")
+}
+
+// UniqueBy is like Unique, but compares the values that QQ(element, keyPath) returns for each element.
+func UniqueBy(root interface{}, path, keyPath string) []interface{} {
+	var r []interface{}
+	seen := make(map[string]bool)
+	for _, e := range elements(QQ(root, path)) {
+		k := QQ(e, keyPath)
+		if _, ok := k.(error); ok {
+			k = nil
+		}
+		c := canonical(k)
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		r = append(r, e)
+	}
+	return r
+}
+
+// GroupBy buckets the elements of the container selected by QQ(root, path), in the order of Values,
+// by the value that QQ(element, keyPath) returns for each element.
+// Strings are used as bucket names as they are, numbers of any type in their shortest
+// decimal form, exact even beyond the precision of float64, booleans as "true" and "false", and missing values as "".
+func GroupBy(root interface{}, path, keyPath string) map[string][]interface{} {
+	m := make(map[string][]interface{})
+	for _, e := range elements(QQ(root, path)) {
+		k := groupKey(QQ(e, keyPath))
+		m[k] = append(m[k], e)
+	}
+	return m
+}
+
+func groupKey(v interface{}) string {
+	switch order(v) {
+	case orderNull:
+		return ""
+	case orderNumber:
+		f, _ := number(v)
+		if math.Abs(f) >= maxExactFloat {
+			return canonical(v) // the exact integer, see compare
+		}
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	case orderString:
+		return reflect.ValueOf(v).String()
+	}
+	if _, ok := v.(error); ok {
+		return ""
+	}
+	return canonical(v)
+}
+
+// Flatten returns the elements of the container selected by QQ(root, path), in the order of Values,
+// with nested arrays and slices replaced by their elements, up to depth levels deep.
+// A negative depth flattens completely. Maps and structs are not flattened.
+func Flatten(root interface{}, path string, depth int) []interface{} {
+	return flatten(nil, elements(QQ(root, path)), depth)
+}
+
+func flatten(r, a []interface{}, depth int) []interface{} {
+	for _, e := range a {
+		if depth != 0 && order(e) == orderArray {
+			r = flatten(r, elements(e), depth-1)
+			continue
+		}
+		r = append(r, e)
+	}
+	return r
+}
+
+// Reverse returns the elements of the container selected by QQ(root, path), in the reverse
+// order of Values. The original container is not modified.
+func Reverse(root interface{}, path string) []interface{} {
+	a := elements(QQ(root, path))
+	for i, j := 0, len(a)-1; i < j; i, j = i+1, j-1 {
+		a[i], a[j] = a[j], a[i]
+	}
+	return a
+}
@@ -0,0 +1,144 @@
+package jq
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var people = []interface{}{
+	map[string]interface{}{"name": "carol", "age": 35},
+	map[string]interface{}{"name": "alice", "age": json.Number("30")},
+	map[string]interface{}{"name": "bob", "age": 4.5},
+	map[string]interface{}{"name": "dave"},
+}
+
+func TestSortBy(t *testing.T) {
+	names := func(a []interface{}) []interface{} {
+		var r []interface{}
+		for _, e := range a {
+			r = append(r, QQ(e, "name"))
+		}
+		return r
+	}
+	for _, tc := range []struct {
+		root    interface{}
+		path    string
+		keyPath string
+		expect  []interface{}
+	}{
+		{people, "", "age", []interface{}{"dave", "bob", "alice", "carol"}},
+		{people, "", "name", []interface{}{"alice", "bob", "carol", "dave"}},
+		{people, "", "age/x", []interface{}{"carol", "alice", "bob", "dave"}}, // all errors, stable
+	} {
+		if v := names(SortBy(tc.root, tc.path, tc.keyPath)); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("[%q by %q]:  expected %v, got %v", tc.path, tc.keyPath, tc.expect, v)
+		}
+	}
+
+	mixed := []interface{}{"b", 2, nil, true, "a", 1.5, []interface{}{1}, false, map[string]interface{}{}}
+	expect := []interface{}{nil, false, true, 1.5, 2, "a", "b", []interface{}{1}, map[string]interface{}{}}
+	if v := SortBy(mixed, "", ""); !reflect.DeepEqual(v, expect) {
+		t.Errorf("mixed: expected %v, got %v", expect, v)
+	}
+	if v := SortBy(testObj, "nosuchkey", ""); len(v) != 0 {
+		t.Errorf("missing: expected empty, got %v", v)
+	}
+	if mixed[0] != "b" {
+		t.Errorf("original modified: %v", mixed)
+	}
+}
+
+func TestUnique(t *testing.T) {
+	for _, tc := range []struct {
+		root   interface{}
+		path   string
+		expect []interface{}
+	}{
+		{[]interface{}{3, 1., json.Number("3"), "3", 1, nil, nil}, "", []interface{}{3, 1., "3", nil}},
+		{[]interface{}{[]interface{}{1, 2}, []int{1, 2}, []int{2, 1}}, "", []interface{}{[]interface{}{1, 2}, []int{2, 1}}},
+		{[]interface{}{map[string]interface{}{"a": 1}, map[string]int{"a": 1}}, "", []interface{}{map[string]interface{}{"a": 1}}},
+		{testObj, "array/*/foo", []interface{}{1., nil}},
+		{testObj, "nosuchkey", nil},
+	} {
+		if v := Unique(tc.root, tc.path); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%v [%q]:  expected %v, got %v", tc.root, tc.path, tc.expect, v)
+		}
+	}
+
+	byAge := UniqueBy(append(people, map[string]interface{}{"name": "erin", "age": 30}), "", "age")
+	if len(byAge) != 4 {
+		t.Errorf("UniqueBy age: expected 4 elements, got %v", byAge)
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	root := []interface{}{
+		map[string]interface{}{"id": 1, "team": "a", "size": 3},
+		map[string]interface{}{"id": 2, "team": "b", "size": json.Number("3")},
+		map[string]interface{}{"id": 3, "team": "a", "size": 1e6},
+		map[string]interface{}{"id": 4, "active": true},
+	}
+	for _, tc := range []struct {
+		keyPath string
+		expect  map[string][]interface{}
+	}{
+		{"team", map[string][]interface{}{"a": {root[0], root[2]}, "b": {root[1]}, "": {root[3]}}},
+		{"size", map[string][]interface{}{"3": {root[0], root[1]}, "1000000": {root[2]}, "": {root[3]}}},
+		{"active", map[string][]interface{}{"": {root[0], root[1], root[2]}, "true": {root[3]}}},
+	} {
+		if v := GroupBy(root, "", tc.keyPath); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("[by %q]:  expected %v, got %v", tc.keyPath, tc.expect, v)
+		}
+	}
+	if v := GroupBy(testObj, "nosuchkey", "x"); len(v) != 0 {
+		t.Errorf("missing: expected empty, got %v", v)
+	}
+
+	ids := []interface{}{
+		map[string]interface{}{"id": int64(9007199254740992)},
+		map[string]interface{}{"id": int64(9007199254740993)},
+		map[string]interface{}{"id": json.Number("9007199254740993")},
+	}
+	expect := map[string][]interface{}{"9007199254740992": {ids[0]}, "9007199254740993": {ids[1], ids[2]}}
+	if v := GroupBy(ids, "", "id"); !reflect.DeepEqual(v, expect) {
+		t.Errorf("large IDs: expected %v, got %v", expect, v)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	root := map[string]interface{}{
+		"a": []interface{}{1, []interface{}{2, []int{3, 4}}, []interface{}{}, map[string]interface{}{"x": []int{5}}},
+	}
+	for _, tc := range []struct {
+		path   string
+		depth  int
+		expect []interface{}
+	}{
+		{"a", -1, []interface{}{1, 2, 3, 4, map[string]interface{}{"x": []int{5}}}},
+		{"a", 1, []interface{}{1, 2, []int{3, 4}, map[string]interface{}{"x": []int{5}}}},
+		{"a", 0, root["a"].([]interface{})},
+		{"a/3/x", -1, []interface{}{5}},
+		{"nosuchkey", -1, nil},
+	} {
+		if v := Flatten(root, tc.path, tc.depth); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("[%q, %d]:  expected %v, got %v", tc.path, tc.depth, tc.expect, v)
+		}
+	}
+}
+
+func TestReverse(t *testing.T) {
+	orig := []int{1, 2, 3}
+	if v := Reverse(orig, ""); !reflect.DeepEqual(v, []interface{}{3, 2, 1}) {
+		t.Errorf("Reverse: got %v", v)
+	}
+	if orig[0] != 1 {
+		t.Errorf("original modified: %v", orig)
+	}
+	if v := Reverse(testObj, "subobj/subsubobj/array"); !reflect.DeepEqual(v, []interface{}{"world", "hello"}) {
+		t.Errorf("Reverse: got %v", v)
+	}
+	if v := Reverse(testObj, "nosuchkey"); len(v) != 0 {
+		t.Errorf("Reverse missing: got %v", v)
+	}
+}
@@ -0,0 +1,73 @@
+package jq
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Size describes the extent of a value, as returned by SizeAt.
+type Size struct {
+	Len    int // number of elements of an array or entries of an object, 0 for other values
+	Values int // number of values, including the value itself and all nested values
+	Depth  int // levels of nesting: 0 for scalars, 1 for a flat array or object
+	Bytes  int // approximate length of the compact JSON encoding
+}
+
+// SizeAt measures the value at the slash separated path in root, so that services can
+// enforce limits before processing it:
+//
+//	if s, err := jq.SizeAt(req, "items"); err == nil && s.Len > 10000 {
+//		return errTooManyItems
+//	}
+//
+// Bytes ignores the escaping of strings and formats numbers like String does.
+// It returns an error wrapping ErrNotFound if the value is not present.
+func SizeAt(root interface{}, path string) (Size, error) {
+	v, err := resolve(root, path)
+	if err != nil {
+		return Size{}, err
+	}
+	return sizeOf(v), nil
+}
+
+func sizeOf(v interface{}) Size {
+	v = unwrap(v)
+	switch order(v) {
+	case orderNull:
+		return Size{Values: 1, Bytes: 4}
+	case orderFalse:
+		return Size{Values: 1, Bytes: 5}
+	case orderTrue:
+		return Size{Values: 1, Bytes: 4}
+	case orderNumber:
+		return Size{Values: 1, Bytes: len(numberString(v))}
+	case orderString:
+		return Size{Values: 1, Bytes: len(reflect.ValueOf(v).String()) + 2}
+	case orderArray:
+		if b, ok := v.([]byte); ok {
+			return Size{Values: 1, Bytes: (len(b)+2)/3*4 + 2} // base64, like encoding/json
+		}
+		ee := elements(v)
+		s := Size{Len: len(ee), Values: 1, Depth: 1, Bytes: 2 + max(len(ee)-1, 0)}
+		for _, e := range ee {
+			s.add(sizeOf(e))
+		}
+		return s
+	case orderObject:
+		kk, _ := keys(v).([]interface{})
+		s := Size{Len: len(kk), Values: 1, Depth: 1, Bytes: 2 + max(len(kk)-1, 0)}
+		for _, k := range kk {
+			s.Bytes += len(fmt.Sprint(k)) + 3 // quotes and colon
+			s.add(sizeOf(Q(v, k)))
+		}
+		return s
+	}
+	return Size{Values: 1}
+}
+
+// add accounts for the nested value c in s.
+func (s *Size) add(c Size) {
+	s.Values += c.Values
+	s.Bytes += c.Bytes
+	s.Depth = max(s.Depth, c.Depth+1)
+}
@@ -0,0 +1,40 @@
+package jq
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSizeAt(t *testing.T) {
+	const doc = `{"items":[{"id":1,"tags":["a","bc"]},{"id":22,"ok":true,"x":null}],"name":"shop","n":1.5}`
+	var root interface{}
+	if err := json.Unmarshal([]byte(doc), &root); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		path   string
+		expect Size
+	}{
+		{"", Size{Len: 3, Values: 13, Depth: 4, Bytes: len(doc)}},
+		{"items", Size{Len: 2, Values: 10, Depth: 3, Bytes: len(`[{"id":1,"tags":["a","bc"]},{"id":22,"ok":true,"x":null}]`)}},
+		{"items/0/tags", Size{Len: 2, Values: 3, Depth: 1, Bytes: len(`["a","bc"]`)}},
+		{"items/1/x", Size{Values: 1, Bytes: 4}},
+		{"name", Size{Values: 1, Bytes: 6}},
+		{"n", Size{Values: 1, Bytes: 3}},
+	} {
+		if s, err := SizeAt(root, tc.path); err != nil || s != tc.expect {
+			t.Errorf("%q: expected %+v, got %+v, %v", tc.path, tc.expect, s, err)
+		}
+	}
+
+	if s, err := SizeAt(map[string]interface{}{"raw": json.RawMessage(`[1, 2, 3]`), "empty": []interface{}{}}, "raw"); err != nil || s.Len != 3 || s.Bytes != 7 {
+		t.Errorf("json.RawMessage: got %+v, %v", s, err)
+	}
+	if s, _ := SizeAt(map[string]interface{}{"empty": map[string]interface{}{}}, "empty"); s != (Size{Values: 1, Depth: 1, Bytes: 2}) {
+		t.Errorf("empty object: got %+v", s)
+	}
+	if _, err := SizeAt(root, "nosuchkey"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing value: got %v", err)
+	}
+}
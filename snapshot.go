@@ -0,0 +1,36 @@
+package jq
+
+// A State is a saved version of a document, taken by Snapshot or Document.Snapshot.
+// It cannot be modified, so it can be restored any number of times.
+type State struct {
+	root interface{}
+}
+
+// Snapshot returns the state of root, deeply copied as by DeepCopy, so that root can be
+// modified in place and rolled back with Restore if the modifications fail or the result
+// does not validate:
+//
+//	saved := jq.Snapshot(cfg)
+//	if err := edit(cfg); err != nil {
+//		cfg = saved.Restore().(map[string]interface{})
+//	}
+func Snapshot(root interface{}) *State {
+	return &State{root: DeepCopy(root)}
+}
+
+// Restore returns a deep copy of the saved document, which the caller may modify.
+func (s *State) Restore() interface{} {
+	return DeepCopy(s.root)
+}
+
+// Snapshot returns the current state of d. Since a Document does not modify its containers
+// in place, the state shares them with d instead of copying them.
+func (d *Document) Snapshot() *State {
+	return &State{root: d.Root()}
+}
+
+// Restore replaces the document with the saved state s, and notifies the subscribers of
+// the changes.
+func (d *Document) Restore(s *State) {
+	d.modify(func(interface{}) (interface{}, error) { return s.root, nil })
+}
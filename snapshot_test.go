@@ -0,0 +1,50 @@
+package jq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	cfg := map[string]interface{}{
+		"server": map[string]interface{}{"port": 8080},
+		"tags":   []interface{}{"a"},
+	}
+	saved := Snapshot(cfg)
+	cfg["server"].(map[string]interface{})["port"] = 9000
+	cfg["tags"] = append(cfg["tags"].([]interface{}), "b")
+
+	restored := saved.Restore()
+	expect := map[string]interface{}{
+		"server": map[string]interface{}{"port": 8080},
+		"tags":   []interface{}{"a"},
+	}
+	if !reflect.DeepEqual(restored, expect) {
+		t.Errorf("Restore: expected %v, got %v", expect, restored)
+	}
+	restored.(map[string]interface{})["server"].(map[string]interface{})["port"] = 1
+	if v := QQ(saved.Restore(), "server/port"); v != 8080 {
+		t.Errorf("restored copy shares state with the snapshot: got %v", v)
+	}
+}
+
+func TestDocumentSnapshot(t *testing.T) {
+	doc := NewDocument(map[string]interface{}{"port": 8080})
+	var changes []interface{}
+	doc.Subscribe("port", func(old, new interface{}) { changes = append(changes, new) })
+
+	saved := doc.Snapshot()
+	doc.Set("port", 9000)
+	doc.Set("host", "example.com")
+	doc.Restore(saved)
+
+	if !reflect.DeepEqual(doc.Root(), map[string]interface{}{"port": 8080}) {
+		t.Errorf("Restore: got %v", doc.Root())
+	}
+	if !reflect.DeepEqual(changes, []interface{}{9000, 8080}) {
+		t.Errorf("subscribers: got %v", changes)
+	}
+	if v := QQ(saved.Restore(), "port"); v != 8080 {
+		t.Errorf("State.Restore: got %v", v)
+	}
+}
@@ -0,0 +1,77 @@
+package jq
+
+import "encoding/json"
+
+// stream calls fn for every value that Q(root, index...) would select, without building
+// the intermediate results of ALL. Containers are visited in the order of keys,
+// and path holds the keys leading to each value. Errors are passed to fn as values.
+// Stream stops and returns false as soon as fn returns false.
+func stream(root interface{}, index []interface{}, path []interface{}, fn func(path []interface{}, v interface{}) bool) bool {
+	return plain.stream(root, index, path, fn)
+}
+
+// stream implements stream, applying the options in c, which must not have hooks.
+func (c *config) stream(root interface{}, index []interface{}, path []interface{}, fn func(path []interface{}, v interface{}) bool) bool {
+	cur := root
+	for len(index) > 0 {
+		if l, ok := cur.(Layered); ok {
+			cur = l.pick(c, index)
+		}
+		q, ok := index[0].(quantifier)
+		if !ok || (q != ALL && q != KEYS) {
+			cur = c.step(cur, index[:1])
+			path = append(path, index[0])
+			index = index[1:]
+			if _, ok := cur.(error); ok || cur == nil {
+				break
+			}
+			continue
+		}
+
+		cur = c.unwrap(cur)
+		kk := c.keys(cur)
+		if _, ok := kk.(error); ok {
+			if q == ALL {
+				return fn(path, c.step(cur, index))
+			}
+			return fn(path, kk)
+		}
+		if q == KEYS {
+			cur, index = kk, index[1:]
+			continue
+		}
+		for _, k := range kk.([]interface{}) {
+			p := append(path[:len(path):len(path)], k)
+			if !c.stream(c.step(cur, []interface{}{k}), index[1:], p, fn) {
+				return false
+			}
+		}
+		return true
+	}
+	return fn(path, cur)
+}
+
+// unwrap decodes the values that Q decodes on descent, and loads providers.
+func unwrap(v interface{}) interface{} {
+	return plain.unwrap(v)
+}
+
+// unwrap implements unwrap, applying the options in c.
+func (c *config) unwrap(v interface{}) interface{} {
+	if p, err := c.provided(v); err == nil {
+		v = p
+	}
+	switch vv := v.(type) {
+	case json.RawMessage:
+		if d, err := decodeRawMessage(vv); err == nil {
+			return d
+		}
+	case string:
+		if c.decodeStrings {
+			if d, ok := decodeJSONString(vv); ok {
+				return d
+			}
+		}
+	}
+	return v
+}
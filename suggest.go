@@ -0,0 +1,54 @@
+package jq
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Suggest returns completions for the slash separated partialPath: the keys of the deepest
+// container along it that start with the element following that container, ignoring case.
+// The last element of partialPath is always taken as such a partial key, so "subobj/su" suggests
+// the keys of subobj starting with "su", and "subobj/" suggests all of them.
+// If no key starts with the element, for example because it is misspelled, Suggest returns
+// all keys of the container, for messages listing the keys that are available.
+// Keys are returned in the order of KEYS, with array indices formatted as numbers.
+// Suggest returns nil if root is not a container, or if partialPath has an empty element
+// other than the last.
+func Suggest(root interface{}, partialPath string) []string {
+	index, err := parsePath(strings.TrimSuffix(partialPath, "/"))
+	if err != nil {
+		return nil
+	}
+	if strings.HasSuffix(partialPath, "/") || len(index) == 0 {
+		index = append(index, "")
+	}
+	cur := root
+	for _, e := range index[:len(index)-1] {
+		v, ok := lookup(cur, []interface{}{e})
+		if !ok {
+			return suggest(cur, fmt.Sprint(e))
+		}
+		cur = v
+	}
+	return suggest(cur, fmt.Sprint(index[len(index)-1]))
+}
+
+// suggest returns the keys of the container v starting with prefix, or all of them if none does.
+func suggest(v interface{}, prefix string) []string {
+	kk, ok := keys(unwrap(v)).([]interface{})
+	if !ok {
+		return nil
+	}
+	all := make([]string, len(kk))
+	var matched []string
+	for i, k := range kk {
+		all[i] = fmt.Sprint(k)
+		if len(all[i]) >= len(prefix) && strings.EqualFold(all[i][:len(prefix)], prefix) {
+			matched = append(matched, all[i])
+		}
+	}
+	if matched == nil {
+		return all
+	}
+	return matched
+}
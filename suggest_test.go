@@ -0,0 +1,32 @@
+package jq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	for _, tc := range []struct {
+		path   string
+		expect []string
+	}{
+		{"", []string{"array", "bar", "baz", "bool", "foo", "subobj", "test"}},
+		{"sub", []string{"subobj"}},
+		{"b", []string{"bar", "baz", "bool"}},
+		{"SUB", []string{"subobj"}},
+		{"subobj/", []string{"foo", "subarray", "subsubobj"}},
+		{"subobj/su", []string{"subarray", "subsubobj"}},
+		{"subobj/subsubobj/array/", []string{"0", "1"}},
+		{"subobj/nosuchkey/x", []string{"foo", "subarray", "subsubobj"}},
+		{"subobj/xyz", []string{"foo", "subarray", "subsubobj"}},
+		{"array/0/f", []string{"foo"}},
+		{"foo/", nil},
+	} {
+		if v := Suggest(testObj, tc.path); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%q: expected %v, got %v", tc.path, tc.expect, v)
+		}
+	}
+	if v := Suggest(testStruct, "Subo"); !reflect.DeepEqual(v, []string{"Subobj"}) {
+		t.Errorf("struct: got %v", v)
+	}
+}
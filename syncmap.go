@@ -0,0 +1,52 @@
+package jq
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// qSyncMap resolves index against m. A single key is looked up with Load,
+// falling back to the matching rules for interface-keyed maps, and ALL
+// visits the entries with Range.
+func (c *config) qSyncMap(m *sync.Map, index []interface{}) interface{} {
+	if i, ok := index[0].(quantifier); ok && i == ALL {
+		c := c.unbounded()
+		r := make(map[interface{}]interface{})
+		m.Range(func(k, v interface{}) bool {
+			rr := c.next(v, k, index[1:])
+			if rr == nil {
+				return true
+			}
+			if _, ok := rr.(error); ok {
+				return true
+			}
+			r[k] = rr
+			return true
+		})
+		return r
+	}
+
+	if t := reflect.TypeOf(index[0]); t != nil && t.Comparable() {
+		if v, ok := m.Load(index[0]); ok {
+			return c.next(v, index[0], index[1:])
+		}
+	}
+	var (
+		i     = reflect.ValueOf(index[0])
+		r     interface{}
+		found bool
+	)
+	m.Range(func(k, v interface{}) bool {
+		kv := reflect.ValueOf(k)
+		if keyMatches(kv, i) || c.fold && kv.Kind() == reflect.String && i.Kind() == reflect.String && strings.EqualFold(kv.String(), i.String()) {
+			r, found = c.next(v, k, index[1:]), true
+			return false
+		}
+		return true
+	})
+	if !found {
+		return c.missing(index[0])
+	}
+	return r
+}
@@ -0,0 +1,36 @@
+package jq
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestSyncMap(t *testing.T) {
+	var m sync.Map
+	m.Store("svc", map[string]interface{}{"port": 80})
+	m.Store(int64(2), "two")
+	m.Store("empty", nil)
+
+	for _, tc := range []struct {
+		path   []interface{}
+		expect interface{}
+	}{
+		{[]interface{}{"svc", "port"}, 80},
+		{[]interface{}{int64(2)}, "two"},
+		{[]interface{}{"2"}, "two"},
+		{[]interface{}{2}, "two"},
+		{[]interface{}{"nosuchkey"}, nil},
+		{[]interface{}{[]int{1}}, nil}, // not comparable
+		{[]interface{}{nil}, nil},
+		{[]interface{}{ALL, "port"}, map[interface{}]interface{}{"svc": 80}},
+	} {
+		if v := Q(&m, tc.path...); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("sync.Map [%v]:  expected %v, got %v (%T)", tc.path, tc.expect, v, v)
+		}
+	}
+
+	if v := QQ(&m, "svc/port"); v != 80 {
+		t.Errorf("sync.Map [%q]:  expected %v, got %v (%T)", "svc/port", 80, v, v)
+	}
+}
@@ -0,0 +1,83 @@
+package jq
+
+import "fmt"
+
+// A Txn stages modifications of a Document, which Commit applies all together or not at all:
+//
+//	err := doc.Commit(new(jq.Txn).
+//		Set("server/port", 8081).
+//		Delete("server/legacyPort").
+//		Append("audit", "port changed"))
+//
+// The zero value is an empty transaction. The methods return t, so calls can be chained,
+// and a Txn can be committed to several documents.
+type Txn struct {
+	ops []txnOp
+}
+
+// txnOp is a modification staged in a Txn.
+type txnOp struct {
+	path  string
+	err   error // from parsing path
+	apply func(root interface{}) (interface{}, error)
+}
+
+// add stages the modification fn of the value at path.
+func (t *Txn) add(path string, fn func(root interface{}, index []interface{}) (interface{}, error)) *Txn {
+	index, err := parsePath(path)
+	t.ops = append(t.ops, txnOp{path: path, err: err, apply: func(root interface{}) (interface{}, error) {
+		return fn(root, index)
+	}})
+	return t
+}
+
+// Set stages storing v at path, as described for Document.Set.
+func (t *Txn) Set(path string, v interface{}) *Txn {
+	return t.add(path, func(root interface{}, index []interface{}) (interface{}, error) {
+		return setPath(root, index, v)
+	})
+}
+
+// Delete stages removing the value at path, as described for Document.Delete.
+func (t *Txn) Delete(path string) *Txn {
+	return t.add(path, func(root interface{}, index []interface{}) (interface{}, error) {
+		if len(index) == 0 {
+			return nil, nil
+		}
+		return deletePath(root, index)
+	})
+}
+
+// Append stages appending vv to the array at path, as described for Document.Append.
+func (t *Txn) Append(path string, vv ...interface{}) *Txn {
+	return t.add(path, func(root interface{}, index []interface{}) (interface{}, error) {
+		return appendPath(root, index, vv)
+	})
+}
+
+// Len returns the number of modifications staged in t.
+func (t *Txn) Len() int {
+	return len(t.ops)
+}
+
+// Commit applies the modifications staged in t to d, in order, so that later ones see the
+// effect of earlier ones. If one of them fails, Commit returns its error and d is left
+// unmodified; otherwise the new version replaces the document at once, so concurrent queries
+// see either none or all of the modifications, and the subscribers are notified of the combined
+// changes.
+func (d *Document) Commit(t *Txn) error {
+	for _, op := range t.ops {
+		if op.err != nil {
+			return op.err
+		}
+	}
+	return d.modify(func(root interface{}) (interface{}, error) {
+		for _, op := range t.ops {
+			var err error
+			if root, err = op.apply(root); err != nil {
+				return nil, fmt.Errorf("%s: %v", op.path, err)
+			}
+		}
+		return root, nil
+	})
+}
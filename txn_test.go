@@ -0,0 +1,62 @@
+package jq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTxn(t *testing.T) {
+	doc := NewDocument(map[string]interface{}{
+		"server": map[string]interface{}{"port": 8080, "legacyPort": 80},
+		"audit":  []interface{}{"created"},
+	})
+	var changes int
+	doc.Subscribe("server", func(old, new interface{}) { changes++ })
+
+	tx := new(Txn).
+		Set("server/port", 8081).
+		Delete("server/legacyPort").
+		Append("audit", "port changed", "legacy port removed").
+		Append("new/list", 1)
+	if tx.Len() != 4 {
+		t.Errorf("Len: got %d", tx.Len())
+	}
+	if err := doc.Commit(tx); err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{
+		"server": map[string]interface{}{"port": 8081},
+		"audit":  []interface{}{"created", "port changed", "legacy port removed"},
+		"new":    map[string]interface{}{"list": []interface{}{1}},
+	}
+	if !reflect.DeepEqual(doc.Root(), expect) {
+		t.Errorf("Commit: expected %v, got %v", expect, doc.Root())
+	}
+	if changes != 1 {
+		t.Errorf("expected one notification for the combined changes, got %d", changes)
+	}
+
+	before := doc.Root()
+	for _, tx := range []*Txn{
+		new(Txn).Set("server/port", 9000).Append("server/port", 1),
+		new(Txn).Set("server/port", 9000).Set("audit/x", 1),
+		new(Txn).Set("server/port", 9000).Delete("a//b"),
+	} {
+		if err := doc.Commit(tx); err == nil {
+			t.Errorf("expected error")
+		}
+		if !reflect.DeepEqual(doc.Root(), before) {
+			t.Errorf("failed Commit modified the document: %v", doc.Root())
+		}
+	}
+	if changes != 1 {
+		t.Errorf("failed Commit notified subscribers")
+	}
+
+	if err := doc.Append("audit", "done"); err != nil || doc.QQ("audit/3") != "done" {
+		t.Errorf("Append: got %v, %v", doc.QQ("audit"), err)
+	}
+	if err := doc.Commit(new(Txn)); err != nil {
+		t.Errorf("empty Txn: %v", err)
+	}
+}
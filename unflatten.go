@@ -0,0 +1,82 @@
+package jq
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unflatten rebuilds a nested document from a map of slash separated paths to values,
+// such as a set of keys read from a key-value store or the environment:
+//
+//	Unflatten(map[string]interface{}{"db/host": "x", "db/ports/0": 5432, "db/ports/1": 5433})
+//
+// returns {"db": {"host": "x", "ports": [5432, 5433]}}. Objects are built as
+// map[string]interface{}; an object whose keys are all integers, without leading zeros,
+// becomes an []interface{} instead, with nil for the indices that are missing, unless its
+// largest index is twice its number of keys or more, so a stray key cannot make it huge.
+// Leading and trailing slashes of the paths are ignored, and the empty path sets the root.
+// If a path is both set to a value and the prefix of other paths, the value is dropped.
+func Unflatten(flat map[string]interface{}) interface{} {
+	paths := make([]string, 0, len(flat))
+	for p := range flat {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths) // for a deterministic result when paths differ in slashes only
+
+	var root interface{}
+	for _, path := range paths {
+		p := strings.Trim(path, "/")
+		if p == "" {
+			if _, ok := root.(map[string]interface{}); !ok {
+				root = flat[path]
+			}
+			continue
+		}
+		ee := strings.Split(p, "/")
+		m, ok := root.(map[string]interface{})
+		if !ok {
+			m = make(map[string]interface{})
+			root = m
+		}
+		for _, e := range ee[:len(ee)-1] {
+			c, ok := m[e].(map[string]interface{})
+			if !ok {
+				c = make(map[string]interface{})
+				m[e] = c
+			}
+			m = c
+		}
+		if _, ok := m[ee[len(ee)-1]].(map[string]interface{}); !ok {
+			m[ee[len(ee)-1]] = flat[path]
+		}
+	}
+	return arrays(root)
+}
+
+// arrays replaces the maps built by Unflatten that have integer keys with slices.
+func arrays(v interface{}) interface{} {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	max, indices := -1, true
+	for k, e := range m {
+		m[k] = arrays(e)
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || strconv.Itoa(i) != k {
+			indices = false
+		} else if i > max {
+			max = i
+		}
+	}
+	if !indices || max >= 2*len(m) {
+		return m
+	}
+	a := make([]interface{}, max+1)
+	for k, e := range m {
+		i, _ := strconv.Atoi(k)
+		a[i] = e
+	}
+	return a
+}
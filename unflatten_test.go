@@ -0,0 +1,52 @@
+package jq
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnflatten(t *testing.T) {
+	for _, tc := range []struct {
+		flat   map[string]interface{}
+		expect interface{}
+	}{
+		{
+			map[string]interface{}{"db/host": "x", "db/ports/0": 5432, "db/ports/1": 5433, "debug": true},
+			map[string]interface{}{"db": map[string]interface{}{"host": "x", "ports": []interface{}{5432, 5433}}, "debug": true},
+		},
+		{
+			map[string]interface{}{"servers/0/name": "a", "servers/1/name": "b", "servers/1/tags/0": "t"},
+			map[string]interface{}{"servers": []interface{}{
+				map[string]interface{}{"name": "a"},
+				map[string]interface{}{"name": "b", "tags": []interface{}{"t"}},
+			}},
+		},
+		{
+			map[string]interface{}{"a/0": 1, "a/2": 3},
+			map[string]interface{}{"a": []interface{}{1, nil, 3}},
+		},
+		{
+			map[string]interface{}{"a/0": 1, "a/9": 2},
+			map[string]interface{}{"a": map[string]interface{}{"0": 1, "9": 2}},
+		},
+		{
+			map[string]interface{}{"a/0": 1, "a/01": 2, "b/0": 1, "b/x": 2},
+			map[string]interface{}{"a": map[string]interface{}{"0": 1, "01": 2}, "b": map[string]interface{}{"0": 1, "x": 2}},
+		},
+		{
+			map[string]interface{}{"a": 1, "a/b": 2, "c/d": 3, "c": 4},
+			map[string]interface{}{"a": map[string]interface{}{"b": 2}, "c": map[string]interface{}{"d": 3}},
+		},
+		{
+			map[string]interface{}{"/config/app/": "x"},
+			map[string]interface{}{"config": map[string]interface{}{"app": "x"}},
+		},
+		{map[string]interface{}{"0": "a", "1": "b"}, []interface{}{"a", "b"}},
+		{map[string]interface{}{"": 42}, 42},
+		{map[string]interface{}{}, nil},
+	} {
+		if v := Unflatten(tc.flat); !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%v: expected %v, got %v", tc.flat, tc.expect, v)
+		}
+	}
+}
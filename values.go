@@ -0,0 +1,59 @@
+package jq
+
+import "strings"
+
+// qValues resolves index against the multi-valued map m, as found in url.Values
+// and http.Header. A key selects the first value stored under it, unless it is
+// followed by ALL, in which case all values are selected.
+func (c *config) qValues(m map[string][]string, canonical func(string) string, index []interface{}) interface{} {
+	if i, ok := index[0].(quantifier); ok && i == ALL {
+		c := c.unbounded()
+		r := make(map[string]interface{})
+		for k, vv := range m {
+			rr := c.qFirst(k, vv, index[1:])
+			if rr == nil {
+				continue
+			}
+			if _, ok := rr.(error); ok {
+				continue
+			}
+			r[k] = rr
+		}
+		return r
+	}
+
+	k, ok := index[0].(string)
+	if !ok {
+		return c.step(m, index)
+	}
+	if canonical != nil {
+		k = canonical(k)
+	}
+	vv, ok := m[k]
+	if !ok && c.fold {
+		for kk, v := range m {
+			if strings.EqualFold(kk, k) {
+				vv, ok = v, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return c.missing(index[0])
+	}
+	return c.qFirst(k, vv, index[1:])
+}
+
+// qFirst applies index to the first element of vv, the values stored under key,
+// or to all of vv if index starts with ALL.
+func (c *config) qFirst(key string, vv []string, index []interface{}) interface{} {
+	if len(index) > 0 {
+		if i, ok := index[0].(quantifier); ok && i == ALL {
+			return c.next(vv, key, index)
+		}
+	}
+	if len(vv) == 0 {
+		return nil
+	}
+	return c.next(vv[0], key, index)
+}
@@ -0,0 +1,41 @@
+package jq
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestMultiValues(t *testing.T) {
+	vals := url.Values{"a": {"1", "2"}, "b": {"3"}, "empty": {}}
+	hdr := http.Header{"Content-Type": {"text/plain"}, "Accept": {"a", "b"}}
+
+	for _, tc := range []struct {
+		root   interface{}
+		path   string
+		expect interface{}
+	}{
+		{vals, "a", "1"},
+		{vals, "a/*", []interface{}{"1", "2"}},
+		{vals, "a/1", ee}, // "1" is a string, not indexable
+		{vals, "b", "3"},
+		{vals, "empty", nil},
+		{vals, "nosuchkey", nil},
+		{vals, "*", map[string]interface{}{"a": "1", "b": "3"}},
+		{hdr, "content-type", "text/plain"},
+		{hdr, "Accept/*", []interface{}{"a", "b"}},
+		{hdr, "X-Missing", nil},
+	} {
+		v := QQ(tc.root, tc.path)
+		if _, ok := tc.expect.(error); ok {
+			if _, ok := v.(error); !ok {
+				t.Errorf("%#v [%q]: expected error, got %v (%T) ", tc.root, tc.path, v, v)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(v, tc.expect) {
+			t.Errorf("%#v [%q]:  expected %v, got %v (%T)", tc.root, tc.path, tc.expect, v, v)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package jq
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// SkipChildren can be returned by a WalkFunc to skip the children of the current value.
+var SkipChildren = errors.New("skip children")
+
+// SkipAll can be returned by a WalkFunc to stop the walk without error.
+var SkipAll = errors.New("skip all")
+
+// WalkFunc is called by Walk for every value with the path that leads to it from the root,
+// such that Q(root, path...) returns value. The path must not be modified or retained.
+type WalkFunc func(path []interface{}, value interface{}) error
+
+// Walk visits root and every value nested in it depth-first, calling fn before visiting
+// the children of a value. Children are visited in the order of Values.
+// Byte slices are treated as leaves, and json.RawMessage values are decoded.
+//
+// If fn returns SkipChildren, the children of the current value are skipped.
+// If fn returns SkipAll, Walk stops and returns nil.
+// If fn returns any other error, Walk stops and returns that error.
+func Walk(root interface{}, fn WalkFunc) error {
+	if err := walk(nil, root, fn); err != nil && err != SkipAll {
+		return err
+	}
+	return nil
+}
+
+func walk(path []interface{}, v interface{}, fn WalkFunc) error {
+	if r, ok := v.(json.RawMessage); ok {
+		v = unwrap(r)
+	}
+	switch err := fn(path, v); err {
+	case nil:
+	case SkipChildren:
+		return nil
+	default:
+		return err
+	}
+
+	if o := order(v); o != orderArray && o != orderObject || isBytes(v) {
+		return nil
+	}
+	kk, _ := keys(v).([]interface{})
+	for _, k := range kk {
+		if err := walk(append(path[:len(path):len(path)], k), Q(v, k), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Paths returns the path of every value nested in root, in the order Walk visits them.
+// The empty path of root itself is not included. Each path can be passed to Q.
+func Paths(root interface{}) [][]interface{} {
+	var pp [][]interface{}
+	Walk(root, func(path []interface{}, _ interface{}) error {
+		if len(path) > 0 {
+			pp = append(pp, append([]interface{}(nil), path...))
+		}
+		return nil
+	})
+	return pp
+}
+
+// LeafPaths is like Paths, but only returns the paths of scalar values:
+// values other than arrays, slices, maps and structs, including nil.
+func LeafPaths(root interface{}) [][]interface{} {
+	var pp [][]interface{}
+	Walk(root, func(path []interface{}, v interface{}) error {
+		if o := order(v); o != orderArray && o != orderObject || isBytes(v) {
+			pp = append(pp, append([]interface{}(nil), path...))
+		}
+		return nil
+	})
+	return pp
+}
+
+func isBytes(v interface{}) bool {
+	_, ok := v.([]byte)
+	return ok
+}
+
+// Find returns the path and value of the first value in root, in the order Walk visits them,
+// for which pred returns true. It returns false if there is none.
+func Find(root interface{}, pred func(interface{}) bool) (path []interface{}, value interface{}, ok bool) {
+	Walk(root, func(p []interface{}, v interface{}) error {
+		if pred(v) {
+			path, value, ok = append([]interface{}(nil), p...), v, true
+			return SkipAll
+		}
+		return nil
+	})
+	return path, value, ok
+}
+
+// Match is a value found by FindAll together with its path.
+type Match struct {
+	Path  []interface{}
+	Value interface{}
+}
+
+// FindAll returns all values in root for which pred returns true, in the order Walk visits them.
+func FindAll(root interface{}, pred func(interface{}) bool) []Match {
+	var mm []Match
+	Walk(root, func(p []interface{}, v interface{}) error {
+		if pred(v) {
+			mm = append(mm, Match{append([]interface{}(nil), p...), v})
+		}
+		return nil
+	})
+	return mm
+}
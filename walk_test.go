@@ -0,0 +1,97 @@
+package jq
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	root := map[string]interface{}{
+		"a": []interface{}{1, map[string]interface{}{"b": nil}},
+		"c": "x",
+	}
+
+	var visited []string
+	err := Walk(root, func(path []interface{}, v interface{}) error {
+		visited = append(visited, fmt.Sprint(path))
+		if !reflect.DeepEqual(Q(root, path...), v) {
+			t.Errorf("%v: Q returns %v, walk visited %v", path, Q(root, path...), v)
+		}
+		return nil
+	})
+	expect := []string{"[]", "[a]", "[a 0]", "[a 1]", "[a 1 b]", "[c]"}
+	if err != nil || !reflect.DeepEqual(visited, expect) {
+		t.Errorf("Walk: expected %v, got %v, %v", expect, visited, err)
+	}
+
+	visited = nil
+	Walk(root, func(path []interface{}, v interface{}) error {
+		visited = append(visited, fmt.Sprint(path))
+		if len(path) == 1 && path[0] == "a" {
+			return SkipChildren
+		}
+		return nil
+	})
+	if expect := []string{"[]", "[a]", "[c]"}; !reflect.DeepEqual(visited, expect) {
+		t.Errorf("SkipChildren: expected %v, got %v", expect, visited)
+	}
+
+	visited = nil
+	err = Walk(root, func(path []interface{}, v interface{}) error {
+		visited = append(visited, fmt.Sprint(path))
+		if len(path) == 2 {
+			return SkipAll
+		}
+		return nil
+	})
+	if expect := []string{"[]", "[a]", "[a 0]"}; err != nil || !reflect.DeepEqual(visited, expect) {
+		t.Errorf("SkipAll: expected %v, got %v, %v", expect, visited, err)
+	}
+
+	boom := errors.New("boom")
+	if err := Walk(root, func([]interface{}, interface{}) error { return boom }); err != boom {
+		t.Errorf("expected %v, got %v", boom, err)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	root := map[string]interface{}{
+		"a": []interface{}{1, map[string]interface{}{"b": nil}, []interface{}{}},
+		"c": []byte("x"),
+	}
+	expect := [][]interface{}{{"a"}, {"a", 0}, {"a", 1}, {"a", 1, "b"}, {"a", 2}, {"c"}}
+	if v := Paths(root); !reflect.DeepEqual(v, expect) {
+		t.Errorf("Paths: expected %v, got %v", expect, v)
+	}
+	expect = [][]interface{}{{"a", 0}, {"a", 1, "b"}, {"c"}}
+	if v := LeafPaths(root); !reflect.DeepEqual(v, expect) {
+		t.Errorf("LeafPaths: expected %v, got %v", expect, v)
+	}
+	if v := Paths(1); v != nil {
+		t.Errorf("Paths of scalar: expected nil, got %v", v)
+	}
+}
+
+func TestFind(t *testing.T) {
+	isWorld := func(v interface{}) bool { return v == "world" }
+	path, v, ok := Find(testObj, isWorld)
+	if expect := []interface{}{"subobj", "subsubobj", "array", 1}; !ok || v != "world" || !reflect.DeepEqual(path, expect) {
+		t.Errorf("Find: expected %v, got %v, %v, %v", expect, path, v, ok)
+	}
+	if _, _, ok := Find(testObj, func(v interface{}) bool { return v == "nowhere" }); ok {
+		t.Errorf("Find: expected not found")
+	}
+
+	isTwo := func(v interface{}) bool { return v == 2. }
+	expect := []Match{
+		{[]interface{}{"array", 1, "bar"}, 2.},
+		{[]interface{}{"bar"}, 2.},
+		{[]interface{}{"subobj", "subarray", 1}, 2.},
+		{[]interface{}{"subobj", "subsubobj", "bar"}, 2.},
+	}
+	if mm := FindAll(testObj, isTwo); !reflect.DeepEqual(mm, expect) {
+		t.Errorf("FindAll: expected %v, got %v", expect, mm)
+	}
+}